@@ -0,0 +1,60 @@
+package tokenauthority
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+	"time"
+)
+
+// JWK is a public key in JSON Web Key format (RFC 7517, RFC 8037 for Ed25519).
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public halves of all unretired keys, including those not signing yet,
+// so verifiers can pick them up ahead of a rotation.
+// It is empty when tokens are signed with the shared secret.
+func (t *TokenAuthority) JWKS() JWKSet {
+	now := time.Now()
+	set := JWKSet{Keys: make([]JWK, 0, len(t.keys))}
+	for _, k := range t.keys {
+		if k.retired(now) {
+			continue
+		}
+
+		jwk := JWK{
+			Use: "sig",
+			Alg: k.method.Alg(),
+			Kid: k.id,
+		}
+		switch pub := k.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
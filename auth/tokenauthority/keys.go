@@ -0,0 +1,96 @@
+package tokenauthority
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/Laelapa/CompanyRegistry/internal/config"
+)
+
+// signingKey is an asymmetric key of the rotation schedule.
+// The algorithm follows from the key type: RS256 for RSA and EdDSA for Ed25519 keys.
+type signingKey struct {
+	id       string
+	method   jwt.SigningMethod
+	private  crypto.Signer // nil for keys that only verify
+	public   crypto.PublicKey
+	signFrom time.Time
+	retireAt time.Time
+}
+
+const minRSAKeyBits = 2048
+
+// loadSigningKey reads a PEM encoded private or public key.
+func loadSigningKey(cfg config.SigningKeyConfig) (*signingKey, error) {
+	pemBytes, err := os.ReadFile(cfg.PEMFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key %q: %w", cfg.ID, err)
+	}
+
+	k := &signingKey{
+		id:       cfg.ID,
+		signFrom: cfg.SignFrom,
+		retireAt: cfg.RetireAt,
+	}
+
+	if rsaKey, rErr := jwt.ParseRSAPrivateKeyFromPEM(pemBytes); rErr == nil {
+		k.method, k.private, k.public = jwt.SigningMethodRS256, rsaKey, &rsaKey.PublicKey
+	} else if edKey, eErr := jwt.ParseEdPrivateKeyFromPEM(pemBytes); eErr == nil {
+		signer, _ := edKey.(ed25519.PrivateKey) // always is when parsing succeeds
+		k.method, k.private, k.public = jwt.SigningMethodEdDSA, signer, signer.Public()
+	} else if rsaPub, rpErr := jwt.ParseRSAPublicKeyFromPEM(pemBytes); rpErr == nil {
+		k.method, k.public = jwt.SigningMethodRS256, rsaPub
+	} else if edPub, epErr := jwt.ParseEdPublicKeyFromPEM(pemBytes); epErr == nil {
+		k.method, k.public = jwt.SigningMethodEdDSA, edPub
+	} else {
+		return nil, fmt.Errorf("signing key %q is neither an RSA nor an Ed25519 PEM key", cfg.ID)
+	}
+
+	if rsaPub, ok := k.public.(*rsa.PublicKey); ok && rsaPub.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("signing key %q: RSA keys must be at least %d bits", cfg.ID, minRSAKeyBits)
+	}
+	return k, nil
+}
+
+// retired reports whether the key has stopped verifying tokens.
+func (k *signingKey) retired(now time.Time) bool {
+	return !k.retireAt.IsZero() && !now.Before(k.retireAt)
+}
+
+// canSign reports whether the key may sign tokens at the given time,
+// not accounting for newer keys taking over.
+func (k *signingKey) canSign(now time.Time) bool {
+	return k.private != nil && !now.Before(k.signFrom) && !k.retired(now)
+}
+
+// currentSigningKey picks the key with the latest SignFrom that may sign at the given time.
+// Among keys with the same SignFrom, the one configured last wins.
+func (t *TokenAuthority) currentSigningKey(now time.Time) (*signingKey, error) {
+	var current *signingKey
+	for _, k := range t.keys {
+		if k.canSign(now) && (current == nil || !k.signFrom.Before(current.signFrom)) {
+			current = k
+		}
+	}
+	if current == nil {
+		return nil, errors.New("no signing key is active")
+	}
+	return current, nil
+}
+
+// verificationKey returns the unretired key with the given ID, or nil if there is none.
+func (t *TokenAuthority) verificationKey(kid string, now time.Time) *signingKey {
+	for _, k := range t.keys {
+		if k.id == kid && !k.retired(now) {
+			return k
+		}
+	}
+	return nil
+}
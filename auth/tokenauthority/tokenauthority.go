@@ -10,62 +10,157 @@ import (
 	"github.com/Laelapa/CompanyRegistry/internal/config"
 )
 
+// TokenAuthority issues and validates access tokens.
+// Tokens are signed with the asymmetric keys of the configured rotation schedule,
+// or with HS256 and the shared JwtSecret if no keys are configured.
 type TokenAuthority struct {
-	cfg *config.AuthConfig
+	cfg  *config.AuthConfig
+	keys []*signingKey
 }
 
-func New(cfg *config.AuthConfig) *TokenAuthority {
-	return &TokenAuthority{
+// accessClaims are the claims of the access tokens issued by the TokenAuthority.
+// SessionID ties a token to the refresh token family it was issued for,
+// Role is the user's role at the time the token was issued.
+type accessClaims struct {
+	jwt.RegisteredClaims
+	SessionID string `json:"sid"`
+	Role      string `json:"role"`
+}
+
+// AccessToken holds the claims of a validated access token.
+type AccessToken struct {
+	UserID    uuid.UUID
+	ID        uuid.UUID // jti
+	SessionID uuid.UUID
+	Role      string
+	ExpiresAt time.Time
+}
+
+// New creates a TokenAuthority, loading the configured signing keys.
+func New(cfg *config.AuthConfig) (*TokenAuthority, error) {
+	t := &TokenAuthority{
 		cfg: cfg,
 	}
+
+	for _, keyCfg := range cfg.SigningKeys {
+		k, err := loadSigningKey(keyCfg)
+		if err != nil {
+			return nil, err
+		}
+		t.keys = append(t.keys, k)
+	}
+	if len(t.keys) > 0 {
+		if _, err := t.currentSigningKey(time.Now()); err != nil {
+			return nil, fmt.Errorf("invalid signing key schedule: %w", err)
+		}
+	}
+
+	return t, nil
 }
 
-func (t *TokenAuthority) IssueJWT(userID uuid.UUID) (signedToken string, err error) {
+// asymmetric reports whether tokens are signed with the configured keys rather than the shared secret.
+func (t *TokenAuthority) asymmetric() bool {
+	return len(t.keys) > 0
+}
+
+// IssueJWT issues a short-lived access token for a user's session, carrying the user's role.
+// Every token gets a unique ID (jti) so it can be revoked on its own.
+func (t *TokenAuthority) IssueJWT(
+	userID,
+	sessionID uuid.UUID,
+	role string,
+) (signedToken string, expiresAt time.Time, err error) {
 	now := time.Now().UTC()
-	claims := jwt.RegisteredClaims{
-		Issuer:    t.cfg.JwtIssuer,
-		Subject:   userID.String(),
-		IssuedAt:  jwt.NewNumericDate(now),
-		ExpiresAt: jwt.NewNumericDate(now.Add(t.cfg.JwtLifetime)),
+	expiresAt = now.Add(t.cfg.JwtLifetime)
+	claims := accessClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Issuer:    t.cfg.JwtIssuer,
+			Subject:   userID.String(),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+		SessionID: sessionID.String(),
+		Role:      role,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err = token.SignedString([]byte(t.cfg.JwtSecret))
+	if t.asymmetric() {
+		var key *signingKey
+		if key, err = t.currentSigningKey(now); err != nil {
+			return "", time.Time{}, err
+		}
+		token := jwt.NewWithClaims(key.method, claims)
+		token.Header["kid"] = key.id
+		signedToken, err = token.SignedString(key.private)
+	} else {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		signedToken, err = token.SignedString([]byte(t.cfg.JwtSecret))
+	}
 	if err != nil {
-		return "", fmt.Errorf("failed to sign JWT: %w", err)
+		return "", time.Time{}, fmt.Errorf("failed to sign JWT: %w", err)
 	}
 
-	return signedToken, nil
+	return signedToken, expiresAt, nil
 }
 
 // ValidateJWT parses and validates the provided JWT string.
-// It checks the signature method (must be HS256), the signature itself, and standard claims like expiration.
+// It checks the signature method, which must match the key named by the kid header
+// (or be HS256 without configured keys), the signature itself, and standard claims like expiration.
+// Tokens signed with retired keys are rejected.
 //
-// It returns the subject of the token as a UUID.
-func (t *TokenAuthority) ValidateJWT(tokenString string) (subjectUUID uuid.UUID, err error) {
-	token, err := jwt.ParseWithClaims(
+// It returns the claims identifying the user, the token and its session.
+// Whether the token has been revoked is up to the caller to check.
+func (t *TokenAuthority) ValidateJWT(tokenString string) (*AccessToken, error) {
+	claims := &accessClaims{}
+	_, err := jwt.ParseWithClaims(
 		tokenString,
-		&jwt.RegisteredClaims{},
-		func(token *jwt.Token) (any, error) {
-			if token.Method != jwt.SigningMethodHS256 {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Method.Alg())
-			}
-			return []byte(t.cfg.JwtSecret), nil
-		},
+		claims,
+		t.verificationKeyFunc,
+		jwt.WithExpirationRequired(),
 	)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("failed to parse JWT: %w", err)
+		return nil, fmt.Errorf("failed to parse JWT: %w", err)
+	}
+
+	subjectUUID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("token subject is not a valid UUID: %w", err)
 	}
 
-	subject, err := token.Claims.GetSubject()
+	tokenID, err := uuid.Parse(claims.ID)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("failed to extract subject from JWT: %w", err)
+		return nil, fmt.Errorf("token ID is not a valid UUID: %w", err)
 	}
 
-	subjectUUID, err = uuid.Parse(subject)
+	sessionID, err := uuid.Parse(claims.SessionID)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("token subject is not a valid UUID: %w", err)
+		return nil, fmt.Errorf("token session ID is not a valid UUID: %w", err)
 	}
 
-	return subjectUUID, nil
+	return &AccessToken{
+		UserID:    subjectUUID,
+		ID:        tokenID,
+		SessionID: sessionID,
+		Role:      claims.Role,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+func (t *TokenAuthority) verificationKeyFunc(token *jwt.Token) (any, error) {
+	if !t.asymmetric() {
+		if token.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Method.Alg())
+		}
+		return []byte(t.cfg.JwtSecret), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key := t.verificationKey(kid, time.Now())
+	if key == nil {
+		return nil, fmt.Errorf("unknown or retired signing key %q", kid)
+	}
+	if token.Method != key.method {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Method.Alg())
+	}
+	return key.public, nil
 }
@@ -14,11 +14,16 @@ import (
 
 	"github.com/Laelapa/CompanyRegistry/auth/tokenauthority"
 	"github.com/Laelapa/CompanyRegistry/internal/app"
+	"github.com/Laelapa/CompanyRegistry/internal/cache"
 	"github.com/Laelapa/CompanyRegistry/internal/config"
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
 	"github.com/Laelapa/CompanyRegistry/internal/events"
+	"github.com/Laelapa/CompanyRegistry/internal/metrics"
+	"github.com/Laelapa/CompanyRegistry/internal/migrations"
 	"github.com/Laelapa/CompanyRegistry/internal/repository"
 	"github.com/Laelapa/CompanyRegistry/internal/repository/adapters"
 	"github.com/Laelapa/CompanyRegistry/internal/service"
+	"github.com/Laelapa/CompanyRegistry/internal/tracing"
 	"github.com/Laelapa/CompanyRegistry/logging"
 )
 
@@ -45,8 +50,33 @@ func run() error {
 			log.Printf("WARNING: failed to sync logger: %v", syncErr)
 		}
 	}()
-	tokenAuthority := tokenauthority.New(&cfg.Auth)
-	dbPool, err := pgxpool.New(ctx, cfg.DB.URL)
+	shutdownTracing, err := tracing.Setup(ctx, &cfg.Tracing, cfg.Logging.ServiceName, cfg.Environment)
+	if err != nil {
+		return fmt.Errorf("failed to initialize tracing: %w", err)
+	}
+	defer func() {
+		// The signal context is done by now, give the exporter a moment of its own to flush
+		flushCtx, cancelFlush := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancelFlush()
+		if shutdownErr := shutdownTracing(flushCtx); shutdownErr != nil {
+			logger.Warn("Failed to flush pending spans", zap.Error(shutdownErr))
+		}
+	}()
+	logger.Info("Tracing initialized", zap.String(logging.FieldTracingExporter, cfg.Tracing.Exporter))
+
+	tokenAuthority, err := tokenauthority.New(&cfg.Auth)
+	if err != nil {
+		return fmt.Errorf("failed to initialize token authority: %w", err)
+	}
+	if len(cfg.Auth.SigningKeys) == 0 {
+		logger.Warn("No JWT signing keys configured, signing with HS256 and the shared secret")
+	}
+	dbConfig, err := pgxpool.ParseConfig(cfg.DB.URL)
+	if err != nil {
+		return fmt.Errorf("failed to parse database URL: %w", err)
+	}
+	dbConfig.ConnConfig.Tracer = tracing.NewQueryTracer()
+	dbPool, err := pgxpool.NewWithConfig(ctx, dbConfig)
 	if err != nil {
 		return fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -60,6 +90,11 @@ func run() error {
 	}
 	logger.Info("Database connection verified")
 
+	appMetrics := metrics.New()
+	if err = appMetrics.RegisterPool(dbPool); err != nil {
+		return fmt.Errorf("failed to register database pool metrics: %w", err)
+	}
+
 	var kgoClient *kgo.Client // nil if Kafka not configured
 	var producer service.EventProducer
 	kafkaBrokers := cfg.Kafka.Brokers
@@ -73,7 +108,7 @@ func run() error {
 		} else {
 			kgoClient = client
 			defer kgoClient.Close()
-			producer = events.NewProducer(kgoClient)
+			producer = events.NewProducer(kgoClient, appMetrics)
 			logger.Info(
 				"Kafka client initialized",
 				zap.Strings(logging.FieldKafkaBrokers, kafkaBrokers),
@@ -84,22 +119,111 @@ func run() error {
 	} // If no brokers, kafkaClient & producer remain nil
 
 	queries := repository.New(dbPool)
+	transactor := adapters.NewPGTransactor(dbPool)
+
+	// Events are written to the outbox alongside the mutations and published by the relay
+	var outbox service.EventOutbox // nil if Kafka not configured
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	defer stopRelay()
+	relayDone := make(chan struct{})
+	if producer != nil {
+		outboxAdapter := adapters.NewPGOutboxRepoAdapter(queries)
+		outbox = outboxAdapter
+		relay := service.NewOutboxRelay(outboxAdapter, transactor, producer, logger, appMetrics, &cfg.Outbox)
+		go func() {
+			defer close(relayDone)
+			relay.Run(relayCtx)
+		}()
+	} else {
+		close(relayDone)
+	}
+
+	userRepo := adapters.NewPGUserRepoAdapter(queries)
+	companyRepo := adapters.NewPGCompanyRepoAdapter(queries)
+	companyTypes := service.NewCompanyTypeService(adapters.NewPGCompanyTypeRepoAdapter(queries))
+	sessions := service.NewSessionService(
+		adapters.NewPGSessionRepoAdapter(queries),
+		userRepo,
+		transactor,
+		tokenAuthority,
+		logger,
+		cfg.Auth.RefreshTokenLifetime,
+	)
+
+	schemaVersion, err := migrations.LatestVersion()
+	if err != nil {
+		return fmt.Errorf("failed to read the expected schema version: %w", err)
+	}
+	var broker service.BrokerHealth // nil if Kafka not configured
+	if kgoClient != nil {
+		broker = kgoClient
+	}
+	var companyCache service.CompanyCache // nil if caching is disabled
+	if cfg.CompanyCache.Enabled {
+		companyCache = cache.New[*domain.Company](cfg.CompanyCache.Size, cfg.CompanyCache.TTL)
+	}
+
+	// Deleted companies can be restored until the purge job removes them for good
+	companyPurger := service.NewCompanyPurger(
+		companyRepo,
+		transactor,
+		outbox,
+		logger,
+		appMetrics,
+		&cfg.CompanyPurge,
+		cfg.Kafka.Topic.CompanyMutations,
+		cfg.Kafka.EventSource,
+	)
+	go companyPurger.Run(ctx)
+
 	service := &service.Service{
 		User: service.NewUserService(
-			adapters.NewPGUserRepoAdapter(queries),
-			tokenAuthority,
+			userRepo,
+			transactor,
+			outbox,
+			sessions,
 			logger,
-			producer,
+			appMetrics,
 			cfg.Kafka.Topic.UserMutations,
+			cfg.Kafka.EventSource,
 		),
 		Company: service.NewCompanyService(
-			adapters.NewPGCompanyRepoAdapter(queries),
+			companyRepo,
+			companyTypes,
+			companyCache,
+			transactor,
+			outbox,
 			tokenAuthority,
 			logger,
-			producer,
+			appMetrics,
 			cfg.Kafka.Topic.CompanyMutations,
+			cfg.Kafka.EventSource,
+		),
+		CompanyType: companyTypes,
+		Session:     sessions,
+		Health: service.NewHealthService(
+			adapters.NewPGHealthAdapter(dbPool),
+			broker,
+			schemaVersion,
+			cfg.Server.HealthCheckTimeout,
+		),
+		Idempotency: service.NewIdempotencyService(
+			adapters.NewPGIdempotencyRepoAdapter(queries),
+			logger,
+			cfg.Idempotency.KeyTTL,
+			cfg.Idempotency.LockTimeout,
 		),
 	}
+	go service.Idempotency.RunPruning(ctx, cfg.Idempotency.PruneInterval)
+	go sessions.RunPruning(ctx, cfg.Auth.SessionPruneInterval)
+
+	if cfg.Auth.BootstrapAdminUsername != "" {
+		err = service.User.BootstrapAdmin(ctx, cfg.Auth.BootstrapAdminUsername, cfg.Auth.BootstrapAdminPassword)
+		if err != nil {
+			return fmt.Errorf("failed to bootstrap admin %q: %w", cfg.Auth.BootstrapAdminUsername, err)
+		}
+		logger.Info("Bootstrap admin ready", zap.String(logging.FieldUsername, cfg.Auth.BootstrapAdminUsername))
+	}
 
 	app := app.New(
 		&cfg.Server,
@@ -107,9 +231,16 @@ func run() error {
 		service,
 		tokenAuthority,
 		kgoClient,
+		appMetrics,
 	)
-	if err = app.LaunchServer(ctx); err != nil {
-		return fmt.Errorf("failed to launch server: %w", err)
+	serverErr := app.LaunchServer(ctx)
+
+	// The server no longer accepts requests so no new events can be enqueued, let the relay drain the rest
+	stopRelay()
+	<-relayDone
+
+	if serverErr != nil {
+		return fmt.Errorf("failed to launch server: %w", serverErr)
 	}
 	return nil
 }
@@ -5,9 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/Laelapa/CompanyRegistry/auth/tokenauthority"
 	"github.com/Laelapa/CompanyRegistry/internal/config"
+	"github.com/Laelapa/CompanyRegistry/internal/metrics"
 	"github.com/Laelapa/CompanyRegistry/internal/middleware"
 	"github.com/Laelapa/CompanyRegistry/internal/routes"
 	"github.com/Laelapa/CompanyRegistry/internal/service"
@@ -19,8 +21,10 @@ import (
 
 type App struct {
 	server       *http.Server
+	adminServer  *http.Server // operational endpoints, served on a separate port
 	serverConfig *config.ServerConfig
 	logger       *logging.Logger
+	health       *service.HealthService
 }
 
 func New(
@@ -29,6 +33,7 @@ func New(
 	service *service.Service,
 	tokenAuthority *tokenauthority.TokenAuthority,
 	kafkaClient *kgo.Client,
+	metrics *metrics.Metrics,
 ) *App {
 	return &App{
 		server: &http.Server{
@@ -39,14 +44,26 @@ func New(
 				service,
 				tokenAuthority,
 				kafkaClient,
+				metrics,
+				&serverConfig.AccessLog,
+				serverConfig.Timeouts.ExportTimeout,
 			),
 			ReadHeaderTimeout: serverConfig.Timeouts.ReadHeaderTimeout,
 			ReadTimeout:       serverConfig.Timeouts.ReadTimeout,
 			WriteTimeout:      serverConfig.Timeouts.WriteTimeout,
 			IdleTimeout:       serverConfig.Timeouts.IdleTimeout,
 		},
+		adminServer: &http.Server{
+			Addr:              fmt.Sprintf(":%s", serverConfig.AdminPort),
+			Handler:           newAdminMux(metrics),
+			ReadHeaderTimeout: serverConfig.Timeouts.ReadHeaderTimeout,
+			ReadTimeout:       serverConfig.Timeouts.ReadTimeout,
+			WriteTimeout:      serverConfig.Timeouts.WriteTimeout,
+			IdleTimeout:       serverConfig.Timeouts.IdleTimeout,
+		},
 		serverConfig: serverConfig,
 		logger:       logger,
+		health:       service.Health,
 	}
 }
 
@@ -56,6 +73,9 @@ func newMux(
 	service *service.Service,
 	tokenAuthority *tokenauthority.TokenAuthority,
 	kafkaClient *kgo.Client,
+	metrics *metrics.Metrics,
+	accessLogConfig *config.AccessLogConfig,
+	exportTimeout time.Duration,
 ) http.Handler {
 	mux := routes.Setup(
 		// staticDir,
@@ -63,35 +83,55 @@ func newMux(
 		service,
 		tokenAuthority,
 		kafkaClient,
+		exportTimeout,
 	)
-	return attachBasicMiddleware(mux, logger)
+	return attachBasicMiddleware(mux, logger, metrics, accessLogConfig)
 }
 
-func attachBasicMiddleware(handler http.Handler, logger *logging.Logger) http.Handler {
-	handler = middleware.RequestLogger(handler, logger)
+func attachBasicMiddleware(
+	handler http.Handler,
+	logger *logging.Logger,
+	metrics *metrics.Metrics,
+	accessLogConfig *config.AccessLogConfig,
+) http.Handler {
+	handler = middleware.InstrumentHTTP(handler, metrics)
+	handler = middleware.AccessLog(handler, logger, accessLogConfig)
+	handler = middleware.RequestID(handler)
+	// Outermost, so every log line of the request carries its trace
+	handler = middleware.Trace(handler)
 
 	return handler
 }
 
+// newAdminMux routes the operational endpoints, which are not part of the public API.
+func newAdminMux(metrics *metrics.Metrics) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	return mux
+}
+
 func (app *App) LaunchServer(ctx context.Context) error {
-	errChan := make(chan error, 1)
+	errChan := make(chan error, 2)
 
-	go func() {
+	serve := func(srv *http.Server, name string) {
 		app.logger.Info(
-			"HTTP Server starting",
-			zap.String(logging.FieldServerAddr, app.server.Addr),
+			name+" starting",
+			zap.String(logging.FieldServerAddr, srv.Addr),
 		)
-		if err := app.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			app.logger.Error(
-				"ListenAndServe returned with error",
+				name+" ListenAndServe returned with error",
 				zap.Error(err),
 			)
 			errChan <- err
 		}
-	}()
+	}
+	go serve(app.server, "HTTP Server")
+	go serve(app.adminServer, "Admin HTTP Server")
 
 	select {
 	case err := <-errChan:
+		app.ShutdownServer()
 		return fmt.Errorf("server error: %w", err)
 	case <-ctx.Done():
 		app.logger.Info("Shutting down HTTP server...")
@@ -100,27 +140,45 @@ func (app *App) LaunchServer(ctx context.Context) error {
 	}
 }
 
+// ShutdownServer gracefully shuts down the API server, then the admin server,
+// so metrics remain scrapable while in-flight requests finish.
+// Readiness fails for the configured drain delay beforehand, while requests are still served,
+// so load balancers can take the instance out of rotation first.
 func (app *App) ShutdownServer() {
+	app.health.Drain()
+	if app.serverConfig.DrainDelay > 0 {
+		app.logger.Info(
+			"Draining traffic before shutdown",
+			zap.Duration(logging.FieldDrainDelay, app.serverConfig.DrainDelay),
+		)
+		time.Sleep(app.serverConfig.DrainDelay)
+	}
+
+	app.shutdown(app.server, "HTTP server")
+	app.shutdown(app.adminServer, "Admin HTTP server")
+}
+
+func (app *App) shutdown(srv *http.Server, name string) {
 	ctx, cancel := context.WithTimeout(context.Background(), app.serverConfig.ShutdownTimeout)
 	defer cancel()
 
-	if err := app.server.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+	if err := srv.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		app.logger.Error(
-			"HTTP server shutdown returned with error",
+			name+" shutdown returned with error",
 			zap.Error(err),
 		)
 		app.logger.Warn(
-			"Forcing HTTP server shutdown",
+			"Forcing " + name + " shutdown",
 		)
-		if closeErr := app.server.Close(); closeErr != nil {
+		if closeErr := srv.Close(); closeErr != nil {
 			app.logger.Error(
-				"HTTP server FORCED shutdown returned with error",
+				name+" FORCED shutdown returned with error",
 				zap.Error(closeErr),
 			)
 			return
 		}
 	}
-	app.logger.Info("HTTP server shut down successfully")
+	app.logger.Info(name + " shut down successfully")
 }
 
 // ServeHTTP implements http.Handler interface for App.
@@ -128,3 +186,9 @@ func (app *App) ShutdownServer() {
 func (app *App) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	app.server.Handler.ServeHTTP(w, r)
 }
+
+// AdminHandler returns the handler of the admin server.
+// This allows testing the operational endpoints without starting a listener.
+func (app *App) AdminHandler() http.Handler {
+	return app.adminServer.Handler
+}
@@ -0,0 +1,83 @@
+// Package authz holds the authorization rules of the API.
+// Rules are plain functions of the acting principal and the resource at hand,
+// returning nil when the action is allowed and a *Denial explaining why otherwise.
+package authz
+
+import (
+	"context"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/util/ctxutils"
+
+	"github.com/google/uuid"
+)
+
+// Principal is the authenticated user an action is performed on behalf of.
+type Principal struct {
+	UserID uuid.UUID
+	Role   domain.Role
+}
+
+// Denial is returned by rules that deny an action.
+// It unwraps to domain.ErrForbidden.
+type Denial struct {
+	Reason string
+}
+
+// Rule is an authorization rule that depends on the principal alone.
+type Rule func(p Principal) error
+
+func (d *Denial) Error() string {
+	return "forbidden: " + d.Reason
+}
+
+func (d *Denial) Unwrap() error {
+	return domain.ErrForbidden
+}
+
+// PrincipalFromContext assembles the principal of a request authenticated by the jwt authentication middleware.
+// It returns false if there is none.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	userID, ok := ctxutils.GetUserIDFromContext(ctx)
+	if !ok {
+		return Principal{}, false
+	}
+	role, _ := ctxutils.GetUserRoleFromContext(ctx)
+	return Principal{UserID: userID, Role: domain.Role(role)}, true
+}
+
+// CanModifyCompany allows the company's creator, editors and admins to update or delete a company.
+func CanModifyCompany(p Principal, c *domain.Company) error {
+	if p.Role == domain.RoleAdmin || p.Role == domain.RoleEditor {
+		return nil
+	}
+	if p.UserID != uuid.Nil && c.CreatedBy != nil && *c.CreatedBy == p.UserID {
+		return nil
+	}
+	return &Denial{Reason: "only the company's creator, an editor or an admin can modify it"}
+}
+
+// CanGovernCompanyLifecycle allows editors and admins to move companies through their lifecycle,
+// creators can't approve or reinstate their own companies.
+func CanGovernCompanyLifecycle(p Principal) error {
+	if p.Role == domain.RoleAdmin || p.Role == domain.RoleEditor {
+		return nil
+	}
+	return &Denial{Reason: "only editors and admins can change the status of companies"}
+}
+
+// CanAssignRoles allows admins to change the roles of users.
+func CanAssignRoles(p Principal) error {
+	if p.Role == domain.RoleAdmin {
+		return nil
+	}
+	return &Denial{Reason: "only admins can assign roles"}
+}
+
+// CanManageCompanyTypes allows admins to add, change and remove company types.
+func CanManageCompanyTypes(p Principal) error {
+	if p.Role == domain.RoleAdmin {
+		return nil
+	}
+	return &Denial{Reason: "only admins can manage company types"}
+}
@@ -0,0 +1,72 @@
+package authz_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Laelapa/CompanyRegistry/internal/authz"
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanModifyCompany(t *testing.T) {
+	creator := uuid.New()
+	other := uuid.New()
+	company := &domain.Company{CreatedBy: &creator}
+	orphan := &domain.Company{CreatedBy: &uuid.Nil}
+
+	tests := []struct {
+		name      string
+		principal authz.Principal
+		company   *domain.Company
+		allowed   bool
+	}{
+		{"creator", authz.Principal{UserID: creator, Role: domain.RoleViewer}, company, true},
+		{"other viewer", authz.Principal{UserID: other, Role: domain.RoleViewer}, company, false},
+		{"other editor", authz.Principal{UserID: other, Role: domain.RoleEditor}, company, true},
+		{"other admin", authz.Principal{UserID: other, Role: domain.RoleAdmin}, company, true},
+		{"unknown role", authz.Principal{UserID: other, Role: "superuser"}, company, false},
+		{"company without creator", authz.Principal{UserID: uuid.Nil, Role: domain.RoleViewer}, orphan, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := authz.CanModifyCompany(tt.principal, tt.company)
+			if tt.allowed {
+				assert.NoError(t, err)
+				return
+			}
+			assertDenied(t, err)
+		})
+	}
+}
+
+func TestCanGovernCompanyLifecycle(t *testing.T) {
+	assert.NoError(t, authz.CanGovernCompanyLifecycle(authz.Principal{UserID: uuid.New(), Role: domain.RoleAdmin}))
+	assert.NoError(t, authz.CanGovernCompanyLifecycle(authz.Principal{UserID: uuid.New(), Role: domain.RoleEditor}))
+	assertDenied(t, authz.CanGovernCompanyLifecycle(authz.Principal{UserID: uuid.New(), Role: domain.RoleViewer}))
+	assertDenied(t, authz.CanGovernCompanyLifecycle(authz.Principal{UserID: uuid.New(), Role: "superuser"}))
+}
+
+func TestCanAssignRoles(t *testing.T) {
+	assert.NoError(t, authz.CanAssignRoles(authz.Principal{UserID: uuid.New(), Role: domain.RoleAdmin}))
+	assertDenied(t, authz.CanAssignRoles(authz.Principal{UserID: uuid.New(), Role: domain.RoleEditor}))
+	assertDenied(t, authz.CanAssignRoles(authz.Principal{UserID: uuid.New(), Role: domain.RoleViewer}))
+}
+
+func TestCanManageCompanyTypes(t *testing.T) {
+	assert.NoError(t, authz.CanManageCompanyTypes(authz.Principal{UserID: uuid.New(), Role: domain.RoleAdmin}))
+	assertDenied(t, authz.CanManageCompanyTypes(authz.Principal{UserID: uuid.New(), Role: domain.RoleEditor}))
+	assertDenied(t, authz.CanManageCompanyTypes(authz.Principal{UserID: uuid.New(), Role: domain.RoleViewer}))
+}
+
+func assertDenied(t *testing.T, err error) {
+	t.Helper()
+
+	var denial *authz.Denial
+	if assert.ErrorAs(t, err, &denial) {
+		assert.NotEmpty(t, denial.Reason)
+	}
+	assert.True(t, errors.Is(err, domain.ErrForbidden))
+}
@@ -0,0 +1,100 @@
+// Package cache provides a size-bounded in-process cache with expiring entries.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache is a least-recently-used cache whose entries expire after a fixed TTL.
+// It is safe for concurrent use.
+//
+// Read-through callers should take the Generation before loading a value and hand it to Add,
+// so a value loaded before a concurrent Remove is not cached after it.
+type Cache[V any] struct {
+	mu         sync.Mutex
+	size       int
+	ttl        time.Duration
+	entries    map[string]*list.Element
+	lru        *list.List // most recently used at the front
+	generation uint64
+}
+
+type entry[V any] struct {
+	key       string
+	value     V
+	expiresAt time.Time
+}
+
+// New creates a Cache holding at most size entries for ttl each.
+func New[V any](size int, ttl time.Duration) *Cache[V] {
+	return &Cache[V]{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[string]*list.Element, size),
+		lru:     list.New(),
+	}
+}
+
+// Get returns the value cached under key, if it's there and has not expired.
+func (c *Cache[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	el, ok := c.entries[key]
+	if !ok {
+		return zero, false
+	}
+	e := el.Value.(*entry[V]) //nolint:errcheck // the list only holds entries
+	if time.Now().After(e.expiresAt) {
+		c.remove(el)
+		return zero, false
+	}
+	c.lru.MoveToFront(el)
+	return e.value, true
+}
+
+// Generation changes on every Remove.
+func (c *Cache[V]) Generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// Add caches value under key, evicting the least recently used entry if the cache is full.
+// It does nothing if entries have been removed since generation was taken, the value may be outdated.
+func (c *Cache[V]) Add(key string, value V, generation uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+	if c.lru.Len() >= c.size {
+		c.remove(c.lru.Back())
+	}
+	c.entries[key] = c.lru.PushFront(&entry[V]{key: key, value: value, expiresAt: time.Now().Add(c.ttl)})
+}
+
+// Remove drops the entries cached under keys.
+func (c *Cache[V]) Remove(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	for _, key := range keys {
+		if el, ok := c.entries[key]; ok {
+			c.remove(el)
+		}
+	}
+}
+
+func (c *Cache[V]) remove(el *list.Element) {
+	c.lru.Remove(el)
+	delete(c.entries, el.Value.(*entry[V]).key) //nolint:errcheck // the list only holds entries
+}
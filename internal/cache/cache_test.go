@@ -0,0 +1,51 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/cache"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := cache.New[int](2, time.Hour)
+	c.Add("a", 1, c.Generation())
+	c.Add("b", 2, c.Generation())
+	c.Get("a")
+	c.Add("c", 3, c.Generation())
+
+	_, ok := c.Get("b")
+	assert.False(t, ok, "b was the least recently used entry")
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	v, ok = c.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+}
+
+func TestCacheExpiresEntries(t *testing.T) {
+	c := cache.New[int](2, time.Millisecond)
+	c.Add("a", 1, c.Generation())
+	time.Sleep(5 * time.Millisecond)
+
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+}
+
+func TestCacheIgnoresValuesLoadedBeforeRemove(t *testing.T) {
+	c := cache.New[int](2, time.Hour)
+	generation := c.Generation()
+	c.Remove("a")
+	c.Add("a", 1, generation)
+
+	_, ok := c.Get("a")
+	assert.False(t, ok, "a value loaded before the removal may be outdated")
+
+	c.Add("a", 2, c.Generation())
+	c.Remove("a")
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+}
@@ -1,24 +1,42 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"strings"
 	"time"
 )
 
 type Config struct {
-	Environment string
-	Server      ServerConfig
-	DB          DatabaseConfig
-	Auth        AuthConfig
-	Kafka       KafkaConfig
-	Logging     LoggingConfig
+	Environment  string
+	Server       ServerConfig
+	DB           DatabaseConfig
+	Auth         AuthConfig
+	Kafka        KafkaConfig
+	Outbox       OutboxConfig
+	CompanyCache CompanyCacheConfig
+	Idempotency  IdempotencyConfig
+	CompanyPurge CompanyPurgeConfig
+	Logging      LoggingConfig
+	Tracing      TracingConfig
 }
 
 type ServerConfig struct {
 	Port            string
+	AdminPort       string // serves operational endpoints such as /metrics, keep it off the public network
 	ShutdownTimeout time.Duration
+	DrainDelay      time.Duration // how long readiness fails before connections are closed, so load balancers notice
+	// HealthCheckTimeout bounds every dependency check of the readiness endpoint
+	HealthCheckTimeout time.Duration
 	// StaticDir       string
-	Timeouts ServerTimeoutsConfig
+	Timeouts  ServerTimeoutsConfig
+	AccessLog AccessLogConfig
+}
+
+// AccessLogConfig controls which completed requests are logged.
+type AccessLogConfig struct {
+	SuccessSampleRate float64 // share of responses below 400 that are logged
+	AlwaysLogErrors   bool    // log every 4xx and 5xx response regardless of the sample rate
 }
 
 type ServerTimeoutsConfig struct {
@@ -26,6 +44,8 @@ type ServerTimeoutsConfig struct {
 	ReadTimeout       time.Duration
 	WriteTimeout      time.Duration
 	IdleTimeout       time.Duration
+	// ExportTimeout bounds a whole export, whose write deadline is extended while it streams
+	ExportTimeout time.Duration
 }
 
 type DatabaseConfig struct {
@@ -33,20 +53,76 @@ type DatabaseConfig struct {
 }
 
 type AuthConfig struct {
-	JwtSecret   string
-	JwtIssuer   string
-	JwtLifetime time.Duration
+	JwtSecret            string // HS256 fallback when no signing keys are configured
+	JwtIssuer            string
+	JwtLifetime          time.Duration
+	RefreshTokenLifetime time.Duration
+	SigningKeys          []SigningKeyConfig
+	SessionPruneInterval time.Duration // how often expired sessions and access token revocations are removed
+	// The bootstrap admin is created, or promoted, at startup so a fresh deployment has an admin to assign roles.
+	// An empty username skips it.
+	BootstrapAdminUsername string
+	BootstrapAdminPassword string
+}
+
+// SigningKeyConfig describes an asymmetric JWT signing key and its place in the rotation schedule.
+// A key is published and verifies tokens until it retires, and signs from SignFrom
+// until a key with a later SignFrom takes over.
+type SigningKeyConfig struct {
+	ID       string // kid
+	PEMFile  string // private key, or public key for keys that only verify
+	SignFrom time.Time
+	RetireAt time.Time // zero means never
 }
 
 type KafkaConfig struct {
-	ClientID string
-	Brokers  []string
-	Topic    struct {
+	ClientID    string
+	Brokers     []string
+	EventSource string // CloudEvents source attribute of the published events
+	Topic       struct {
 		CompanyMutations string
 		UserMutations    string
 	}
 }
 
+type OutboxConfig struct {
+	PollInterval     time.Duration
+	BatchSize        int
+	PublishTimeout   time.Duration // per event
+	MaxAttempts      int           // publish attempts after which an event is parked
+	MaxRetryBackoff  time.Duration
+	LagWarnThreshold time.Duration // log a warning when the oldest pending event is older than this
+	DrainTimeout     time.Duration // how long shutdown waits for pending events to be published
+}
+
+// CompanyCacheConfig controls the in-process cache of company lookups by name.
+type CompanyCacheConfig struct {
+	Enabled bool
+	TTL     time.Duration // bounds how long changes made through other instances go unnoticed
+	Size    int           // maximum number of companies held
+}
+
+// IdempotencyConfig controls how long the responses of requests sent with an Idempotency-Key are kept.
+type IdempotencyConfig struct {
+	KeyTTL        time.Duration // how long retries get the recorded response
+	LockTimeout   time.Duration // after which a key whose request never completed can be used again
+	PruneInterval time.Duration // how often expired keys are removed
+}
+
+// CompanyPurgeConfig controls how long deleted companies can be restored before they're removed for good.
+type CompanyPurgeConfig struct {
+	Retention time.Duration // how long after their deletion companies are purged
+	Interval  time.Duration // how often the purge job runs
+	BatchSize int           // companies purged per transaction
+}
+
+type TracingConfig struct {
+	Exporter     string // none, stdout or otlp
+	OTLPEndpoint string // host:port of an OTLP/HTTP collector
+	OTLPInsecure bool   // plain HTTP to the collector
+	SampleRatio  float64
+}
+
 type LoggingConfig struct {
 	ServiceName     string
 	LoggerSetup     string
@@ -58,18 +134,63 @@ const (
 	defaultEnv = "prod"
 
 	// Server
-	defaultShutdownTimeout = 5 * time.Second
+	defaultShutdownTimeout    = 5 * time.Second
+	defaultDrainDelay         = 5 * time.Second
+	defaultHealthCheckTimeout = 2 * time.Second
 	// defaultStaticDir       = "./static"
 	// Server Timeouts
 	defaultReadHeaderTimeout = 10 * time.Second  // For slow headers
 	defaultReadTimeout       = 30 * time.Second  // For slow requests
 	defaultWriteTimeout      = 30 * time.Second  // For preventing clients from keeping connections open
 	defaultIdleTimeout       = 120 * time.Second // For closing idle connections
+	defaultExportTimeout     = 10 * time.Minute  // For exports, which hold a transaction open while streaming
+	// Access log
+	defaultAccessLogSampleRate = 1.0
 
 	// Auth
-	defaultMaxHeaderLength = 1024
-	defaultJwtLifetime     = 15 * time.Minute
-	defaultJwtSecret       = "Default JWT Secret - DO NOT USE IN PRODUCTION" //nolint:gosec // hardcoded secret for dev/testing
+	defaultMaxHeaderLength      = 1024
+	defaultJwtLifetime          = 15 * time.Minute
+	defaultRefreshLifetime      = 30 * 24 * time.Hour
+	defaultSessionPruneInterval = 1 * time.Hour
+	defaultJwtSecret            = "Default JWT Secret - DO NOT USE IN PRODUCTION" //nolint:gosec // hardcoded secret for dev/testing
+
+	// Bootstrap admin, checked like signup. bcrypt ignores anything past 72 bytes of a password
+	maxUsernameLength = 255
+	maxPasswordLength = 72
+
+	// Outbox
+	defaultOutboxPollInterval     = 1 * time.Second
+	defaultOutboxBatchSize        = 100
+	maxOutboxBatchSize            = 10000
+	defaultOutboxPublishTimeout   = 5 * time.Second
+	defaultOutboxMaxAttempts      = 20
+	maxOutboxMaxAttempts          = 1000
+	defaultOutboxMaxRetryBackoff  = 5 * time.Minute
+	defaultOutboxLagWarnThreshold = 1 * time.Minute
+	defaultOutboxDrainTimeout     = 10 * time.Second
+
+	// Company cache
+	defaultCompanyCacheTTL  = 30 * time.Second
+	defaultCompanyCacheSize = 1000
+	maxCompanyCacheSize     = 1_000_000
+
+	// Idempotency
+	defaultIdempotencyKeyTTL        = 24 * time.Hour
+	defaultIdempotencyLockTimeout   = 1 * time.Minute
+	defaultIdempotencyPruneInterval = 1 * time.Hour
+
+	// Company purge
+	defaultCompanyPurgeRetention = 30 * 24 * time.Hour
+	defaultCompanyPurgeInterval  = 1 * time.Hour
+	defaultCompanyPurgeBatchSize = 100
+	maxCompanyPurgeBatchSize     = 10000
+
+	// Tracing
+	TracingExporterNone    = "none"
+	TracingExporterStdout  = "stdout"
+	TracingExporterOTLP    = "otlp"
+	defaultOTLPEndpoint    = "localhost:4318"
+	defaultTraceSampleRate = 1.0
 
 	// Logging
 	defaultServiceName = "my-service"
@@ -92,31 +213,52 @@ func Load() (*Config, error) {
 	if err != nil {
 		return nil, err
 	}
+	signingKeys, err := getEnvSigningKeys("JWT_SIGNING_KEYS")
+	if err != nil {
+		return nil, err
+	}
 
 	cfg := &Config{
 		Environment: getEnvWithFallbackAndValidOptions("ENVIRONMENT", defaultEnv, validEnvs...),
 		Server: ServerConfig{
-			Port:            getEnvWithFallbackAndCustomValidation("SERVER_PORT", "8080", validatePort),
-			ShutdownTimeout: getEnvDurationWithFallback("SERVER_SHUTDOWN_TIMEOUT", defaultShutdownTimeout),
+			Port:               getEnvWithFallbackAndCustomValidation("SERVER_PORT", "8080", validatePort),
+			AdminPort:          getEnvWithFallbackAndCustomValidation("ADMIN_PORT", "9090", validatePort),
+			ShutdownTimeout:    getEnvDurationWithFallback("SERVER_SHUTDOWN_TIMEOUT", defaultShutdownTimeout),
+			DrainDelay:         getEnvDurationWithFallback("SERVER_DRAIN_DELAY", defaultDrainDelay),
+			HealthCheckTimeout: getEnvDurationWithFallback("HEALTH_CHECK_TIMEOUT", defaultHealthCheckTimeout),
 			// StaticDir:       getEnvWithFallback("SERVER_STATIC_DIR", defaultStaticDir), // Disable for now
 			Timeouts: ServerTimeoutsConfig{
 				ReadHeaderTimeout: getEnvDurationWithFallback("SERVER_READ_HEADER_TIMEOUT", defaultReadHeaderTimeout),
 				ReadTimeout:       getEnvDurationWithFallback("SERVER_READ_TIMEOUT", defaultReadTimeout),
 				WriteTimeout:      getEnvDurationWithFallback("SERVER_WRITE_TIMEOUT", defaultWriteTimeout),
 				IdleTimeout:       getEnvDurationWithFallback("SERVER_IDLE_TIMEOUT", defaultIdleTimeout),
+				ExportTimeout:     getEnvDurationWithFallback("SERVER_EXPORT_TIMEOUT", defaultExportTimeout),
+			},
+			AccessLog: AccessLogConfig{
+				SuccessSampleRate: getEnvFloatInRangeWithFallback(
+					"ACCESS_LOG_SUCCESS_SAMPLE_RATE", defaultAccessLogSampleRate, 0, 1,
+				),
+				AlwaysLogErrors: getEnvBoolWithFallback("ACCESS_LOG_ALWAYS_LOG_ERRORS", true),
 			},
 		},
 		DB: DatabaseConfig{
 			URL: dbURL,
 		},
 		Auth: AuthConfig{
-			JwtSecret:   getEnvWithFallback("JWT_SECRET", defaultJwtSecret),
-			JwtIssuer:   getEnvWithFallback("JWT_ISSUER", getEnvWithFallback("SERVICE_NAME", "my-service")),
-			JwtLifetime: getEnvDurationWithFallback("JWT_LIFETIME", defaultJwtLifetime),
+			JwtSecret:            getEnvWithFallback("JWT_SECRET", defaultJwtSecret),
+			JwtIssuer:            getEnvWithFallback("JWT_ISSUER", getEnvWithFallback("SERVICE_NAME", "my-service")),
+			JwtLifetime:          getEnvDurationWithFallback("JWT_LIFETIME", defaultJwtLifetime),
+			RefreshTokenLifetime: getEnvDurationWithFallback("REFRESH_TOKEN_LIFETIME", defaultRefreshLifetime),
+			SigningKeys:          signingKeys,
+			SessionPruneInterval: getEnvDurationWithFallback("SESSION_PRUNE_INTERVAL", defaultSessionPruneInterval),
+
+			BootstrapAdminUsername: getEnvWithFallback("BOOTSTRAP_ADMIN_USERNAME", ""),
+			BootstrapAdminPassword: getEnvWithFallback("BOOTSTRAP_ADMIN_PASSWORD", ""),
 		},
 		Kafka: KafkaConfig{
-			ClientID: getEnvWithFallback("SERVICE_NAME", defaultServiceName),
-			Brokers:  strings.Split(getEnvWithFallback("KAFKA_BROKERS", "localhost:9092"), ","),
+			ClientID:    getEnvWithFallback("SERVICE_NAME", defaultServiceName),
+			Brokers:     strings.Split(getEnvWithFallback("KAFKA_BROKERS", "localhost:9092"), ","),
+			EventSource: getEnvWithFallback("EVENT_SOURCE", getEnvWithFallback("SERVICE_NAME", defaultServiceName)),
 			Topic: struct {
 				CompanyMutations string
 				UserMutations    string
@@ -125,11 +267,75 @@ func Load() (*Config, error) {
 				UserMutations:    getEnvWithFallback("KAFKA_TOPIC_USERS", "user.mutations"),
 			},
 		},
+		Outbox: OutboxConfig{
+			PollInterval:   getEnvDurationWithFallback("OUTBOX_POLL_INTERVAL", defaultOutboxPollInterval),
+			BatchSize:      getEnvIntInRangeWithFallback("OUTBOX_BATCH_SIZE", defaultOutboxBatchSize, 1, maxOutboxBatchSize),
+			PublishTimeout: getEnvDurationWithFallback("OUTBOX_PUBLISH_TIMEOUT", defaultOutboxPublishTimeout),
+			MaxAttempts: getEnvIntInRangeWithFallback(
+				"OUTBOX_MAX_ATTEMPTS", defaultOutboxMaxAttempts, 1, maxOutboxMaxAttempts,
+			),
+			MaxRetryBackoff:  getEnvDurationWithFallback("OUTBOX_MAX_RETRY_BACKOFF", defaultOutboxMaxRetryBackoff),
+			LagWarnThreshold: getEnvDurationWithFallback("OUTBOX_LAG_WARN_THRESHOLD", defaultOutboxLagWarnThreshold),
+			DrainTimeout:     getEnvDurationWithFallback("OUTBOX_DRAIN_TIMEOUT", defaultOutboxDrainTimeout),
+		},
+		CompanyCache: CompanyCacheConfig{
+			Enabled: getEnvBoolWithFallback("COMPANY_CACHE_ENABLED", false),
+			TTL:     getEnvDurationWithFallback("COMPANY_CACHE_TTL", defaultCompanyCacheTTL),
+			Size: getEnvIntInRangeWithFallback(
+				"COMPANY_CACHE_SIZE", defaultCompanyCacheSize, 1, maxCompanyCacheSize,
+			),
+		},
+		Idempotency: IdempotencyConfig{
+			KeyTTL:      getEnvDurationWithFallback("IDEMPOTENCY_KEY_TTL", defaultIdempotencyKeyTTL),
+			LockTimeout: getEnvDurationWithFallback("IDEMPOTENCY_LOCK_TIMEOUT", defaultIdempotencyLockTimeout),
+			PruneInterval: getEnvDurationWithFallback(
+				"IDEMPOTENCY_PRUNE_INTERVAL", defaultIdempotencyPruneInterval,
+			),
+		},
+		CompanyPurge: CompanyPurgeConfig{
+			Retention: getEnvDurationWithFallback("COMPANY_PURGE_RETENTION", defaultCompanyPurgeRetention),
+			Interval:  getEnvDurationWithFallback("COMPANY_PURGE_INTERVAL", defaultCompanyPurgeInterval),
+			BatchSize: getEnvIntInRangeWithFallback(
+				"COMPANY_PURGE_BATCH_SIZE", defaultCompanyPurgeBatchSize, 1, maxCompanyPurgeBatchSize,
+			),
+		},
 		Logging: LoggingConfig{
 			ServiceName:     getEnvWithFallback("SERVICE_NAME", defaultServiceName),
 			LoggerSetup:     getEnvWithFallbackAndValidOptions("LOGGER_SETUP", defaultLoggerSetup, validEnvs...),
 			MaxHeaderLength: getEnvIntWithFallback("MAX_HEADER_LENGTH", defaultMaxHeaderLength),
 		},
+		Tracing: TracingConfig{
+			Exporter: getEnvWithFallbackAndValidOptions(
+				"TRACING_EXPORTER", TracingExporterNone,
+				TracingExporterNone, TracingExporterStdout, TracingExporterOTLP,
+			),
+			OTLPEndpoint: getEnvWithFallback("TRACING_OTLP_ENDPOINT", defaultOTLPEndpoint),
+			OTLPInsecure: getEnvBoolWithFallback("TRACING_OTLP_INSECURE", false),
+			SampleRatio:  getEnvFloatInRangeWithFallback("TRACING_SAMPLE_RATIO", defaultTraceSampleRate, 0, 1),
+		},
+	}
+	// Expired refresh token families are pruned, access tokens issued for them must have expired by then
+	if cfg.Auth.JwtLifetime >= cfg.Auth.RefreshTokenLifetime {
+		return nil, fmt.Errorf(
+			"JWT_LIFETIME must be shorter than REFRESH_TOKEN_LIFETIME, got %s and %s",
+			cfg.Auth.JwtLifetime, cfg.Auth.RefreshTokenLifetime,
+		)
+	}
+	// The bootstrap admin has to pass the checks of signup, so it can log in like any other user
+	if cfg.Auth.BootstrapAdminUsername != "" {
+		switch {
+		case cfg.Auth.BootstrapAdminPassword == "":
+			return nil, errors.New("BOOTSTRAP_ADMIN_PASSWORD is required along with BOOTSTRAP_ADMIN_USERNAME")
+		case !validateUsername(cfg.Auth.BootstrapAdminUsername):
+			return nil, fmt.Errorf(
+				"BOOTSTRAP_ADMIN_USERNAME must be alphanumeric and at most %d characters", maxUsernameLength,
+			)
+		case len(cfg.Auth.BootstrapAdminPassword) > maxPasswordLength:
+			return nil, fmt.Errorf("BOOTSTRAP_ADMIN_PASSWORD must be at most %d bytes", maxPasswordLength)
+		}
+	}
+	if cfg.Server.AdminPort == cfg.Server.Port {
+		return nil, fmt.Errorf("ADMIN_PORT must differ from SERVER_PORT, both are %s", cfg.Server.Port)
 	}
 	return cfg, nil
 }
@@ -6,6 +6,7 @@ import (
 	"os"
 	"slices"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -51,6 +52,55 @@ func getEnvIntWithFallback(key string, fallback int) int {
 	return intVal
 }
 
+// getEnvIntInRangeWithFallback retrieves an int from an environment variable,
+// uses fallback if not set, not parseable, or outside [minVal, maxVal].
+func getEnvIntInRangeWithFallback(key string, fallback, minVal, maxVal int) int {
+	intVal := getEnvIntWithFallback(key, fallback)
+	if intVal < minVal || intVal > maxVal {
+		log.Printf("WARNING: env %v must be within [%v, %v], got %v, falling back to %v", key, minVal, maxVal, intVal, fallback)
+		return fallback
+	}
+	return intVal
+}
+
+// getEnvFloatInRangeWithFallback retrieves a float from an environment variable,
+// uses fallback if not set, not parseable, or outside [minVal, maxVal].
+func getEnvFloatInRangeWithFallback(key string, fallback, minVal, maxVal float64) float64 {
+	val := os.Getenv(key)
+	if val == "" {
+		log.Printf("WARNING: env %v not set, falling back to %v", key, fallback)
+		return fallback
+	}
+
+	floatVal, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		log.Printf("WARNING: could not parse float from env %v, falling back to %v", key, fallback)
+		return fallback
+	}
+
+	if floatVal < minVal || floatVal > maxVal {
+		log.Printf("WARNING: env %v must be within [%v, %v], got %v, falling back to %v", key, minVal, maxVal, floatVal, fallback)
+		return fallback
+	}
+	return floatVal
+}
+
+func getEnvBoolWithFallback(key string, fallback bool) bool {
+	val := os.Getenv(key)
+	if val == "" {
+		log.Printf("WARNING: env %v not set, falling back to %v", key, fallback)
+		return fallback
+	}
+
+	boolVal, err := strconv.ParseBool(val)
+	if err != nil {
+		log.Printf("WARNING: could not parse bool from env %v, falling back to %v", key, fallback)
+		return fallback
+	}
+
+	return boolVal
+}
+
 // getEnvDurationWithFallback retrieves a duration from an environment variable,
 // uses fallback if non-positive, not parseable, or not set.
 func getEnvDurationWithFallback(key string, fallback time.Duration) time.Duration {
@@ -87,3 +137,45 @@ func getEnvWithFallbackAndCustomValidation(key string, fallback string, validate
 	}
 	return val
 }
+
+// getEnvSigningKeys parses the JWT signing keys from a comma separated environment variable.
+// Each entry has the form kid|pem_file[|sign_from[|retire_at]] with optional RFC 3339 timestamps.
+// An unset variable yields no keys, malformed entries are an error.
+func getEnvSigningKeys(key string) ([]SigningKeyConfig, error) {
+	val := os.Getenv(key)
+	if val == "" {
+		log.Printf("WARNING: env %v not set, falling back to HS256 with the shared JWT secret", key)
+		return nil, nil
+	}
+
+	var keys []SigningKeyConfig
+	seen := make(map[string]bool)
+	for entry := range strings.SplitSeq(val, ",") {
+		fields := strings.Split(strings.TrimSpace(entry), "|")
+		if len(fields) < 2 || len(fields) > 4 || fields[0] == "" || fields[1] == "" {
+			return nil, fmt.Errorf(
+				"env %v: invalid signing key entry %q, expected kid|pem_file[|sign_from[|retire_at]]", key, entry,
+			)
+		}
+		if seen[fields[0]] {
+			return nil, fmt.Errorf("env %v: duplicate signing key ID %q", key, fields[0])
+		}
+		seen[fields[0]] = true
+
+		k := SigningKeyConfig{ID: fields[0], PEMFile: fields[1]}
+		var err error
+		if len(fields) > 2 && fields[2] != "" {
+			if k.SignFrom, err = time.Parse(time.RFC3339, fields[2]); err != nil {
+				return nil, fmt.Errorf("env %v: invalid sign_from of key %q: %w", key, k.ID, err)
+			}
+		}
+		if len(fields) > 3 && fields[3] != "" {
+			if k.RetireAt, err = time.Parse(time.RFC3339, fields[3]); err != nil {
+				return nil, fmt.Errorf("env %v: invalid retire_at of key %q: %w", key, k.ID, err)
+			}
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, nil
+}
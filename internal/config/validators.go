@@ -11,3 +11,16 @@ func validatePort(port string) bool {
 	}
 	return true
 }
+
+// validateUsername applies the username rules of signup: alphanumeric and at most maxUsernameLength characters.
+func validateUsername(username string) bool {
+	if username == "" || len(username) > maxUsernameLength {
+		return false
+	}
+	for _, r := range username {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
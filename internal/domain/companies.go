@@ -1,25 +1,33 @@
 package domain
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
+// CompanyType names one of the types in the company type catalog, see CompanyTypeDefinition.
 type CompanyType string
 
-const (
-	CompanyTypeCorporation        CompanyType = "Corporation"
-	CompanyTypeNonProfit          CompanyType = "NonProfit"
-	CompanyTypeCooperative        CompanyType = "Cooperative"
-	CompanyTypeSoleProprietorship CompanyType = "Sole Proprietorship"
-)
-
-type Company struct { //nolint:decorder // consts sitting right after their type definition
+type Company struct {
 	ID            *uuid.UUID
 	Name          *string
 	Description   *string
 	EmployeeCount *int32
-	Registered    *bool
+	Status        *CompanyStatus
+	Registered    *bool // derived from Status, updates carrying it must agree with the current status
 	CompanyType   *CompanyType
 	CreatedBy     *uuid.UUID
 	UpdatedBy     *uuid.UUID
+	CreatedAt     *time.Time
+	UpdatedAt     *time.Time // nil until the first update
+	// Version is incremented on every update. Updates carrying a version only apply to that version.
+	Version *int64
+	// DeletedAt and DeletedBy are set while the company is deleted, until it's restored or purged
+	DeletedAt *time.Time
+	DeletedBy *uuid.UUID
+	// CreatedByUsername and UpdatedByUsername name the actors, they're only resolved by lookups
+	// and listings of the current state
+	CreatedByUsername *string
+	UpdatedByUsername *string
 }
@@ -0,0 +1,49 @@
+package domain
+
+// CompanyImportMode decides what happens to the valid rows of an import when others can't be created.
+type CompanyImportMode string
+
+// CompanyImportStatus is the outcome of a single import row.
+type CompanyImportStatus string
+
+// CompanyImportRow is a row of a bulk import and, once imported, its outcome.
+type CompanyImportRow struct {
+	Row     int      // position of the row in the input, starting at 1
+	Company *Company // the company to create, the created company once imported
+	Status  CompanyImportStatus
+	// Problems explains why an invalid row was rejected, by the field it concerns
+	Problems []CompanyImportProblem
+}
+
+// CompanyImportProblem is a reason a row can't be imported.
+type CompanyImportProblem struct {
+	Field   string
+	Rule    string
+	Message string
+}
+
+// CompanyImportReport is the outcome of a bulk import.
+// Unless Committed is set, rows reported as created were only checked and nothing has been written.
+type CompanyImportReport struct {
+	Rows      []*CompanyImportRow
+	Created   int
+	Skipped   int
+	Invalid   int
+	Committed bool
+}
+
+const (
+	// CompanyImportDryRun only reports what an import would do
+	CompanyImportDryRun CompanyImportMode = "dry_run"
+	// CompanyImportAllOrNothing imports nothing unless every row can be created
+	CompanyImportAllOrNothing CompanyImportMode = "all_or_nothing"
+	// CompanyImportBestEffort creates the rows it can and reports the others
+	CompanyImportBestEffort CompanyImportMode = "best_effort"
+
+	// CompanyImportPending is the status of a row not imported yet
+	CompanyImportPending CompanyImportStatus = ""
+	CompanyImportCreated CompanyImportStatus = "created"
+	// CompanyImportSkipped rows name a company that already exists, or an earlier row of the same import does
+	CompanyImportSkipped CompanyImportStatus = "skipped"
+	CompanyImportInvalid CompanyImportStatus = "invalid"
+)
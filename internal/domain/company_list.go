@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CompanySortField is a field company listings can be ordered by.
+type CompanySortField string
+
+// CompanyFilter narrows down a company listing.
+// Nil fields are not filtered on.
+type CompanyFilter struct {
+	CompanyType      *CompanyType
+	Registered       *bool
+	MinEmployeeCount *int32
+	MaxEmployeeCount *int32
+	CreatedBy        *uuid.UUID
+}
+
+// CompanyCursor marks the last company of a listing page.
+// It holds every sortable value so the next page can resume right after it
+// regardless of which field the listing is ordered by.
+type CompanyCursor struct {
+	ID            uuid.UUID
+	Name          string
+	EmployeeCount int32
+	CreatedAt     time.Time
+}
+
+type CompanyListParams struct {
+	Filter     CompanyFilter
+	SortBy     CompanySortField
+	Descending bool
+	After      *CompanyCursor // nil for the first page
+	Limit      int32
+	AsOf       *time.Time // nil lists the current companies
+}
+
+type CompanyPage struct {
+	Companies []*Company
+	Next      *CompanyCursor // nil when there are no more pages
+}
+
+const (
+	CompanySortByCreatedAt     CompanySortField = "created_at"
+	CompanySortByName          CompanySortField = "name"
+	CompanySortByEmployeeCount CompanySortField = "employee_count"
+)
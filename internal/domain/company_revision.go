@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CompanyOperation is a kind of change recorded in a company's history.
+type CompanyOperation string
+
+// CompanyRevision is the state of a company after one of its changes.
+// Revisions are numbered per company, following the company's version.
+type CompanyRevision struct {
+	Revision  int64
+	Operation CompanyOperation
+	Company   *Company   // for deletions the last state of the company
+	Actor     *uuid.UUID // nil if unknown
+	ChangedAt time.Time
+	Reason    *string // given for transitions
+}
+
+type CompanyRevisionPage struct {
+	Revisions []*CompanyRevision
+	Next      *int64 // the revision the next page starts below, nil when there are no more pages
+}
+
+// CompanyFieldChange is a user editable field that differs between two states of a company.
+// Values are nil where the field is unset.
+type CompanyFieldChange struct {
+	Field string
+	From  any
+	To    any
+}
+
+const (
+	CompanyOperationCreate     CompanyOperation = "create"
+	CompanyOperationUpdate     CompanyOperation = "update"
+	CompanyOperationDelete     CompanyOperation = "delete"
+	CompanyOperationRestore    CompanyOperation = "restore"
+	CompanyOperationTransition CompanyOperation = "transition"
+)
@@ -0,0 +1,11 @@
+package domain
+
+// CompanySearchHit is a company matched by a search query.
+type CompanySearchHit struct {
+	Company *Company
+	Rank    float32
+	// Snippet is an HTML excerpt of the description with the matching terms wrapped in <mark> tags.
+	// The description is escaped, so the only markup in it are those tags.
+	// It is nil when the company has no description.
+	Snippet *string
+}
@@ -0,0 +1,28 @@
+package domain
+
+// CompanyStatus is a stage of a company's lifecycle. It only changes through CompanyActions.
+type CompanyStatus string
+
+// CompanyAction is a lifecycle transition a company can be put through.
+type CompanyAction string
+
+const (
+	CompanyStatusPending       CompanyStatus = "pending"
+	CompanyStatusActive        CompanyStatus = "active"
+	CompanyStatusSuspended     CompanyStatus = "suspended"
+	CompanyStatusInLiquidation CompanyStatus = "in_liquidation"
+	CompanyStatusDissolved     CompanyStatus = "dissolved"
+
+	CompanyActionApprove   CompanyAction = "approve"
+	CompanyActionReject    CompanyAction = "reject"
+	CompanyActionSuspend   CompanyAction = "suspend"
+	CompanyActionReinstate CompanyAction = "reinstate"
+	CompanyActionLiquidate CompanyAction = "liquidate"
+	CompanyActionDissolve  CompanyAction = "dissolve"
+)
+
+// Registered reports whether companies in status s are on the register.
+// Applications that haven't been approved yet and dissolved companies aren't.
+func (s CompanyStatus) Registered() bool {
+	return s == CompanyStatusActive || s == CompanyStatusSuspended || s == CompanyStatusInLiquidation
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// CompanyTypeDefinition is an entry of the company type catalog.
+// Retired types, those no longer Active, stay with the companies that have them but can't be given to others.
+type CompanyTypeDefinition struct {
+	Name        *CompanyType
+	Description *string
+	Active      *bool
+	CreatedAt   *time.Time
+	UpdatedAt   *time.Time // nil until the first update
+}
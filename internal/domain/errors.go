@@ -7,4 +7,13 @@ var (
 	ErrConflict       = errors.New("resource already exists")
 	ErrBadCredentials = errors.New("invalid credentials")
 	ErrBadRequest     = errors.New("bad request")
+	ErrForbidden      = errors.New("forbidden")
+	// ErrPreconditionFailed signals the resource changed since the version a mutation was based on
+	ErrPreconditionFailed = errors.New("precondition failed")
+	// ErrInvalidTransition signals a lifecycle transition that isn't allowed from the current status
+	ErrInvalidTransition = errors.New("invalid transition")
+	// ErrUnknownCompanyType signals a company type that isn't in the catalog or has been retired
+	ErrUnknownCompanyType = errors.New("unknown company type")
+	// ErrIdempotencyKeyReused signals an Idempotency-Key sent again with a different request
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused")
 )
@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// HealthStatus is the state of a dependency, or of the service as a whole.
+type HealthStatus string
+
+// DependencyHealth is the outcome of checking one dependency.
+// Non-critical dependencies being down degrade the service without making it unready.
+type DependencyHealth struct {
+	Status   HealthStatus
+	Critical bool
+	Latency  time.Duration
+	Error    string
+	Details  map[string]any
+}
+
+// HealthReport is the readiness of the service along with the checks it was derived from.
+type HealthReport struct {
+	Status       HealthStatus
+	Dependencies map[string]DependencyHealth
+}
+
+const (
+	HealthStatusUp       HealthStatus = "up"
+	HealthStatusDown     HealthStatus = "down"
+	HealthStatusDisabled HealthStatus = "disabled"
+
+	HealthStatusReady    HealthStatus = "ready"
+	HealthStatusDegraded HealthStatus = "degraded" // ready, but a non-critical dependency is down
+	HealthStatusNotReady HealthStatus = "not_ready"
+	HealthStatusDraining HealthStatus = "draining" // shutting down, no new traffic should be routed here
+)
+
+// Ready reports whether the service should receive traffic.
+func (r *HealthReport) Ready() bool {
+	return r.Status == HealthStatusReady || r.Status == HealthStatusDegraded
+}
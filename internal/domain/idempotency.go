@@ -0,0 +1,23 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdempotencyRecord is what is remembered of a request sent with an Idempotency-Key.
+type IdempotencyRecord struct {
+	UserID      uuid.UUID // uuid.Nil for anonymous requests
+	Key         string
+	RequestHash []byte
+	Response    *RecordedResponse // nil while the request is being handled
+	ExpiresAt   time.Time
+}
+
+// RecordedResponse is a response stored to be replayed to retries of its request.
+type RecordedResponse struct {
+	Status int
+	Header map[string][]string
+	Body   []byte
+}
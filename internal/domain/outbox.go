@@ -0,0 +1,20 @@
+package domain
+
+import "time"
+
+// OutboxMessage is an event waiting in the transactional outbox to be published.
+type OutboxMessage struct {
+	ID       int64
+	Topic    string
+	Key      string
+	Payload  []byte
+	Headers  map[string]string // passed on as record headers, e.g. the trace context of the enqueuing request
+	Attempts int32             // failed publish attempts so far
+}
+
+// OutboxBacklog summarizes the messages still waiting in the outbox.
+type OutboxBacklog struct {
+	Pending int64
+	Lag     time.Duration // age of the oldest pending message
+	Parked  int64         // messages that failed every publish attempt, not counted as pending
+}
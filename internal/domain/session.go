@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefreshToken is a stored refresh token, only its hash is ever persisted.
+// Tokens descending from the same login share a FamilyID, which also identifies the session.
+type RefreshToken struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	FamilyID  uuid.UUID
+	TokenHash []byte
+	ExpiresAt time.Time
+	RotatedAt *time.Time // set once the token has been exchanged for a new one
+	RevokedAt *time.Time
+}
+
+// TokenPair is what a client receives when a session is started or refreshed.
+type TokenPair struct {
+	AccessToken          string
+	AccessTokenExpiresAt time.Time
+	RefreshToken         string
+}
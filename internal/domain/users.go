@@ -4,10 +4,28 @@ import (
 	"github.com/google/uuid"
 )
 
-type User struct {
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"
+	RoleEditor Role = "editor"
+	RoleViewer Role = "viewer"
+)
+
+type User struct { //nolint:decorder // consts sitting right after their type definition
 	// Fields kept as pointers for less friction if implementing
 	// partial updates is decided in the future
 	ID           *uuid.UUID
 	Username     *string
 	PasswordHash *string
+	Role         *Role
+}
+
+// Valid reports whether r is one of the known roles.
+func (r Role) Valid() bool {
+	switch r {
+	case RoleAdmin, RoleEditor, RoleViewer:
+		return true
+	}
+	return false
 }
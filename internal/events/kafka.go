@@ -2,28 +2,77 @@ package events
 
 import (
 	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/metrics"
+	"github.com/Laelapa/CompanyRegistry/internal/tracing"
 
 	"github.com/twmb/franz-go/pkg/kgo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// cloudEventsContentType marks record values as structured mode CloudEvents,
+// as required by the CloudEvents Kafka protocol binding.
+const cloudEventsContentType = "application/cloudevents+json; charset=UTF-8"
+
 type Producer struct {
-	client *kgo.Client
+	client  *kgo.Client
+	metrics *metrics.Metrics
+	tracer  trace.Tracer
 }
 
-func NewProducer(client *kgo.Client) *Producer {
-	return &Producer{client: client}
+func NewProducer(client *kgo.Client, metrics *metrics.Metrics) *Producer {
+	return &Producer{client: client, metrics: metrics, tracer: tracing.Tracer()}
 }
 
-// Produce satisfies the service.EventProducer interface
-func (p *Producer) Produce(ctx context.Context, topic string, key string, value []byte) error {
+// Produce satisfies the service.EventProducer interface.
+// The headers are sent along as record headers, with the trace context replaced by the one of the publish span.
+func (p *Producer) Produce(
+	ctx context.Context,
+	topic string,
+	key string,
+	value []byte,
+	headers map[string]string,
+) (err error) {
 	if p.client == nil {
 		return nil
 	}
+
+	ctx, span := p.tracer.Start(ctx, "send "+topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeSend,
+			semconv.MessagingDestinationName(topic),
+			semconv.MessagingKafkaMessageKey(key),
+		),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	carrier := propagation.MapCarrier{}
+	maps.Copy(carrier, headers)
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
 	record := &kgo.Record{
 		Topic: topic,
 		Key:   []byte(key),
 		Value: value,
+		Headers: []kgo.RecordHeader{
+			{Key: "content-type", Value: []byte(cloudEventsContentType)},
+		},
+	}
+	for _, k := range slices.Sorted(maps.Keys(carrier)) {
+		record.Headers = append(record.Headers, kgo.RecordHeader{Key: k, Value: []byte(carrier[k])})
 	}
+
 	// ProduceSync is safest for low-volume critical events
-	return p.client.ProduceSync(ctx, record).FirstErr()
+	start := time.Now()
+	err = p.client.ProduceSync(ctx, record).FirstErr()
+	p.metrics.ObserveKafkaProduce(topic, err, time.Since(start))
+	return err
 }
@@ -0,0 +1,206 @@
+// Package metrics holds the Prometheus collectors of the service.
+// All methods are safe to call on a nil *Metrics, which records nothing.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics owns a registry and the collectors the service records into.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests        *prometheus.CounterVec
+	httpRequestDuration *prometheus.HistogramVec
+
+	kafkaProduced        *prometheus.CounterVec
+	kafkaProduceDuration *prometheus.HistogramVec
+	outboxParked         *prometheus.CounterVec
+
+	companyMutations *prometheus.CounterVec
+	logins           *prometheus.CounterVec
+}
+
+const (
+	namespace = "company_registry"
+
+	resultSuccess = "success"
+	resultFailure = "failure"
+
+	// RouteUnmatched labels requests that matched no route, keeping arbitrary paths out of the label values.
+	RouteUnmatched = "unmatched"
+)
+
+// New creates the collectors and registers them, along with the Go runtime and process collectors,
+// on a registry of their own.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests handled, by method, route pattern and status code.",
+		}, []string{"method", "route", "status"}),
+		httpRequestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency, by method, route pattern and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		kafkaProduced: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "kafka",
+			Name:      "produced_records_total",
+			Help:      "Records produced to Kafka, by topic and result.",
+		}, []string{"topic", "result"}),
+		kafkaProduceDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "kafka",
+			Name:      "produce_duration_seconds",
+			Help:      "Latency of synchronous Kafka produce calls, by topic.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"topic"}),
+		outboxParked: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "outbox",
+			Name:      "parked_events_total",
+			Help:      "Events parked in the outbox after failing every publish attempt, by topic.",
+		}, []string{"topic"}),
+		companyMutations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "company_mutations_total",
+			Help:      "Committed company mutations, by action (created, updated, deleted, restored, transitioned, purged).",
+		}, []string{"action"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "logins_total",
+			Help:      "Login attempts, by result (success, failure).",
+		}, []string{"result"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpRequestDuration,
+		m.kafkaProduced,
+		m.kafkaProduceDuration,
+		m.outboxParked,
+		m.companyMutations,
+		m.logins,
+	)
+	return m
+}
+
+// Handler serves the registered metrics in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	if m == nil {
+		return http.NotFoundHandler()
+	}
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// RegisterPool exposes the connection statistics of a pgx pool.
+func (m *Metrics) RegisterPool(pool *pgxpool.Pool) error {
+	if m == nil {
+		return nil
+	}
+	return m.registry.Register(newPoolCollector(pool))
+}
+
+// ObserveHTTPRequest records a handled HTTP request.
+// route should be the pattern the request matched, or RouteUnmatched.
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpRequestDuration.WithLabelValues(method, route, code).Observe(elapsed.Seconds())
+}
+
+// ObserveKafkaProduce records the outcome of producing a record to topic.
+func (m *Metrics) ObserveKafkaProduce(topic string, err error, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+	result := resultSuccess
+	if err != nil {
+		result = resultFailure
+	}
+	m.kafkaProduced.WithLabelValues(topic, result).Inc()
+	m.kafkaProduceDuration.WithLabelValues(topic).Observe(elapsed.Seconds())
+}
+
+// OutboxEventParked counts an event of topic parked after failing every publish attempt.
+func (m *Metrics) OutboxEventParked(topic string) {
+	if m == nil {
+		return
+	}
+	m.outboxParked.WithLabelValues(topic).Inc()
+}
+
+// CompanyCreated counts a committed company creation.
+func (m *Metrics) CompanyCreated() {
+	m.companyMutation("created")
+}
+
+// CompanyUpdated counts a committed company update.
+func (m *Metrics) CompanyUpdated() {
+	m.companyMutation("updated")
+}
+
+// CompanyDeleted counts a committed company deletion.
+func (m *Metrics) CompanyDeleted() {
+	m.companyMutation("deleted")
+}
+
+// CompanyRestored counts a committed restore of a deleted company.
+func (m *Metrics) CompanyRestored() {
+	m.companyMutation("restored")
+}
+
+// CompanyTransitioned counts a committed lifecycle transition of a company.
+func (m *Metrics) CompanyTransitioned() {
+	m.companyMutation("transitioned")
+}
+
+// CompaniesPurged counts deleted companies removed for good by a committed purge.
+func (m *Metrics) CompaniesPurged(n int) {
+	if m == nil {
+		return
+	}
+	m.companyMutations.WithLabelValues("purged").Add(float64(n))
+}
+
+// LoginSucceeded counts a login that started a session.
+func (m *Metrics) LoginSucceeded() {
+	if m == nil {
+		return
+	}
+	m.logins.WithLabelValues(resultSuccess).Inc()
+}
+
+// LoginFailed counts a login rejected for bad credentials.
+func (m *Metrics) LoginFailed() {
+	if m == nil {
+		return
+	}
+	m.logins.WithLabelValues(resultFailure).Inc()
+}
+
+func (m *Metrics) companyMutation(action string) {
+	if m == nil {
+		return
+	}
+	m.companyMutations.WithLabelValues(action).Inc()
+}
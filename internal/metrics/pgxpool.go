@@ -0,0 +1,79 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector reads the statistics of a pgx pool at scrape time.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns        *prometheus.Desc
+	idleConns            *prometheus.Desc
+	totalConns           *prometheus.Desc
+	maxConns             *prometheus.Desc
+	acquires             *prometheus.Desc
+	emptyAcquires        *prometheus.Desc
+	canceledAcquires     *prometheus.Desc
+	acquireDuration      *prometheus.Desc
+	emptyAcquireWaitTime *prometheus.Desc
+}
+
+func newPoolCollector(pool *pgxpool.Pool) *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, nil, nil)
+	}
+	return &poolCollector{
+		pool:          pool,
+		acquiredConns: desc("acquired_connections", "Connections currently checked out of the pool."),
+		idleConns:     desc("idle_connections", "Idle connections in the pool."),
+		totalConns:    desc("total_connections", "Connections in the pool, including those being established."),
+		maxConns:      desc("max_connections", "Maximum size of the pool."),
+		acquires:      desc("acquires_total", "Successful connection acquisitions."),
+		emptyAcquires: desc(
+			"empty_acquires_total",
+			"Acquisitions that had to wait for a connection because the pool was empty.",
+		),
+		canceledAcquires: desc("canceled_acquires_total", "Acquisitions canceled by their context."),
+		acquireDuration: desc(
+			"acquire_duration_seconds_total",
+			"Total time spent acquiring connections.",
+		),
+		emptyAcquireWaitTime: desc(
+			"empty_acquire_wait_seconds_total",
+			"Total time acquisitions spent waiting for a connection because the pool was empty.",
+		),
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.totalConns
+	ch <- c.maxConns
+	ch <- c.acquires
+	ch <- c.emptyAcquires
+	ch <- c.canceledAcquires
+	ch <- c.acquireDuration
+	ch <- c.emptyAcquireWaitTime
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stat()
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(s.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(s.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(s.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(s.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(s.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(s.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(
+		c.canceledAcquires, prometheus.CounterValue, float64(s.CanceledAcquireCount()),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.acquireDuration, prometheus.CounterValue, s.AcquireDuration().Seconds(),
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.emptyAcquireWaitTime, prometheus.CounterValue, s.EmptyAcquireWaitTime().Seconds(),
+	)
+}
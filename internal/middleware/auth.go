@@ -1,18 +1,27 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/Laelapa/CompanyRegistry/auth/tokenauthority"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
 	"github.com/Laelapa/CompanyRegistry/logging"
 	"github.com/Laelapa/CompanyRegistry/util/ctxutils"
 	"github.com/Laelapa/CompanyRegistry/util/netutils"
 )
 
+// RevocationChecker reports whether an otherwise valid access token has been revoked.
+type RevocationChecker interface {
+	IsRevoked(ctx context.Context, tokenID, sessionID uuid.UUID) (bool, error)
+}
+
 func AuthenticateWithJWT(
 	tokenAuthority *tokenauthority.TokenAuthority,
+	revocations RevocationChecker,
 	logger *logging.Logger,
 ) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -21,7 +30,7 @@ func AuthenticateWithJWT(
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
 				logger.Warn("Unauthorized request: Missing Authorization header", logger.ReqFields(r)...)
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				writeProblem(w, r, logger, problem.Unauthorized())
 				return
 			}
 
@@ -32,22 +41,52 @@ func AuthenticateWithJWT(
 					"Unauthorized request: Invalid Authorization header",
 					append(logger.ReqFields(r), zap.Error(err))...,
 				)
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				writeProblem(w, r, logger, problem.Unauthorized())
 				return
 			}
 
-			// Validate JWT & extract userID
-			userUUID, err := tokenAuthority.ValidateJWT(tokenString)
+			// Validate JWT & extract claims
+			token, err := tokenAuthority.ValidateJWT(tokenString)
 			if err != nil {
 				logger.Warn(
 					"Unauthorized request: Invalid token",
 					append(logger.ReqFields(r), zap.Error(err))...,
 				)
-				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				writeProblem(w, r, logger, problem.Unauthorized())
+				return
+			}
+			userUUID := token.UserID
+
+			// Reject tokens revoked before their expiry, failing closed if that can't be determined
+			revoked, err := revocations.IsRevoked(r.Context(), token.ID, token.SessionID)
+			if err != nil {
+				logger.Error(
+					"Failed to check token revocation",
+					append(logger.ReqFields(r), zap.Error(err))...,
+				)
+				writeProblem(w, r, logger, problem.Internal())
+				return
+			}
+			if revoked {
+				logger.Warn(
+					"Unauthorized request: Revoked token",
+					append(
+						logger.ReqFields(r),
+						zap.String(logging.FieldUserID, userUUID.String()),
+						zap.String(logging.FieldSessionID, token.SessionID.String()),
+					)...,
+				)
+				writeProblem(w, r, logger, problem.Unauthorized())
 				return
 			}
 
 			ctx := ctxutils.SetUserIDInContext(r.Context(), userUUID)
+			ctx = ctxutils.SetUserRoleInContext(ctx, token.Role)
+			ctx = ctxutils.SetTokenInfoInContext(ctx, ctxutils.TokenInfo{
+				ID:        token.ID,
+				SessionID: token.SessionID,
+				ExpiresAt: token.ExpiresAt,
+			})
 
 			logger.Info(
 				"Request authenticated with JWT",
@@ -61,3 +100,10 @@ func AuthenticateWithJWT(
 		})
 	}
 }
+
+// writeProblem renders p as the response to r.
+func writeProblem(w http.ResponseWriter, r *http.Request, logger *logging.Logger, p *problem.Problem) {
+	if err := problem.Write(w, r, p); err != nil {
+		logger.Error("Failed to write response", append(logger.ReqFields(r), zap.Error(err))...)
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/Laelapa/CompanyRegistry/internal/authz"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
+	"github.com/Laelapa/CompanyRegistry/logging"
+)
+
+// Authorize denies requests whose principal doesn't satisfy the rule with 403 and the rule's reason.
+// It must run after AuthenticateWithJWT, which puts the principal in the request context.
+func Authorize(rule authz.Rule, logger *logging.Logger) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := authz.PrincipalFromContext(r.Context())
+			if !ok {
+				logger.Error("Failed to get principal from context", logger.ReqFields(r)...)
+				writeProblem(w, r, logger, problem.Unauthorized())
+				return
+			}
+
+			if err := rule(principal); err != nil {
+				logger.Warn(
+					"Forbidden request",
+					append(
+						logger.ReqFields(r),
+						zap.String(logging.FieldUserID, principal.UserID.String()),
+						zap.Error(err),
+					)...,
+				)
+				p := problem.New(http.StatusForbidden, problem.CodeForbidden, "Forbidden")
+				var denial *authz.Denial
+				if errors.As(err, &denial) {
+					p.Detail = denial.Reason
+				}
+				writeProblem(w, r, logger, p)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
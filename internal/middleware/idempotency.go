@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
+	"github.com/Laelapa/CompanyRegistry/logging"
+	"github.com/Laelapa/CompanyRegistry/util/ctxutils"
+)
+
+// IdempotencyStore records the responses of requests sent with an Idempotency-Key.
+type IdempotencyStore interface {
+	Begin(ctx context.Context, userID uuid.UUID, key string, requestHash []byte) (*domain.RecordedResponse, error)
+	Complete(ctx context.Context, userID uuid.UUID, key string, resp *domain.RecordedResponse) error
+	Release(ctx context.Context, userID uuid.UUID, key string) error
+}
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed marks a response replayed from an earlier request with the same key
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+	// The body is buffered before the handler runs, and to hash it, so it has to stay small
+	maxIdempotentBodySize = 64 << 10
+)
+
+// Idempotency makes requests sent with an Idempotency-Key header take effect once. Retries with the same key
+// and request get the recorded response of the first attempt, a different request with the key is rejected
+// with 422, and a retry racing the first attempt with 409.
+// Keys are scoped to the authenticated user, so it should run after AuthenticateWithJWT on protected routes.
+// 5xx responses are not recorded, the request may be retried with the same key.
+// Recorded responses are stored as they are, handlers that respond with credentials need IdempotencyReissuing.
+// Bodies of requests with a key are limited to maxIdempotentBodySize, larger ones are rejected with 413.
+func Idempotency(store IdempotencyStore, logger *logging.Logger) func(next http.Handler) http.Handler {
+	return idempotency(store, logger, nil)
+}
+
+// IdempotencyReissuing is Idempotency for handlers whose successful responses carry credentials, such as signup.
+// Only the status of a success is recorded, retries after it are answered by reissue, which gets the same
+// request and has to issue fresh credentials for it. Failures are recorded and replayed like Idempotency does.
+func IdempotencyReissuing(
+	store IdempotencyStore,
+	logger *logging.Logger,
+	reissue http.Handler,
+) func(next http.Handler) http.Handler {
+	return idempotency(store, logger, reissue)
+}
+
+func idempotency(
+	store IdempotencyStore,
+	logger *logging.Logger,
+	reissue http.Handler,
+) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(HeaderIdempotencyKey)
+			if key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				logger.Warn("Idempotency-Key header too long", logger.ReqFields(r)...)
+				writeProblem(w, r, logger, problem.BadRequest("Invalid Idempotency-Key header"))
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentBodySize))
+			if err != nil {
+				logger.Warn("Failed to read request body", append(logger.ReqFields(r), zap.Error(err))...)
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					writeProblem(w, r, logger, problem.New(http.StatusRequestEntityTooLarge, problem.CodePayloadTooLarge,
+						fmt.Sprintf("Requests with an Idempotency-Key cannot exceed %d bytes", maxIdempotentBodySize)))
+					return
+				}
+				writeProblem(w, r, logger, problem.BadRequest("Malformed request body"))
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			// Anonymous requests share the nil user
+			userID, _ := ctxutils.GetUserIDFromContext(r.Context())
+			fields := append(logger.ReqFields(r), zap.String(logging.FieldIdempotencyKey, key))
+
+			recorded, err := store.Begin(r.Context(), userID, key, hashRequest(r, body))
+			if err != nil {
+				logger.Warn("Idempotency-Key not usable", append(fields, zap.Error(err))...)
+				writeProblem(w, r, logger, problem.FromError(err, problem.Messages{
+					domain.ErrConflict: "A request with this Idempotency-Key is still being processed",
+				}))
+				return
+			}
+			if recorded != nil && reissue != nil && recorded.Status < http.StatusBadRequest {
+				logger.Info("Reissuing response to a completed request", fields...)
+				w.Header().Set(HeaderIdempotentReplayed, "true")
+				r.Body = io.NopCloser(bytes.NewReader(body))
+				reissue.ServeHTTP(w, r)
+				return
+			}
+			if recorded != nil {
+				logger.Info("Replaying recorded response", fields...)
+				replayResponse(w, recorded)
+				return
+			}
+
+			// The key is released unless a response gets recorded, also if the handler panics
+			completed := false
+			storeCtx := context.WithoutCancel(r.Context())
+			defer func() {
+				if completed {
+					return
+				}
+				if err := store.Release(storeCtx, userID, key); err != nil {
+					logger.Error("Failed to release Idempotency-Key", append(fields, zap.Error(err))...)
+				}
+			}()
+
+			rec := &bufferingRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			if rec.Status() >= http.StatusInternalServerError {
+				return
+			}
+
+			resp := &domain.RecordedResponse{Status: rec.Status()}
+			if reissue == nil || resp.Status >= http.StatusBadRequest {
+				header := w.Header().Clone()
+				header.Del(HeaderRequestID)
+				resp.Header = header
+				resp.Body = rec.body.Bytes()
+			}
+			err = store.Complete(storeCtx, userID, key, resp)
+			if err != nil {
+				logger.Error("Failed to record response", append(fields, zap.Error(err))...)
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+// hashRequest identifies a request by its method, target and body.
+func hashRequest(r *http.Request, body []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// replayResponse writes a recorded response, keeping the headers already set such as the request ID.
+func replayResponse(w http.ResponseWriter, recorded *domain.RecordedResponse) {
+	for name, values := range recorded.Header {
+		w.Header()[name] = values
+	}
+	w.Header().Set(HeaderIdempotentReplayed, "true")
+	w.WriteHeader(recorded.Status)
+	_, _ = w.Write(recorded.Body)
+}
+
+// bufferingRecorder keeps a copy of the response written through it.
+type bufferingRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *bufferingRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *bufferingRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *bufferingRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Status returns the status code of the response, handlers that write nothing respond 200.
+func (r *bufferingRecorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
@@ -1,14 +1,62 @@
 package middleware
 
 import (
+	"math/rand/v2"
 	"net/http"
+	"time"
 
+	"github.com/Laelapa/CompanyRegistry/internal/config"
 	"github.com/Laelapa/CompanyRegistry/logging"
+	"github.com/Laelapa/CompanyRegistry/util/ctxutils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
-func RequestLogger(next http.Handler, logger *logging.Logger) http.Handler {
+// AccessLog emits a single log line per request once the response is written,
+// with its status, latency, size, matched route and the authenticated user.
+// Responses below 400 are sampled, client and server errors are logged in full unless configured otherwise.
+func AccessLog(next http.Handler, logger *logging.Logger, cfg *config.AccessLogConfig) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		logger.Info("Incoming request", logger.ReqFields(r)...)
-		next.ServeHTTP(w, r)
+		start := time.Now()
+		ctx, summary := ctxutils.SetRequestSummaryInContext(r.Context())
+		rec := recordResponse(w)
+		serveWithContext(ctx, next, rec, r)
+
+		status := rec.Status()
+		if !shouldLogAccess(status, cfg) {
+			return
+		}
+
+		fields := append(logger.ReqFields(r),
+			zap.Int(logging.FieldStatus, status),
+			zap.Duration(logging.FieldLatency, time.Since(start)),
+			zap.Int(logging.FieldBytes, rec.bytes),
+		)
+		if r.Pattern != "" {
+			fields = append(fields, zap.String(logging.FieldRoute, routePath(r.Pattern)))
+		}
+		if summary.UserID != uuid.Nil {
+			fields = append(fields, zap.String(logging.FieldUserID, summary.UserID.String()))
+		}
+
+		switch {
+		case status >= http.StatusInternalServerError:
+			logger.Error("Request completed", fields...)
+		case status >= http.StatusBadRequest:
+			logger.Warn("Request completed", fields...)
+		default:
+			logger.Info("Request completed", fields...)
+		}
 	})
 }
+
+func shouldLogAccess(status int, cfg *config.AccessLogConfig) bool {
+	if status >= http.StatusBadRequest && cfg.AlwaysLogErrors {
+		return true
+	}
+	if cfg.SuccessSampleRate >= 1 {
+		return true
+	}
+	return rand.Float64() < cfg.SuccessSampleRate //nolint:gosec // log sampling needs no cryptographic randomness
+}
@@ -0,0 +1,125 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Laelapa/CompanyRegistry/internal/config"
+	"github.com/Laelapa/CompanyRegistry/internal/middleware"
+	"github.com/Laelapa/CompanyRegistry/logging"
+	"github.com/Laelapa/CompanyRegistry/util/ctxutils"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// serveAccessLogged sends a request through AccessLog wrapping a mux with a single route,
+// whose handler authenticates userID and responds with status and body. It returns the logged lines.
+func serveAccessLogged(
+	t *testing.T,
+	cfg *config.AccessLogConfig,
+	userID uuid.UUID,
+	status int,
+	body string,
+) []observer.LoggedEntry {
+	t.Helper()
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := logging.Wrap(zap.New(core), 1024)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/company/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if userID != uuid.Nil {
+			ctxutils.SetUserIDInContext(r.Context(), userID)
+		}
+		w.WriteHeader(status)
+		if body != "" {
+			_, err := w.Write([]byte(body))
+			assert.NoError(t, err)
+		}
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/company/"+uuid.NewString(), nil)
+	middleware.AccessLog(mux, logger, cfg).ServeHTTP(httptest.NewRecorder(), r)
+	return logs.AllUntimed()
+}
+
+func TestAccessLogFields(t *testing.T) {
+	userID := uuid.New()
+	entries := serveAccessLogged(
+		t, &config.AccessLogConfig{SuccessSampleRate: 1}, userID, http.StatusOK, `{"name":"acme"}`,
+	)
+
+	require.Len(t, entries, 1, "one line per request")
+	entry := entries[0]
+	assert.Equal(t, zapcore.InfoLevel, entry.Level)
+	fields := entry.ContextMap()
+	assert.EqualValues(t, http.StatusOK, fields[logging.FieldStatus])
+	assert.EqualValues(t, len(`{"name":"acme"}`), fields[logging.FieldBytes])
+	assert.Equal(t, "/api/v1/company/{id}", fields[logging.FieldRoute])
+	assert.Equal(t, userID.String(), fields[logging.FieldUserID])
+	assert.Contains(t, fields, logging.FieldLatency)
+}
+
+func TestAccessLogAnonymousRequest(t *testing.T) {
+	entries := serveAccessLogged(t, &config.AccessLogConfig{SuccessSampleRate: 1}, uuid.Nil, http.StatusOK, "")
+
+	require.Len(t, entries, 1)
+	assert.NotContains(t, entries[0].ContextMap(), logging.FieldUserID)
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	tests := []struct {
+		name       string
+		cfg        config.AccessLogConfig
+		status     int
+		wantLogged bool
+		wantLevel  zapcore.Level
+	}{
+		{"success kept at rate 1", config.AccessLogConfig{SuccessSampleRate: 1}, http.StatusOK, true, zapcore.InfoLevel},
+		{"success dropped at rate 0", config.AccessLogConfig{SuccessSampleRate: 0}, http.StatusOK, false, 0},
+		{
+			"client error kept at rate 0 when errors are always logged",
+			config.AccessLogConfig{SuccessSampleRate: 0, AlwaysLogErrors: true},
+			http.StatusNotFound, true, zapcore.WarnLevel,
+		},
+		{
+			"server error kept at rate 0 when errors are always logged",
+			config.AccessLogConfig{SuccessSampleRate: 0, AlwaysLogErrors: true},
+			http.StatusInternalServerError, true, zapcore.ErrorLevel,
+		},
+		{
+			"client error sampled like successes otherwise",
+			config.AccessLogConfig{SuccessSampleRate: 0},
+			http.StatusNotFound, false, 0,
+		},
+		{
+			"server error sampled like successes otherwise",
+			config.AccessLogConfig{SuccessSampleRate: 0},
+			http.StatusInternalServerError, false, 0,
+		},
+		{
+			"success still sampled when errors are always logged",
+			config.AccessLogConfig{SuccessSampleRate: 0, AlwaysLogErrors: true},
+			http.StatusNoContent, false, 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := serveAccessLogged(t, &tt.cfg, uuid.New(), tt.status, "")
+			if !tt.wantLogged {
+				assert.Empty(t, entries)
+				return
+			}
+			require.Len(t, entries, 1)
+			assert.Equal(t, tt.wantLevel, entries[0].Level)
+			assert.EqualValues(t, tt.status, entries[0].ContextMap()[logging.FieldStatus])
+		})
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/metrics"
+)
+
+// InstrumentHTTP records the count and latency of requests by the route pattern they matched.
+// It must wrap the mux, which sets the pattern on the request while routing it.
+func InstrumentHTTP(next http.Handler, m *metrics.Metrics) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := recordResponse(w)
+		next.ServeHTTP(rec, r)
+
+		route := metrics.RouteUnmatched
+		if r.Pattern != "" {
+			route = routePath(r.Pattern)
+		}
+		m.ObserveHTTPRequest(r.Method, route, rec.Status(), time.Since(start))
+	})
+}
+
+// routePath strips the method from a "METHOD /path" route pattern.
+func routePath(pattern string) string {
+	if _, path, found := strings.Cut(pattern, " "); found {
+		return path
+	}
+	return pattern
+}
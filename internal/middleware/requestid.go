@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Laelapa/CompanyRegistry/util/ctxutils"
+
+	"github.com/google/uuid"
+)
+
+const (
+	HeaderRequestID    = "X-Request-ID"
+	maxRequestIDLength = 128
+)
+
+// RequestID tags every request with an ID, so its log lines and events can be correlated.
+// A well-formed X-Request-ID sent by the client or a proxy in front of the service is kept,
+// otherwise a new one is generated. The ID is echoed in the response either way.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(HeaderRequestID)
+		if !validRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(HeaderRequestID, requestID)
+
+		serveWithContext(ctxutils.SetRequestIDInContext(r.Context(), requestID), next, w, r)
+	})
+}
+
+// validRequestID only accepts IDs made of characters that are safe to log and forward as headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
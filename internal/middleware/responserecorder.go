@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+)
+
+// responseRecorder captures the status code and body size written by the wrapped handler.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+// recordResponse wraps w, reusing the recorder of an outer middleware if w already is one.
+func recordResponse(w http.ResponseWriter) *responseRecorder {
+	if rec, ok := w.(*responseRecorder); ok {
+		return rec
+	}
+	return &responseRecorder{ResponseWriter: w}
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Status returns the status code of the response, handlers that write nothing respond 200.
+func (r *responseRecorder) Status() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
+
+// serveWithContext serves a copy of r carrying ctx. The mux sets the matched route pattern on the request
+// it receives, which is handed back to r so the middleware wrapping this one still sees it.
+func serveWithContext(ctx context.Context, next http.Handler, w http.ResponseWriter, r *http.Request) {
+	inner := r.WithContext(ctx)
+	next.ServeHTTP(w, inner)
+	r.Pattern = inner.Pattern
+}
@@ -0,0 +1,20 @@
+package middleware
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+)
+
+// Trace starts a server span for every request, continuing the trace of the caller if it sent one.
+// Spans are named after the route pattern the mux matched, or "HTTP <method>" for unmatched requests.
+func Trace(next http.Handler) http.Handler {
+	return otelhttp.NewHandler(next, "http.server",
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			if r.Pattern != "" {
+				return r.Pattern
+			}
+			return "HTTP " + r.Method
+		}),
+	)
+}
@@ -0,0 +1,27 @@
+-- +goose Up
+-- Full-text search runs on an expression index rather than a stored tsvector column,
+-- that way the companies rows (and the sqlc models selecting them) stay unchanged.
+-- The expression has to match the one used by the SearchCompanies query exactly.
+CREATE INDEX companies_search_idx ON companies USING GIN (
+    (
+        setweight(to_tsvector('simple', name), 'A')
+        || setweight(to_tsvector('english', coalesce(description, '')), 'B')
+    )
+);
+
+-- pg_trgm powers fuzzy name matching. It is not available on every Postgres install,
+-- so failing to enable it must not fail the migration; search falls back to ILIKE instead.
+-- +goose StatementBegin
+DO $$
+BEGIN
+    CREATE EXTENSION IF NOT EXISTS pg_trgm;
+    CREATE INDEX IF NOT EXISTS companies_name_trgm_idx ON companies USING GIN (name gin_trgm_ops);
+EXCEPTION WHEN OTHERS THEN
+    RAISE NOTICE 'pg_trgm is unavailable, company search will fall back to ILIKE: %', SQLERRM;
+END
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP INDEX IF EXISTS companies_name_trgm_idx;
+DROP INDEX IF EXISTS companies_search_idx;
@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE outbox (
+    ID BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    topic VARCHAR(255) NOT NULL,
+    event_key TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Relay polling order
+CREATE INDEX outbox_due_idx ON outbox (next_attempt_at, ID);
+-- Per-key ordering check
+CREATE INDEX outbox_key_idx ON outbox (topic, event_key, ID);
+
+-- +goose Down
+DROP TABLE outbox;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- Only a hash of each refresh token is stored.
+-- Tokens descending from the same login share a family, which is revoked as a whole
+-- on logout or when an already rotated token is presented again.
+CREATE TABLE refresh_tokens (
+    ID UUID DEFAULT gen_random_uuid() PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(ID) ON DELETE CASCADE,
+    family_id UUID NOT NULL,
+    token_hash BYTEA UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    rotated_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX refresh_tokens_family_idx ON refresh_tokens (family_id);
+
+-- Access tokens invalidated before their expiry, kept until they would have expired anyway
+CREATE TABLE revoked_access_tokens (
+    jti UUID PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE revoked_access_tokens;
+DROP TABLE refresh_tokens;
@@ -0,0 +1,14 @@
+-- +goose Up
+-- viewer is the baseline role, editor and admin grant access beyond the user's own companies
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'viewer',
+    ADD CONSTRAINT user_role_check CHECK (
+        role IN (
+            'admin',
+            'editor',
+            'viewer'
+        )
+    );
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN role;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Message headers captured when the event was enqueued, such as the trace context of the request
+ALTER TABLE outbox ADD COLUMN headers JSONB NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE outbox DROP COLUMN headers;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Incremented on every update, clients send it back in If-Match to avoid overwriting each other's changes
+ALTER TABLE companies ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE companies DROP COLUMN version;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Responses recorded for Idempotency-Key headers, so retried requests are answered without running twice.
+-- Keys are scoped to the authenticated user, anonymous requests share the nil UUID.
+-- A row without a response is claimed by a request in progress, its expires_at then marks when the claim
+-- is considered abandoned.
+CREATE TABLE idempotency_keys (
+    user_id UUID NOT NULL,
+    idempotency_key TEXT NOT NULL,
+    request_hash BYTEA NOT NULL,
+    response_status INTEGER,
+    response_headers JSONB,
+    response_body BYTEA,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, idempotency_key)
+);
+
+CREATE INDEX idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);
+
+-- +goose Down
+DROP TABLE idempotency_keys;
@@ -0,0 +1,47 @@
+-- +goose Up
+-- Every state a company has been in, written along with each create, update and delete.
+-- Revisions follow the company's version, its deletion is recorded as the revision after the last version.
+-- They outlive the company, so company_id is deliberately not a foreign key.
+CREATE TABLE company_revisions (
+    company_id UUID NOT NULL,
+    revision BIGINT NOT NULL,
+    operation VARCHAR(10) NOT NULL,
+    name VARCHAR(15) NOT NULL,
+    description VARCHAR(3000),
+    employee_count INT NOT NULL,
+    registered BOOLEAN NOT NULL,
+    company_type VARCHAR(20) NOT NULL,
+    actor UUID REFERENCES users(ID),
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (company_id, revision),
+    CONSTRAINT company_revision_operation_check CHECK (operation IN ('create', 'update', 'delete'))
+);
+
+-- The history of existing companies starts with their latest change
+INSERT INTO company_revisions (
+    company_id,
+    revision,
+    operation,
+    name,
+    description,
+    employee_count,
+    registered,
+    company_type,
+    actor,
+    changed_at
+)
+SELECT
+    ID,
+    version,
+    CASE WHEN version = 1 THEN 'create' ELSE 'update' END,
+    name,
+    description,
+    employee_count,
+    registered,
+    company_type,
+    COALESCE(updated_by, created_by),
+    COALESCE(updated_at, created_at, CURRENT_TIMESTAMP)
+FROM companies;
+
+-- +goose Down
+DROP TABLE company_revisions;
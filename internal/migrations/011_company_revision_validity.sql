@@ -0,0 +1,44 @@
+-- +goose Up
+-- A revision is the state of its company from changed_at until valid_until, which is NULL for the current one.
+-- Deletions are revisions too, a company doesn't exist while its deletion is valid.
+-- Revisions also carry their company's creation details, so past states can be listed like live ones.
+ALTER TABLE company_revisions
+    ADD COLUMN valid_until TIMESTAMP,
+    ADD COLUMN created_at TIMESTAMP,
+    ADD COLUMN created_by UUID REFERENCES users(ID);
+
+UPDATE company_revisions r
+SET valid_until = later.next_changed_at
+FROM (
+    SELECT
+        company_id,
+        revision,
+        LEAD(changed_at) OVER (PARTITION BY company_id ORDER BY revision) AS next_changed_at
+    FROM company_revisions
+) later
+WHERE r.company_id = later.company_id
+    AND r.revision = later.revision;
+
+UPDATE company_revisions r
+SET created_at = c.created_at,
+    created_by = c.created_by
+FROM companies c
+WHERE c.ID = r.company_id;
+
+-- Companies deleted in the meantime are described by their first revision
+UPDATE company_revisions r
+SET created_at = first.changed_at,
+    created_by = first.actor
+FROM company_revisions first
+WHERE r.created_at IS NULL
+    AND first.company_id = r.company_id
+    AND first.operation = 'create';
+
+CREATE INDEX company_revisions_validity_idx ON company_revisions (changed_at, valid_until);
+
+-- +goose Down
+DROP INDEX company_revisions_validity_idx;
+ALTER TABLE company_revisions
+    DROP COLUMN created_by,
+    DROP COLUMN created_at,
+    DROP COLUMN valid_until;
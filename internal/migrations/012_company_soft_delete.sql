@@ -0,0 +1,30 @@
+-- +goose Up
+-- Deleted companies are kept until the purge job removes them after the retention period, so they can be restored.
+-- Only live companies claim their name, a deleted company's name can be taken by a new one.
+ALTER TABLE companies
+    ADD COLUMN deleted_at TIMESTAMP,
+    ADD COLUMN deleted_by UUID REFERENCES users(ID),
+    DROP CONSTRAINT companies_name_key;
+
+CREATE UNIQUE INDEX companies_name_live_idx ON companies (name) WHERE deleted_at IS NULL;
+CREATE INDEX companies_deleted_at_idx ON companies (deleted_at) WHERE deleted_at IS NOT NULL;
+
+ALTER TABLE company_revisions
+    DROP CONSTRAINT company_revision_operation_check,
+    ADD CONSTRAINT company_revision_operation_check CHECK (operation IN ('create', 'update', 'delete', 'restore'));
+
+-- +goose Down
+-- Deleted companies can't be told apart from live ones anymore, they're gone for good
+DELETE FROM companies WHERE deleted_at IS NOT NULL;
+UPDATE company_revisions SET operation = 'update' WHERE operation = 'restore';
+
+ALTER TABLE company_revisions
+    DROP CONSTRAINT company_revision_operation_check,
+    ADD CONSTRAINT company_revision_operation_check CHECK (operation IN ('create', 'update', 'delete'));
+
+DROP INDEX companies_deleted_at_idx;
+DROP INDEX companies_name_live_idx;
+ALTER TABLE companies
+    ADD CONSTRAINT companies_name_key UNIQUE (name),
+    DROP COLUMN deleted_by,
+    DROP COLUMN deleted_at;
@@ -0,0 +1,54 @@
+-- +goose Up
+-- Companies go through a lifecycle, their status only changes through the transitions the service allows.
+-- Registered is derived from the status: applications and dissolved companies aren't on the register.
+-- Transitions are recorded as revisions, along with the reason given for them.
+ALTER TABLE companies
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    ADD CONSTRAINT company_status_check
+        CHECK (status IN ('pending', 'active', 'suspended', 'in_liquidation', 'dissolved'));
+
+UPDATE companies SET status = 'active' WHERE registered;
+
+ALTER TABLE companies
+    ALTER COLUMN status DROP DEFAULT,
+    DROP COLUMN registered;
+
+ALTER TABLE companies
+    ADD COLUMN registered BOOLEAN NOT NULL
+        GENERATED ALWAYS AS (status IN ('active', 'suspended', 'in_liquidation')) STORED;
+
+ALTER TABLE company_revisions
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    ADD COLUMN reason VARCHAR(500),
+    ADD CONSTRAINT company_revision_status_check
+        CHECK (status IN ('pending', 'active', 'suspended', 'in_liquidation', 'dissolved')),
+    DROP CONSTRAINT company_revision_operation_check,
+    ADD CONSTRAINT company_revision_operation_check
+        CHECK (operation IN ('create', 'update', 'delete', 'restore', 'transition'));
+
+UPDATE company_revisions SET status = 'active' WHERE registered;
+
+ALTER TABLE company_revisions
+    ALTER COLUMN status DROP DEFAULT,
+    DROP COLUMN registered;
+
+ALTER TABLE company_revisions
+    ADD COLUMN registered BOOLEAN NOT NULL
+        GENERATED ALWAYS AS (status IN ('active', 'suspended', 'in_liquidation')) STORED;
+
+-- +goose Down
+-- Registered keeps the value derived from the last status, the reasons of transitions are lost
+UPDATE company_revisions SET operation = 'update' WHERE operation = 'transition';
+
+ALTER TABLE company_revisions ALTER COLUMN registered DROP EXPRESSION;
+ALTER TABLE company_revisions
+    DROP CONSTRAINT company_revision_operation_check,
+    ADD CONSTRAINT company_revision_operation_check CHECK (operation IN ('create', 'update', 'delete', 'restore')),
+    DROP CONSTRAINT company_revision_status_check,
+    DROP COLUMN reason,
+    DROP COLUMN status;
+
+ALTER TABLE companies ALTER COLUMN registered DROP EXPRESSION;
+ALTER TABLE companies
+    DROP CONSTRAINT company_status_check,
+    DROP COLUMN status;
@@ -0,0 +1,36 @@
+-- +goose Up
+-- Company types are reference data managed by admins rather than a fixed list.
+-- Retired types stay referenced by the companies that have them but can't be given to any other,
+-- a type can only be removed once no company, live or deleted, has it.
+CREATE TABLE company_types (
+    name VARCHAR(20) PRIMARY KEY,
+    description VARCHAR(300),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP
+);
+
+INSERT INTO company_types (name) VALUES
+    ('Corporation'),
+    ('NonProfit'),
+    ('Cooperative'),
+    ('Sole Proprietorship');
+
+ALTER TABLE companies
+    DROP CONSTRAINT company_type_check,
+    ADD CONSTRAINT company_type_fkey FOREIGN KEY (company_type) REFERENCES company_types(name);
+
+-- +goose Down
+-- Fails if companies have been given types added since
+ALTER TABLE companies
+    DROP CONSTRAINT company_type_fkey,
+    ADD CONSTRAINT company_type_check CHECK (
+        company_type IN (
+            'Corporation',
+            'NonProfit',
+            'Cooperative',
+            'Sole Proprietorship'
+        )
+    );
+
+DROP TABLE company_types;
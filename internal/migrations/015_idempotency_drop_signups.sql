@@ -0,0 +1,7 @@
+-- +goose Up
+-- Signup responses carry the issued tokens and are no longer recorded, drop those recorded before.
+-- Signup was the only anonymous route with Idempotency-Key support, so those are the nil UUID keys.
+DELETE FROM idempotency_keys WHERE user_id = '00000000-0000-0000-0000-000000000000';
+
+-- +goose Down
+-- The dropped responses can't be restored, nothing to undo.
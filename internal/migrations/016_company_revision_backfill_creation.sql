@@ -0,0 +1,50 @@
+-- +goose Up
+-- The history of companies that existed before company_revisions started with their latest change, so they
+-- didn't exist for point-in-time reads before it. Their creation is backfilled as revision 1, valid from
+-- created_at until that first recorded change. The state in between is unknown, the creation revision carries
+-- the first recorded one and says so in its reason.
+INSERT INTO company_revisions (
+    company_id,
+    revision,
+    operation,
+    name,
+    description,
+    employee_count,
+    company_type,
+    status,
+    reason,
+    actor,
+    created_at,
+    created_by,
+    changed_at,
+    valid_until
+)
+SELECT
+    first.company_id,
+    1,
+    'create',
+    first.name,
+    first.description,
+    first.employee_count,
+    first.company_type,
+    first.status,
+    'Backfilled: the state until the next revision is unknown, this is the earliest one recorded',
+    first.created_by,
+    first.created_at,
+    first.created_by,
+    first.created_at,
+    first.changed_at
+FROM (
+    SELECT DISTINCT ON (company_id) *
+    FROM company_revisions
+    ORDER BY company_id, revision
+) first
+WHERE first.revision > 1
+    AND first.created_at IS NOT NULL
+    AND first.created_at < first.changed_at;
+
+-- +goose Down
+DELETE FROM company_revisions
+WHERE revision = 1
+    AND operation = 'create'
+    AND reason = 'Backfilled: the state until the next revision is unknown, this is the earliest one recorded';
@@ -0,0 +1,8 @@
+-- +goose Up
+-- The session pruning job looks up expired refresh tokens and revoked access tokens by their expiry.
+CREATE INDEX refresh_tokens_expires_at_idx ON refresh_tokens (expires_at);
+CREATE INDEX revoked_access_tokens_expires_at_idx ON revoked_access_tokens (expires_at);
+
+-- +goose Down
+DROP INDEX revoked_access_tokens_expires_at_idx;
+DROP INDEX refresh_tokens_expires_at_idx;
@@ -0,0 +1,7 @@
+-- +goose Up
+-- Messages that failed every allowed publish attempt are parked: kept for inspection, but no longer relayed,
+-- and no longer holding back the later messages of their key.
+ALTER TABLE outbox ADD COLUMN parked_at TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE outbox DROP COLUMN parked_at;
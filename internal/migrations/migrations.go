@@ -0,0 +1,32 @@
+// Package migrations embeds the goose migrations of the database schema.
+// They are applied by the migrations container, the service only checks the schema is up to date.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"github.com/pressly/goose/v3"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// LatestVersion returns the version of the newest migration, which is the schema version this build expects.
+func LatestVersion() (int64, error) {
+	names, err := fs.Glob(files, "*.sql")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+	for _, name := range names {
+		version, err := goose.NumericComponent(name)
+		if err != nil {
+			return 0, fmt.Errorf("invalid migration file name %q: %w", name, err)
+		}
+		latest = max(latest, version)
+	}
+	return latest, nil
+}
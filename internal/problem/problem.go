@@ -0,0 +1,148 @@
+// Package problem renders errors as RFC 7807 problem details.
+// Every error response of the API carries a stable machine-readable code next to the human-readable detail,
+// clients should branch on the code.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Laelapa/CompanyRegistry/internal/authz"
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/util/ctxutils"
+)
+
+// Problem is an RFC 7807 problem details object, extended with an error code, the request ID
+// and, for validation failures, the offending fields.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// Messages overrides the default detail of the domain errors a call site expects,
+// e.g. "Company not found" rather than "Resource not found".
+type Messages map[error]string
+
+const (
+	ContentType = "application/problem+json"
+
+	// Problems are identified by their code, the type carries no further semantics
+	defaultType = "about:blank"
+
+	CodeBadRequest           = "bad_request"
+	CodeValidationFailed     = "validation_failed"
+	CodeUnauthorized         = "unauthorized"
+	CodeInvalidCredentials   = "invalid_credentials"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeConflict             = "conflict"
+	CodePreconditionFailed   = "precondition_failed"
+	CodeInvalidTransition    = "invalid_transition"
+	CodeUnknownCompanyType   = "unknown_company_type"
+	CodeIdempotencyKeyReused = "idempotency_key_reused"
+	CodeUnsupportedMediaType = "unsupported_media_type"
+	CodeNotAcceptable        = "not_acceptable"
+	CodePayloadTooLarge      = "payload_too_large"
+	CodeInternal             = "internal_error"
+)
+
+// New creates a problem with the given status, code and detail.
+func New(status int, code, detail string) *Problem {
+	return &Problem{
+		Type:   defaultType,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// BadRequest is the problem of a request that could not be read.
+func BadRequest(detail string) *Problem {
+	return New(http.StatusBadRequest, CodeBadRequest, detail)
+}
+
+// Unauthorized is the problem of a request lacking valid authentication.
+func Unauthorized() *Problem {
+	return New(http.StatusUnauthorized, CodeUnauthorized, "Authentication is required")
+}
+
+// Internal is the problem of an unexpected failure. Its cause is never disclosed to the client.
+func Internal() *Problem {
+	return New(http.StatusInternalServerError, CodeInternal, "Internal server error")
+}
+
+// FromError maps the domain error err wraps to a problem.
+// The detail is taken from msgs, falling back to a generic one. Authorization denials report their reason,
+// and errors that don't wrap a domain error are internal errors.
+func FromError(err error, msgs Messages) *Problem {
+	var denial *authz.Denial
+	if errors.As(err, &denial) {
+		return New(http.StatusForbidden, CodeForbidden, denial.Reason)
+	}
+
+	mappings := []struct {
+		target        error
+		status        int
+		code          string
+		defaultDetail string
+	}{
+		{domain.ErrNotFound, http.StatusNotFound, CodeNotFound, "Resource not found"},
+		{domain.ErrConflict, http.StatusConflict, CodeConflict, "Resource already exists"},
+		{domain.ErrBadRequest, http.StatusBadRequest, CodeBadRequest, "Invalid request"},
+		{domain.ErrBadCredentials, http.StatusUnauthorized, CodeInvalidCredentials, "Invalid credentials"},
+		{domain.ErrForbidden, http.StatusForbidden, CodeForbidden, "Forbidden"},
+		{
+			domain.ErrPreconditionFailed, http.StatusPreconditionFailed, CodePreconditionFailed,
+			"Resource has been modified",
+		},
+		{
+			domain.ErrInvalidTransition, http.StatusConflict, CodeInvalidTransition,
+			"Transition is not allowed from the current status",
+		},
+		{
+			domain.ErrUnknownCompanyType, http.StatusBadRequest, CodeUnknownCompanyType,
+			"Company type is unknown or retired",
+		},
+		{
+			domain.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, CodeIdempotencyKeyReused,
+			"Idempotency-Key has already been used for a different request",
+		},
+	}
+	for _, m := range mappings {
+		if !errors.Is(err, m.target) {
+			continue
+		}
+		detail, ok := msgs[m.target]
+		if !ok {
+			detail = m.defaultDetail
+		}
+		return New(m.status, m.code, detail)
+	}
+	return Internal()
+}
+
+// Write renders p as the response to r, filling in the request path and ID.
+func Write(w http.ResponseWriter, r *http.Request, p *Problem) error {
+	p.Instance = r.URL.Path
+	if requestID, ok := ctxutils.GetRequestIDFromContext(r.Context()); ok {
+		p.RequestID = requestID
+	}
+
+	respMarshalled, err := json.Marshal(p)
+	if err != nil {
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return err
+	}
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(p.Status)
+	_, err = w.Write(respMarshalled)
+	return err
+}
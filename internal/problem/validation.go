@@ -0,0 +1,84 @@
+package problem
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes a field of the request that failed validation.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Validation is the problem of a request whose data failed validation.
+// Fields are reported under the name the validator was configured to use for them,
+// see FieldName.
+func Validation(err error) *Problem {
+	p := New(http.StatusBadRequest, CodeValidationFailed, "Request data failed validation")
+
+	var vErrs validator.ValidationErrors
+	if !errors.As(err, &vErrs) {
+		return p
+	}
+	for _, fe := range vErrs {
+		p.Errors = append(p.Errors, FieldError{
+			Field:   fe.Field(),
+			Rule:    fe.Tag(),
+			Message: fieldMessage(fe),
+		})
+	}
+	return p
+}
+
+// FieldName reports struct fields under their JSON name, or their query parameter name for
+// structs decoded from the query string. It's meant to be registered with validator.RegisterTagNameFunc.
+func FieldName(f reflect.StructField) string {
+	for _, tag := range []string{"json", "query"} {
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return f.Name
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	// Length rules apply to the number of characters of strings
+	unit := ""
+	if fe.Kind() == reflect.String {
+		unit = " characters long"
+	}
+
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", fe.Field())
+	case "required_without":
+		return fmt.Sprintf("%s is required without %s", fe.Field(), strings.ToLower(fe.Param()))
+	case "isdefault":
+		return fmt.Sprintf("%s cannot be set", fe.Field())
+	case "eq":
+		return fmt.Sprintf("%s can only be %s", fe.Field(), fe.Param())
+	case "max", "lte":
+		return fmt.Sprintf("%s must be at most %s%s", fe.Field(), fe.Param(), unit)
+	case "min", "gte":
+		return fmt.Sprintf("%s must be at least %s%s", fe.Field(), fe.Param(), unit)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of %s", fe.Field(), fe.Param())
+	case "alphanum":
+		return fmt.Sprintf("%s must only contain letters and digits", fe.Field())
+	case "base64rawurl":
+		return fmt.Sprintf("%s must be unpadded URL-safe base64", fe.Field())
+	default:
+		return fmt.Sprintf("%s failed the %s rule", fe.Field(), fe.Tag())
+	}
+}
@@ -3,17 +3,80 @@ INSERT INTO companies (
     name,
     description,
     employee_count,
-    registered,
     company_type,
+    status,
     created_by
 ) VALUES (
     $1, $2, $3, $4, $5, $6
 ) RETURNING *;
 
+-- name: CreateCompanies :many
+-- Bulk insert of the rows of an import, one array per column.
+-- Rows whose name is held by a live company are skipped, only the inserted companies are returned.
+INSERT INTO companies (
+    name,
+    description,
+    employee_count,
+    company_type,
+    status,
+    created_by
+)
+SELECT
+    r.name,
+    NULLIF(r.description, ''),
+    r.employee_count,
+    r.company_type,
+    r.status,
+    sqlc.arg('created_by')
+FROM unnest(
+    sqlc.arg('names')::text[],
+    sqlc.arg('descriptions')::text[],
+    sqlc.arg('employee_counts')::int[],
+    sqlc.arg('company_types')::text[],
+    sqlc.arg('statuses')::text[]
+) AS r(name, description, employee_count, company_type, status)
+ON CONFLICT (name) WHERE deleted_at IS NULL DO NOTHING
+RETURNING *;
+
+-- name: GetCompanyByID :one
+-- The users who created and last updated the company are joined in for its audit metadata.
+SELECT
+    sqlc.embed(companies),
+    creator.username AS created_by_username,
+    updater.username AS updated_by_username
+FROM companies
+LEFT JOIN users AS creator ON creator.ID = companies.created_by
+LEFT JOIN users AS updater ON updater.ID = companies.updated_by
+WHERE companies.ID = $1
+    AND companies.deleted_at IS NULL;
+
+-- name: GetCompanyByIDForUpdate :one
+-- Locks the row until the end of the transaction.
+SELECT *
+FROM companies
+WHERE ID = $1
+    AND deleted_at IS NULL
+FOR UPDATE;
+
 -- name: GetCompanyByName :one
+-- The users who created and last updated the company are joined in for its audit metadata.
+SELECT
+    sqlc.embed(companies),
+    creator.username AS created_by_username,
+    updater.username AS updated_by_username
+FROM companies
+LEFT JOIN users AS creator ON creator.ID = companies.created_by
+LEFT JOIN users AS updater ON updater.ID = companies.updated_by
+WHERE companies.name = $1
+    AND companies.deleted_at IS NULL;
+
+-- name: GetDeletedCompanyByIDForUpdate :one
+-- Locks the row until the end of the transaction.
 SELECT *
 FROM companies
-WHERE name = $1;
+WHERE ID = $1
+    AND deleted_at IS NOT NULL
+FOR UPDATE;
 
 -- name: UpdateCompany :one
 UPDATE companies
@@ -21,14 +84,169 @@ SET
     name = COALESCE(sqlc.narg('name'), name),
     description = COALESCE(sqlc.narg('description'), description),
     employee_count = COALESCE(sqlc.narg('employee_count'), employee_count),
-    registered = COALESCE(sqlc.narg('registered'), registered),
     company_type = COALESCE(sqlc.narg('company_type'), company_type),
     updated_at = CURRENT_TIMESTAMP,
-    updated_by = sqlc.arg('updated_by')
+    updated_by = sqlc.arg('updated_by'),
+    version = version + 1
+WHERE ID = sqlc.arg('id')
+    AND deleted_at IS NULL
+RETURNING *;
+
+-- name: UpdateCompanyStatus :one
+-- Statuses only change through the lifecycle transitions the service allows, see UpdateCompany.
+UPDATE companies
+SET
+    status = sqlc.arg('status'),
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = sqlc.arg('updated_by'),
+    version = version + 1
 WHERE ID = sqlc.arg('id')
+    AND deleted_at IS NULL
 RETURNING *;
 
 -- name: DeleteCompany :one
+-- Soft deletion, the row is kept for restoring until PurgeDeletedCompanies removes it.
+UPDATE companies
+SET
+    deleted_at = CURRENT_TIMESTAMP,
+    deleted_by = sqlc.arg('deleted_by'),
+    version = version + 1
+WHERE ID = sqlc.arg('id')
+    AND deleted_at IS NULL
+RETURNING *;
+
+-- name: RestoreCompany :one
+UPDATE companies
+SET
+    deleted_at = NULL,
+    deleted_by = NULL,
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = sqlc.arg('updated_by'),
+    version = version + 1
+WHERE ID = sqlc.arg('id')
+    AND deleted_at IS NOT NULL
+RETURNING *;
+
+-- name: PurgeDeletedCompanies :many
+-- Hard-deletes up to batch_size companies deleted before the cutoff.
+-- Rows locked by a concurrent restore or purge are skipped rather than waited for.
 DELETE FROM companies
-WHERE ID = $1
-RETURNING ID;
+WHERE ID IN (
+    SELECT ID
+    FROM companies
+    WHERE deleted_at < sqlc.arg('deleted_before')::timestamp
+    ORDER BY deleted_at
+    LIMIT sqlc.arg('batch_size')
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ListCompanies :many
+-- Keyset pagination: resumes strictly after the cursor row in the requested order.
+-- ID acts as a tie-breaker so rows sharing a sort value are never skipped or repeated.
+-- The users who created and last updated each company are joined in for its audit metadata.
+SELECT
+    sqlc.embed(companies),
+    creator.username AS created_by_username,
+    updater.username AS updated_by_username
+FROM companies
+LEFT JOIN users AS creator ON creator.ID = companies.created_by
+LEFT JOIN users AS updater ON updater.ID = companies.updated_by
+WHERE
+    companies.deleted_at IS NULL
+    AND (sqlc.narg('company_type')::text IS NULL OR company_type = sqlc.narg('company_type'))
+    AND (sqlc.narg('registered')::boolean IS NULL OR registered = sqlc.narg('registered'))
+    AND (sqlc.narg('min_employee_count')::int IS NULL OR employee_count >= sqlc.narg('min_employee_count'))
+    AND (sqlc.narg('max_employee_count')::int IS NULL OR employee_count <= sqlc.narg('max_employee_count'))
+    AND (sqlc.narg('created_by')::uuid IS NULL OR companies.created_by = sqlc.narg('created_by'))
+    AND (
+        sqlc.narg('cursor_id')::uuid IS NULL
+        OR CASE
+            WHEN sqlc.arg('sort_by')::text = 'name' AND NOT sqlc.arg('descending')::boolean
+                THEN (name, companies.ID) > (sqlc.narg('cursor_name')::text, sqlc.narg('cursor_id'))
+            WHEN sqlc.arg('sort_by') = 'name'
+                THEN (name, companies.ID) < (sqlc.narg('cursor_name'), sqlc.narg('cursor_id'))
+            WHEN sqlc.arg('sort_by') = 'employee_count' AND NOT sqlc.arg('descending')
+                THEN (employee_count, companies.ID) > (sqlc.narg('cursor_employee_count')::int, sqlc.narg('cursor_id'))
+            WHEN sqlc.arg('sort_by') = 'employee_count'
+                THEN (employee_count, companies.ID) < (sqlc.narg('cursor_employee_count'), sqlc.narg('cursor_id'))
+            WHEN NOT sqlc.arg('descending')
+                THEN (companies.created_at, companies.ID) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id'))
+            ELSE (companies.created_at, companies.ID) < (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id'))
+        END
+    )
+ORDER BY
+    CASE WHEN sqlc.arg('sort_by') = 'name' AND NOT sqlc.arg('descending') THEN name END ASC,
+    CASE WHEN sqlc.arg('sort_by') = 'name' AND sqlc.arg('descending') THEN name END DESC,
+    CASE WHEN sqlc.arg('sort_by') = 'employee_count' AND NOT sqlc.arg('descending') THEN employee_count END ASC,
+    CASE WHEN sqlc.arg('sort_by') = 'employee_count' AND sqlc.arg('descending') THEN employee_count END DESC,
+    CASE WHEN sqlc.arg('sort_by') = 'created_at' AND NOT sqlc.arg('descending') THEN companies.created_at END ASC,
+    CASE WHEN sqlc.arg('sort_by') = 'created_at' AND sqlc.arg('descending') THEN companies.created_at END DESC,
+    CASE WHEN NOT sqlc.arg('descending') THEN companies.ID END ASC,
+    CASE WHEN sqlc.arg('descending') THEN companies.ID END DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: DeclareCompanyExportCursor :exec
+-- Opens the company_export cursor over the live companies an export selects, oldest first.
+-- The cursor only lives until the end of the transaction declaring it, see FetchCompanyExport.
+DECLARE company_export NO SCROLL CURSOR FOR
+SELECT *
+FROM companies
+WHERE
+    deleted_at IS NULL
+    AND (sqlc.narg('company_type')::text IS NULL OR company_type = sqlc.narg('company_type'))
+    AND (sqlc.narg('registered')::boolean IS NULL OR registered = sqlc.narg('registered'))
+    AND (sqlc.narg('min_employee_count')::int IS NULL OR employee_count >= sqlc.narg('min_employee_count'))
+    AND (sqlc.narg('max_employee_count')::int IS NULL OR employee_count <= sqlc.narg('max_employee_count'))
+    AND (sqlc.narg('created_by')::uuid IS NULL OR created_by = sqlc.narg('created_by'))
+ORDER BY created_at, ID;
+
+-- name: FetchCompanyExport :many
+-- Reads the next batch of the company_export cursor, an empty or short batch means it's exhausted.
+-- FETCH doesn't accept a parameter for its count.
+FETCH FORWARD 500 FROM company_export;
+
+-- name: SearchCompanies :many
+-- Ranks companies by full-text relevance over name and description plus trigram similarity of the name.
+-- Requires the pg_trgm extension, see SearchCompaniesByPattern for the fallback.
+-- Snippets mark matches with chr(2) and chr(3), stripped from the description beforehand, so the adapter
+-- can escape the description before turning them into markup.
+SELECT
+    sqlc.embed(companies),
+    (
+        ts_rank(
+            setweight(to_tsvector('simple', name), 'A')
+            || setweight(to_tsvector('english', coalesce(description, '')), 'B'),
+            websearch_to_tsquery('english', sqlc.arg('query')::text)
+        )
+        + similarity(name, sqlc.arg('query'))
+    )::real AS rank,
+    ts_headline(
+        'english',
+        translate(coalesce(description, ''), chr(2) || chr(3), ''),
+        websearch_to_tsquery('english', sqlc.arg('query')),
+        'StartSel="' || chr(2) || '", StopSel="' || chr(3) || '", MaxFragments=2, MaxWords=20, MinWords=5'
+    )::text AS snippet
+FROM companies
+WHERE
+    deleted_at IS NULL
+    AND (
+        (
+            setweight(to_tsvector('simple', name), 'A')
+            || setweight(to_tsvector('english', coalesce(description, '')), 'B')
+        ) @@ websearch_to_tsquery('english', sqlc.arg('query'))
+        OR name % sqlc.arg('query')
+        OR name ILIKE sqlc.arg('pattern')::text
+    )
+ORDER BY rank DESC, ID
+LIMIT sqlc.arg('result_limit');
+
+-- name: SearchCompaniesByPattern :many
+-- Plain substring search used when pg_trgm is unavailable.
+-- Name matches rank above description matches.
+SELECT sqlc.embed(companies)
+FROM companies
+WHERE deleted_at IS NULL
+    AND (name ILIKE sqlc.arg('pattern')::text OR description ILIKE sqlc.arg('pattern'))
+ORDER BY (name ILIKE sqlc.arg('pattern')) DESC, name, ID
+LIMIT sqlc.arg('result_limit');
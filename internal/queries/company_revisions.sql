@@ -0,0 +1,139 @@
+-- name: CreateCompanyRevision :exec
+-- The revision is valid from the moment it's written, which ends the validity of the company's previous one.
+-- The clock is read rather than the transaction start: the company is locked while its revision is written,
+-- so the periods follow the order of the revisions even when transactions started in a different order.
+WITH clock AS (
+    SELECT clock_timestamp()::timestamp AS now
+), closed AS (
+    UPDATE company_revisions
+    SET valid_until = (SELECT now FROM clock)
+    WHERE company_id = sqlc.arg('company_id')
+        AND valid_until IS NULL
+)
+INSERT INTO company_revisions (
+    company_id,
+    revision,
+    operation,
+    name,
+    description,
+    employee_count,
+    company_type,
+    status,
+    reason,
+    actor,
+    created_at,
+    created_by,
+    changed_at
+)
+SELECT
+    sqlc.arg('company_id'),
+    sqlc.arg('revision'),
+    sqlc.arg('operation'),
+    sqlc.arg('name'),
+    sqlc.narg('description'),
+    sqlc.arg('employee_count'),
+    sqlc.arg('company_type'),
+    sqlc.arg('status'),
+    sqlc.narg('reason'),
+    sqlc.narg('actor'),
+    sqlc.narg('created_at'),
+    sqlc.narg('created_by'),
+    now
+FROM clock;
+
+-- name: CreateCompanyCreationRevisions :exec
+-- Records the creation of companies inserted in bulk, from their rows.
+-- New companies have no earlier revision whose validity would have to end.
+INSERT INTO company_revisions (
+    company_id,
+    revision,
+    operation,
+    name,
+    description,
+    employee_count,
+    company_type,
+    status,
+    actor,
+    created_at,
+    created_by,
+    changed_at
+)
+SELECT
+    ID,
+    version,
+    'create',
+    name,
+    description,
+    employee_count,
+    company_type,
+    status,
+    created_by,
+    created_at,
+    created_by,
+    clock_timestamp()::timestamp
+FROM companies
+WHERE ID = ANY(sqlc.arg('company_ids')::uuid[]);
+
+-- name: GetCompanyAsOf :one
+-- The revision of a company valid at an instant, unless the company was deleted or not yet created then.
+SELECT *
+FROM company_revisions
+WHERE company_id = sqlc.arg('company_id')
+    AND changed_at <= sqlc.arg('as_of')::timestamp
+    AND (valid_until IS NULL OR valid_until > sqlc.arg('as_of'))
+    AND operation <> 'delete';
+
+-- name: GetCompanyRevision :one
+SELECT *
+FROM company_revisions
+WHERE company_id = $1
+    AND revision = $2;
+
+-- name: ListCompanyRevisions :many
+-- Newest first, resuming below the cursor revision.
+SELECT *
+FROM company_revisions
+WHERE company_id = sqlc.arg('company_id')
+    AND (sqlc.narg('before_revision')::bigint IS NULL OR revision < sqlc.narg('before_revision'))
+ORDER BY revision DESC
+LIMIT sqlc.arg('page_limit');
+
+-- name: ListCompaniesAsOf :many
+-- ListCompanies over the revisions valid at an instant, leaving out companies deleted or not yet created then.
+SELECT *
+FROM company_revisions
+WHERE
+    changed_at <= sqlc.arg('as_of')::timestamp
+    AND (valid_until IS NULL OR valid_until > sqlc.arg('as_of'))
+    AND operation <> 'delete'
+    AND (sqlc.narg('company_type')::text IS NULL OR company_type = sqlc.narg('company_type'))
+    AND (sqlc.narg('registered')::boolean IS NULL OR registered = sqlc.narg('registered'))
+    AND (sqlc.narg('min_employee_count')::int IS NULL OR employee_count >= sqlc.narg('min_employee_count'))
+    AND (sqlc.narg('max_employee_count')::int IS NULL OR employee_count <= sqlc.narg('max_employee_count'))
+    AND (sqlc.narg('created_by')::uuid IS NULL OR created_by = sqlc.narg('created_by'))
+    AND (
+        sqlc.narg('cursor_id')::uuid IS NULL
+        OR CASE
+            WHEN sqlc.arg('sort_by')::text = 'name' AND NOT sqlc.arg('descending')::boolean
+                THEN (name, company_id) > (sqlc.narg('cursor_name')::text, sqlc.narg('cursor_id'))
+            WHEN sqlc.arg('sort_by') = 'name'
+                THEN (name, company_id) < (sqlc.narg('cursor_name'), sqlc.narg('cursor_id'))
+            WHEN sqlc.arg('sort_by') = 'employee_count' AND NOT sqlc.arg('descending')
+                THEN (employee_count, company_id) > (sqlc.narg('cursor_employee_count')::int, sqlc.narg('cursor_id'))
+            WHEN sqlc.arg('sort_by') = 'employee_count'
+                THEN (employee_count, company_id) < (sqlc.narg('cursor_employee_count'), sqlc.narg('cursor_id'))
+            WHEN NOT sqlc.arg('descending')
+                THEN (created_at, company_id) > (sqlc.narg('cursor_created_at')::timestamp, sqlc.narg('cursor_id'))
+            ELSE (created_at, company_id) < (sqlc.narg('cursor_created_at'), sqlc.narg('cursor_id'))
+        END
+    )
+ORDER BY
+    CASE WHEN sqlc.arg('sort_by') = 'name' AND NOT sqlc.arg('descending') THEN name END ASC,
+    CASE WHEN sqlc.arg('sort_by') = 'name' AND sqlc.arg('descending') THEN name END DESC,
+    CASE WHEN sqlc.arg('sort_by') = 'employee_count' AND NOT sqlc.arg('descending') THEN employee_count END ASC,
+    CASE WHEN sqlc.arg('sort_by') = 'employee_count' AND sqlc.arg('descending') THEN employee_count END DESC,
+    CASE WHEN sqlc.arg('sort_by') = 'created_at' AND NOT sqlc.arg('descending') THEN created_at END ASC,
+    CASE WHEN sqlc.arg('sort_by') = 'created_at' AND sqlc.arg('descending') THEN created_at END DESC,
+    CASE WHEN NOT sqlc.arg('descending') THEN company_id END ASC,
+    CASE WHEN sqlc.arg('descending') THEN company_id END DESC
+LIMIT sqlc.arg('page_limit');
@@ -0,0 +1,34 @@
+-- name: CreateCompanyType :one
+INSERT INTO company_types (
+    name,
+    description
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: GetCompanyType :one
+SELECT *
+FROM company_types
+WHERE name = $1;
+
+-- name: ListCompanyTypes :many
+-- Retired types are only listed on request.
+SELECT *
+FROM company_types
+WHERE active OR sqlc.arg('include_inactive')::boolean
+ORDER BY name;
+
+-- name: UpdateCompanyType :one
+UPDATE company_types
+SET
+    description = COALESCE(sqlc.narg('description'), description),
+    active = COALESCE(sqlc.narg('active'), active),
+    updated_at = CURRENT_TIMESTAMP
+WHERE name = sqlc.arg('name')
+RETURNING *;
+
+-- name: DeleteCompanyType :one
+-- Fails with a foreign key violation while any company has the type.
+DELETE FROM company_types
+WHERE name = $1
+RETURNING *;
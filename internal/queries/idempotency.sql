@@ -0,0 +1,42 @@
+-- name: ClaimIdempotencyKey :execrows
+-- Claims a key for a request, taking over an expired record of the same key.
+-- No row is affected if the key is held by a live record.
+INSERT INTO idempotency_keys (
+    user_id,
+    idempotency_key,
+    request_hash,
+    expires_at
+) VALUES (
+    sqlc.arg('user_id'), sqlc.arg('idempotency_key'), sqlc.arg('request_hash'), sqlc.arg('expires_at')
+) ON CONFLICT (user_id, idempotency_key) DO UPDATE
+SET request_hash = EXCLUDED.request_hash,
+    response_status = NULL,
+    response_headers = NULL,
+    response_body = NULL,
+    created_at = CURRENT_TIMESTAMP,
+    expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at < sqlc.arg('now');
+
+-- name: GetIdempotencyKey :one
+SELECT *
+FROM idempotency_keys
+WHERE user_id = $1
+    AND idempotency_key = $2;
+
+-- name: CompleteIdempotencyKey :exec
+UPDATE idempotency_keys
+SET response_status = $3,
+    response_headers = $4,
+    response_body = $5,
+    expires_at = $6
+WHERE user_id = $1
+    AND idempotency_key = $2;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = $1
+    AND idempotency_key = $2;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at < $1;
@@ -0,0 +1,59 @@
+-- name: EnqueueOutboxMessage :exec
+INSERT INTO outbox (
+    topic,
+    event_key,
+    payload,
+    headers
+) VALUES (
+    $1, $2, $3, $4
+);
+
+-- name: ClaimOutboxMessages :many
+-- Locks the oldest due messages for publishing, skipping rows another relay already holds.
+-- A message only becomes claimable once every earlier message with the same key is gone,
+-- which keeps per-key ordering intact across retries. Parked messages are skipped and don't hold back their key.
+SELECT *
+FROM outbox
+WHERE next_attempt_at <= LOCALTIMESTAMP
+    AND parked_at IS NULL
+    AND NOT EXISTS (
+        SELECT 1
+        FROM outbox AS earlier
+        WHERE earlier.topic = outbox.topic
+            AND earlier.event_key = outbox.event_key
+            AND earlier.ID < outbox.ID
+            AND earlier.parked_at IS NULL
+    )
+ORDER BY ID
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: DeleteOutboxMessage :exec
+DELETE FROM outbox
+WHERE ID = $1;
+
+-- name: RescheduleOutboxMessage :exec
+UPDATE outbox
+SET
+    attempts = attempts + 1,
+    last_error = sqlc.arg('last_error'),
+    next_attempt_at = LOCALTIMESTAMP + sqlc.arg('retry_in')::interval
+WHERE ID = sqlc.arg('id');
+
+-- name: ParkOutboxMessage :exec
+-- Records the last failed attempt of a message and takes it out of the relay.
+UPDATE outbox
+SET
+    attempts = attempts + 1,
+    last_error = sqlc.arg('last_error'),
+    parked_at = LOCALTIMESTAMP
+WHERE ID = sqlc.arg('id');
+
+-- name: GetOutboxBacklog :one
+SELECT
+    COUNT(*) FILTER (WHERE parked_at IS NULL) AS pending,
+    COALESCE(
+        EXTRACT(EPOCH FROM LOCALTIMESTAMP - MIN(created_at) FILTER (WHERE parked_at IS NULL)), 0
+    )::float8 AS lag_seconds,
+    COUNT(*) FILTER (WHERE parked_at IS NOT NULL) AS parked
+FROM outbox;
@@ -0,0 +1,69 @@
+-- name: CreateRefreshToken :exec
+INSERT INTO refresh_tokens (
+    user_id,
+    family_id,
+    token_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4
+);
+
+-- name: GetRefreshTokenByHashForUpdate :one
+-- Locks the token so concurrent refreshes with it are serialized.
+SELECT *
+FROM refresh_tokens
+WHERE token_hash = $1
+FOR UPDATE;
+
+-- name: MarkRefreshTokenRotated :exec
+UPDATE refresh_tokens
+SET rotated_at = CURRENT_TIMESTAMP
+WHERE ID = $1;
+
+-- name: RevokeRefreshTokenFamily :exec
+UPDATE refresh_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE family_id = $1
+    AND revoked_at IS NULL;
+
+-- name: RevokeAccessToken :exec
+INSERT INTO revoked_access_tokens (
+    jti,
+    expires_at
+) VALUES (
+    $1, $2
+) ON CONFLICT (jti) DO NOTHING;
+
+-- name: DeleteExpiredRefreshTokenFamilies :execrows
+-- Removes the families whose every refresh token expired before the cutoff, whether rotated, revoked or not.
+-- Access tokens never outlive the refresh token issued with them, so no revocation check needs these anymore.
+-- Only families with an expired token are looked at, the others are never candidates.
+DELETE FROM refresh_tokens
+WHERE family_id IN (
+    SELECT expired.family_id
+    FROM refresh_tokens expired
+    WHERE expired.expires_at < sqlc.arg('expired_before')::timestamp
+        AND NOT EXISTS (
+            SELECT 1
+            FROM refresh_tokens live
+            WHERE live.family_id = expired.family_id
+                AND live.expires_at >= sqlc.arg('expired_before')::timestamp
+        )
+);
+
+-- name: DeleteExpiredRevokedAccessTokens :execrows
+DELETE FROM revoked_access_tokens
+WHERE expires_at < $1;
+
+-- name: IsAccessTokenRevoked :one
+-- An access token is revoked on its own or along with the refresh token family it was issued for.
+SELECT EXISTS (
+    SELECT 1
+    FROM revoked_access_tokens
+    WHERE jti = sqlc.arg('jti')
+) OR EXISTS (
+    SELECT 1
+    FROM refresh_tokens
+    WHERE family_id = sqlc.arg('family_id')
+        AND revoked_at IS NOT NULL
+) AS revoked;
@@ -0,0 +1,6 @@
+-- name: HasExtension :one
+SELECT EXISTS (
+    SELECT 1
+    FROM pg_extension
+    WHERE extname = $1
+);
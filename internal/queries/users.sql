@@ -10,3 +10,14 @@ INSERT INTO users (
 SELECT *
 FROM users
 WHERE username = $1;
+
+-- name: GetUserByID :one
+SELECT *
+FROM users
+WHERE ID = $1;
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2
+WHERE ID = $1
+RETURNING *;
@@ -3,6 +3,9 @@ package adapters
 import (
 	"context"
 	"errors"
+	"math"
+	"sync"
+	"time"
 
 	"github.com/Laelapa/CompanyRegistry/internal/domain"
 	"github.com/Laelapa/CompanyRegistry/internal/repository"
@@ -16,8 +19,16 @@ import (
 
 type PGCompanyRepoAdapter struct {
 	q *repository.Queries
+
+	// Whether pg_trgm is installed is looked up on the first search and remembered afterwards
+	trgmMu        sync.Mutex
+	trgmChecked   bool
+	trgmAvailable bool
 }
 
+// companyExportBatchSize is the number of rows FetchCompanyExport reads at a time.
+const companyExportBatchSize = 500
+
 func NewPGCompanyRepoAdapter(q *repository.Queries) *PGCompanyRepoAdapter {
 	return &PGCompanyRepoAdapter{q: q}
 }
@@ -26,8 +37,8 @@ func (p *PGCompanyRepoAdapter) Create(ctx context.Context, c *domain.Company) (*
 	params := repository.CreateCompanyParams{
 		Name:          *c.Name,
 		EmployeeCount: *c.EmployeeCount,
-		Registered:    *c.Registered,
 		CompanyType:   string(*c.CompanyType),
+		Status:        string(*c.Status),
 	}
 
 	if c.Description != nil {
@@ -37,7 +48,7 @@ func (p *PGCompanyRepoAdapter) Create(ctx context.Context, c *domain.Company) (*
 		params.CreatedBy = typeconvert.GoogleUUIDToPgtypeUUID(*c.CreatedBy)
 	}
 
-	dbCompany, err := p.q.CreateCompany(ctx, params)
+	dbCompany, err := queriesFor(ctx, p.q).CreateCompany(ctx, params)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" { //pg:unique_violation
@@ -49,8 +60,61 @@ func (p *PGCompanyRepoAdapter) Create(ctx context.Context, c *domain.Company) (*
 	return p.toDomainType(&dbCompany), nil
 }
 
-func (p *PGCompanyRepoAdapter) GetByName(ctx context.Context, name string) (*domain.Company, error) {
-	dbCompany, err := p.q.GetCompanyByName(ctx, name)
+// CreateMany inserts companies in a single statement on behalf of createdBy and returns those it created.
+// Companies whose name is already taken are skipped rather than failing the others.
+// An empty description is stored as no description.
+func (p *PGCompanyRepoAdapter) CreateMany(
+	ctx context.Context,
+	companies []*domain.Company,
+	createdBy uuid.UUID,
+) ([]*domain.Company, error) {
+	params := repository.CreateCompaniesParams{
+		CreatedBy:      typeconvert.GoogleUUIDToPgtypeUUID(createdBy),
+		Names:          make([]string, 0, len(companies)),
+		Descriptions:   make([]string, 0, len(companies)),
+		EmployeeCounts: make([]int32, 0, len(companies)),
+		CompanyTypes:   make([]string, 0, len(companies)),
+		Statuses:       make([]string, 0, len(companies)),
+	}
+	for _, c := range companies {
+		description := ""
+		if c.Description != nil {
+			description = *c.Description
+		}
+		params.Names = append(params.Names, *c.Name)
+		params.Descriptions = append(params.Descriptions, description)
+		params.EmployeeCounts = append(params.EmployeeCounts, *c.EmployeeCount)
+		params.CompanyTypes = append(params.CompanyTypes, string(*c.CompanyType))
+		params.Statuses = append(params.Statuses, string(*c.Status))
+	}
+
+	dbCompanies, err := queriesFor(ctx, p.q).CreateCompanies(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]*domain.Company, 0, len(dbCompanies))
+	for i := range dbCompanies {
+		created = append(created, p.toDomainType(&dbCompanies[i]))
+	}
+	return created, nil
+}
+
+func (p *PGCompanyRepoAdapter) GetByID(ctx context.Context, id uuid.UUID) (*domain.Company, error) {
+	row, err := queriesFor(ctx, p.q).GetCompanyByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return p.toDomainTypeWithActors(&row.Company, row.CreatedByUsername, row.UpdatedByUsername), nil
+}
+
+// GetByIDForUpdate retrieves a company by its ID and locks it until the surrounding transaction ends.
+// It returns domain.ErrNotFound if the company does not exist.
+func (p *PGCompanyRepoAdapter) GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*domain.Company, error) {
+	dbCompany, err := queriesFor(ctx, p.q).GetCompanyByIDForUpdate(ctx, id)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrNotFound
@@ -60,7 +124,19 @@ func (p *PGCompanyRepoAdapter) GetByName(ctx context.Context, name string) (*dom
 	return p.toDomainType(&dbCompany), nil
 }
 
+func (p *PGCompanyRepoAdapter) GetByName(ctx context.Context, name string) (*domain.Company, error) {
+	row, err := queriesFor(ctx, p.q).GetCompanyByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return p.toDomainTypeWithActors(&row.Company, row.CreatedByUsername, row.UpdatedByUsername), nil
+}
+
 // Update updates an existing company record in the database with capability for partial update.
+// Every update increments the version, the version of c is not checked. The status isn't updated, see UpdateStatus.
 // It returns domain.ErrNotFound if it tries to update an non-existent entry.
 // It returns domain.ErrConflict if a unique constraint violation occurs.
 func (p *PGCompanyRepoAdapter) Update(ctx context.Context, c *domain.Company) (*domain.Company, error) {
@@ -77,12 +153,11 @@ func (p *PGCompanyRepoAdapter) Update(ctx context.Context, c *domain.Company) (*
 		Name:          typeconvert.PtrStringToPgtypeText(c.Name),
 		Description:   typeconvert.PtrStringToPgtypeText(c.Description),
 		EmployeeCount: typeconvert.PtrInt32ToPgtypeInt4(c.EmployeeCount),
-		Registered:    typeconvert.PtrBoolToPgtypeBool(c.Registered),
 		CompanyType:   ct,
 		UpdatedBy:     typeconvert.GoogleUUIDToPgtypeUUID(*c.UpdatedBy),
 	}
 
-	dbCompany, err := p.q.UpdateCompany(ctx, params)
+	dbCompany, err := queriesFor(ctx, p.q).UpdateCompany(ctx, params)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrNotFound
@@ -97,20 +172,208 @@ func (p *PGCompanyRepoAdapter) Update(ctx context.Context, c *domain.Company) (*
 	return p.toDomainType(&dbCompany), nil
 }
 
-func (p *PGCompanyRepoAdapter) Delete(ctx context.Context, id uuid.UUID) error {
-	// We don't actually need the returned ID variable, we just need to know if it succeeded.
-	_, err := p.q.DeleteCompany(ctx, id)
+// UpdateStatus moves a company to status on behalf of updatedBy, which counts as an update of it.
+// Whether the company may move to that status is up to the caller.
+// It returns domain.ErrNotFound if the company does not exist.
+func (p *PGCompanyRepoAdapter) UpdateStatus(
+	ctx context.Context,
+	id uuid.UUID,
+	status domain.CompanyStatus,
+	updatedBy uuid.UUID,
+) (*domain.Company, error) {
+	dbCompany, err := queriesFor(ctx, p.q).UpdateCompanyStatus(ctx, repository.UpdateCompanyStatusParams{
+		Status:    string(status),
+		UpdatedBy: typeconvert.GoogleUUIDToPgtypeUUID(updatedBy),
+		ID:        id,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return p.toDomainType(&dbCompany), nil
+}
+
+// Delete soft-deletes a company on behalf of deletedBy and returns its last state.
+// Deleting increments the version.
+// It returns domain.ErrNotFound if the company does not exist or is already deleted.
+func (p *PGCompanyRepoAdapter) Delete(ctx context.Context, id, deletedBy uuid.UUID) (*domain.Company, error) {
+	dbCompany, err := queriesFor(ctx, p.q).DeleteCompany(ctx, repository.DeleteCompanyParams{
+		DeletedBy: typeconvert.GoogleUUIDToPgtypeUUID(deletedBy),
+		ID:        id,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return p.toDomainType(&dbCompany), nil
+}
+
+// GetDeletedByIDForUpdate retrieves a deleted company by its ID and locks it until the surrounding transaction ends.
+// It returns domain.ErrNotFound if there's no deleted company with that ID.
+func (p *PGCompanyRepoAdapter) GetDeletedByIDForUpdate(ctx context.Context, id uuid.UUID) (*domain.Company, error) {
+	dbCompany, err := queriesFor(ctx, p.q).GetDeletedCompanyByIDForUpdate(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return p.toDomainType(&dbCompany), nil
+}
+
+// Restore undoes the deletion of a company on behalf of restoredBy, which counts as an update of it.
+// It returns domain.ErrNotFound if there's no deleted company with that ID.
+// It returns domain.ErrConflict if a live company has taken its name in the meantime.
+func (p *PGCompanyRepoAdapter) Restore(ctx context.Context, id, restoredBy uuid.UUID) (*domain.Company, error) {
+	dbCompany, err := queriesFor(ctx, p.q).RestoreCompany(ctx, repository.RestoreCompanyParams{
+		UpdatedBy: typeconvert.GoogleUUIDToPgtypeUUID(restoredBy),
+		ID:        id,
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return domain.ErrNotFound
+			return nil, domain.ErrNotFound
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { //pg:unique_violation
+			return nil, domain.ErrConflict
 		}
+		return nil, err
+	}
+	return p.toDomainType(&dbCompany), nil
+}
+
+// PurgeDeleted permanently removes up to limit companies deleted before the cutoff and returns their last state.
+// Companies locked by a concurrent transaction are left for a later purge.
+func (p *PGCompanyRepoAdapter) PurgeDeleted(
+	ctx context.Context,
+	deletedBefore time.Time,
+	limit int,
+) ([]*domain.Company, error) {
+	dbCompanies, err := queriesFor(ctx, p.q).PurgeDeletedCompanies(ctx, repository.PurgeDeletedCompaniesParams{
+		DeletedBefore: typeconvert.TimeToPgtypeTimestamp(deletedBefore.UTC()),
+		BatchSize:     int32(min(limit, math.MaxInt32)), //nolint:gosec // clamped
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	purged := make([]*domain.Company, 0, len(dbCompanies))
+	for i := range dbCompanies {
+		purged = append(purged, p.toDomainType(&dbCompanies[i]))
+	}
+	return purged, nil
+}
+
+// List retrieves a page of companies matching the filter in the requested order,
+// as they were at params.AsOf if it's set.
+// It fetches one row past the limit to find out whether a next page exists.
+func (p *PGCompanyRepoAdapter) List(
+	ctx context.Context,
+	params domain.CompanyListParams,
+) (*domain.CompanyPage, error) {
+	// Handle nullable CompanyType
+	var ct pgtype.Text
+	if params.Filter.CompanyType == nil {
+		ct = pgtype.Text{Valid: false}
+	} else {
+		ct = pgtype.Text{String: string(*params.Filter.CompanyType), Valid: true}
+	}
+
+	qParams := repository.ListCompaniesParams{
+		CompanyType:      ct,
+		Registered:       typeconvert.PtrBoolToPgtypeBool(params.Filter.Registered),
+		MinEmployeeCount: typeconvert.PtrInt32ToPgtypeInt4(params.Filter.MinEmployeeCount),
+		MaxEmployeeCount: typeconvert.PtrInt32ToPgtypeInt4(params.Filter.MaxEmployeeCount),
+		CreatedBy:        typeconvert.PtrGoogleUUIDToPgtypeUUID(params.Filter.CreatedBy),
+		SortBy:           string(params.SortBy),
+		Descending:       params.Descending,
+		PageLimit:        params.Limit + 1,
+	}
+	if params.After != nil {
+		qParams.CursorID = typeconvert.GoogleUUIDToPgtypeUUID(params.After.ID)
+		qParams.CursorName = pgtype.Text{String: params.After.Name, Valid: true}
+		qParams.CursorEmployeeCount = pgtype.Int4{Int32: params.After.EmployeeCount, Valid: true}
+		qParams.CursorCreatedAt = typeconvert.TimeToPgtypeTimestamp(params.After.CreatedAt)
+	}
+	if params.AsOf != nil {
+		return p.listAsOf(ctx, params, qParams)
+	}
+
+	rows, err := queriesFor(ctx, p.q).ListCompanies(ctx, qParams)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.CompanyPage{}
+	if len(rows) > int(params.Limit) {
+		rows = rows[:params.Limit]
+		last := rows[len(rows)-1].Company
+		page.Next = &domain.CompanyCursor{
+			ID:            last.ID,
+			Name:          last.Name,
+			EmployeeCount: last.EmployeeCount,
+			CreatedAt:     last.CreatedAt.Time,
+		}
+	}
+
+	page.Companies = make([]*domain.Company, 0, len(rows))
+	for i := range rows {
+		page.Companies = append(
+			page.Companies,
+			p.toDomainTypeWithActors(&rows[i].Company, rows[i].CreatedByUsername, rows[i].UpdatedByUsername),
+		)
+	}
+	return page, nil
+}
+
+// Export hands the live companies matching filter to fn one by one, oldest first, reading them
+// from a server-side cursor in batches of companyExportBatchSize.
+// It must run within a transaction, the cursor is closed when the transaction ends.
+func (p *PGCompanyRepoAdapter) Export(
+	ctx context.Context,
+	filter domain.CompanyFilter,
+	fn func(*domain.Company) error,
+) error {
+	var ct pgtype.Text
+	if filter.CompanyType != nil {
+		ct = pgtype.Text{String: string(*filter.CompanyType), Valid: true}
+	}
+
+	q := queriesFor(ctx, p.q)
+	err := q.DeclareCompanyExportCursor(ctx, repository.DeclareCompanyExportCursorParams{
+		CompanyType:      ct,
+		Registered:       typeconvert.PtrBoolToPgtypeBool(filter.Registered),
+		MinEmployeeCount: typeconvert.PtrInt32ToPgtypeInt4(filter.MinEmployeeCount),
+		MaxEmployeeCount: typeconvert.PtrInt32ToPgtypeInt4(filter.MaxEmployeeCount),
+		CreatedBy:        typeconvert.PtrGoogleUUIDToPgtypeUUID(filter.CreatedBy),
+	})
+	if err != nil {
 		return err
 	}
-	return nil
+
+	for {
+		batch, err := q.FetchCompanyExport(ctx)
+		if err != nil {
+			return err
+		}
+		for i := range batch {
+			if err = fn(p.toDomainType(&batch[i])); err != nil {
+				return err
+			}
+		}
+		if len(batch) < companyExportBatchSize {
+			return nil
+		}
+	}
 }
 
 func (p *PGCompanyRepoAdapter) toDomainType(c *repository.Company) *domain.Company {
 	ct := domain.CompanyType(c.CompanyType)
+	st := domain.CompanyStatus(c.Status)
 	cb := typeconvert.PgtypeUUIDToGoogleUUID(c.CreatedBy)
 	ub := typeconvert.PgtypeUUIDToGoogleUUID(c.UpdatedBy)
 
@@ -119,9 +382,26 @@ func (p *PGCompanyRepoAdapter) toDomainType(c *repository.Company) *domain.Compa
 		Name:          &c.Name,
 		Description:   typeconvert.PgtypeTextToPtrString(c.Description),
 		EmployeeCount: &c.EmployeeCount,
+		Status:        &st,
 		Registered:    &c.Registered,
 		CompanyType:   &ct,
 		CreatedBy:     &cb,
 		UpdatedBy:     &ub,
+		CreatedAt:     typeconvert.PgtypeTimestampToPtrTime(c.CreatedAt),
+		UpdatedAt:     typeconvert.PgtypeTimestampToPtrTime(c.UpdatedAt),
+		Version:       &c.Version,
+		DeletedAt:     typeconvert.PgtypeTimestampToPtrTime(c.DeletedAt),
+		DeletedBy:     typeconvert.PgtypeUUIDToPtrGoogleUUID(c.DeletedBy),
 	}
 }
+
+// toDomainTypeWithActors converts a company read along with the usernames of its creator and last updater.
+func (p *PGCompanyRepoAdapter) toDomainTypeWithActors(
+	c *repository.Company,
+	createdBy, updatedBy pgtype.Text,
+) *domain.Company {
+	company := p.toDomainType(c)
+	company.CreatedByUsername = typeconvert.PgtypeTextToPtrString(createdBy)
+	company.UpdatedByUsername = typeconvert.PgtypeTextToPtrString(updatedBy)
+	return company
+}
@@ -0,0 +1,190 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/repository"
+	"github.com/Laelapa/CompanyRegistry/util/typeconvert"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// RecordRevision adds a revision to the history of the company it carries.
+func (p *PGCompanyRepoAdapter) RecordRevision(ctx context.Context, rev *domain.CompanyRevision) error {
+	c := rev.Company
+	return queriesFor(ctx, p.q).CreateCompanyRevision(ctx, repository.CreateCompanyRevisionParams{
+		CompanyID:     *c.ID,
+		Revision:      rev.Revision,
+		Operation:     string(rev.Operation),
+		Name:          *c.Name,
+		Description:   typeconvert.PtrStringToPgtypeText(c.Description),
+		EmployeeCount: *c.EmployeeCount,
+		CompanyType:   string(*c.CompanyType),
+		Status:        string(*c.Status),
+		Reason:        typeconvert.PtrStringToPgtypeText(rev.Reason),
+		Actor:         typeconvert.PtrGoogleUUIDToPgtypeUUID(rev.Actor),
+		CreatedAt:     typeconvert.PtrTimeToPgtypeTimestamp(c.CreatedAt),
+		CreatedBy:     typeconvert.PtrGoogleUUIDToPgtypeUUID(c.CreatedBy),
+	})
+}
+
+// RecordCreations adds the creation of companies inserted in bulk to their history, in their current state.
+func (p *PGCompanyRepoAdapter) RecordCreations(ctx context.Context, companyIDs []uuid.UUID) error {
+	return queriesFor(ctx, p.q).CreateCompanyCreationRevisions(ctx, companyIDs)
+}
+
+// GetAsOf retrieves the state a company was in at the given instant.
+// It returns domain.ErrNotFound if the company did not exist at that instant.
+func (p *PGCompanyRepoAdapter) GetAsOf(ctx context.Context, id uuid.UUID, asOf time.Time) (*domain.Company, error) {
+	dbRevision, err := queriesFor(ctx, p.q).GetCompanyAsOf(ctx, repository.GetCompanyAsOfParams{
+		CompanyID: id,
+		AsOf:      typeconvert.TimeToPgtypeTimestamp(asOf.UTC()),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return toDomainRevision(&dbRevision).Company, nil
+}
+
+// GetRevision retrieves a single revision of a company.
+// It returns domain.ErrNotFound if the company has no such revision.
+func (p *PGCompanyRepoAdapter) GetRevision(
+	ctx context.Context,
+	companyID uuid.UUID,
+	revision int64,
+) (*domain.CompanyRevision, error) {
+	dbRevision, err := queriesFor(ctx, p.q).GetCompanyRevision(ctx, repository.GetCompanyRevisionParams{
+		CompanyID: companyID,
+		Revision:  revision,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return toDomainRevision(&dbRevision), nil
+}
+
+// ListRevisions retrieves a page of a company's revisions, newest first, starting below the before revision.
+// It fetches one row past the limit to find out whether a next page exists.
+func (p *PGCompanyRepoAdapter) ListRevisions(
+	ctx context.Context,
+	companyID uuid.UUID,
+	before *int64,
+	limit int32,
+) (*domain.CompanyRevisionPage, error) {
+	qParams := repository.ListCompanyRevisionsParams{
+		CompanyID: companyID,
+		PageLimit: limit + 1,
+	}
+	if before != nil {
+		qParams.BeforeRevision = pgtype.Int8{Int64: *before, Valid: true}
+	}
+
+	dbRevisions, err := queriesFor(ctx, p.q).ListCompanyRevisions(ctx, qParams)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.CompanyRevisionPage{}
+	if len(dbRevisions) > int(limit) {
+		dbRevisions = dbRevisions[:limit]
+		next := dbRevisions[len(dbRevisions)-1].Revision
+		page.Next = &next
+	}
+
+	page.Revisions = make([]*domain.CompanyRevision, 0, len(dbRevisions))
+	for i := range dbRevisions {
+		page.Revisions = append(page.Revisions, toDomainRevision(&dbRevisions[i]))
+	}
+	return page, nil
+}
+
+// listAsOf is List over the states the companies were in at the given instant.
+func (p *PGCompanyRepoAdapter) listAsOf(
+	ctx context.Context,
+	params domain.CompanyListParams,
+	qParams repository.ListCompaniesParams,
+) (*domain.CompanyPage, error) {
+	dbRevisions, err := queriesFor(ctx, p.q).ListCompaniesAsOf(ctx, repository.ListCompaniesAsOfParams{
+		AsOf:                typeconvert.TimeToPgtypeTimestamp(params.AsOf.UTC()),
+		CompanyType:         qParams.CompanyType,
+		Registered:          qParams.Registered,
+		MinEmployeeCount:    qParams.MinEmployeeCount,
+		MaxEmployeeCount:    qParams.MaxEmployeeCount,
+		CreatedBy:           qParams.CreatedBy,
+		CursorID:            qParams.CursorID,
+		SortBy:              qParams.SortBy,
+		Descending:          qParams.Descending,
+		CursorName:          qParams.CursorName,
+		CursorEmployeeCount: qParams.CursorEmployeeCount,
+		CursorCreatedAt:     qParams.CursorCreatedAt,
+		PageLimit:           qParams.PageLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.CompanyPage{}
+	if len(dbRevisions) > int(params.Limit) {
+		dbRevisions = dbRevisions[:params.Limit]
+		last := dbRevisions[len(dbRevisions)-1]
+		page.Next = &domain.CompanyCursor{
+			ID:            last.CompanyID,
+			Name:          last.Name,
+			EmployeeCount: last.EmployeeCount,
+			CreatedAt:     last.CreatedAt.Time,
+		}
+	}
+
+	page.Companies = make([]*domain.Company, 0, len(dbRevisions))
+	for i := range dbRevisions {
+		page.Companies = append(page.Companies, toDomainRevision(&dbRevisions[i]).Company)
+	}
+	return page, nil
+}
+
+func toDomainRevision(r *repository.CompanyRevision) *domain.CompanyRevision {
+	ct := domain.CompanyType(r.CompanyType)
+	st := domain.CompanyStatus(r.Status)
+	rev := &domain.CompanyRevision{
+		Revision:  r.Revision,
+		Operation: domain.CompanyOperation(r.Operation),
+		Company: &domain.Company{
+			ID:            &r.CompanyID,
+			Name:          &r.Name,
+			Description:   typeconvert.PgtypeTextToPtrString(r.Description),
+			EmployeeCount: &r.EmployeeCount,
+			Status:        &st,
+			Registered:    &r.Registered,
+			CompanyType:   &ct,
+			CreatedAt:     typeconvert.PgtypeTimestampToPtrTime(r.CreatedAt),
+			Version:       &r.Revision,
+		},
+		ChangedAt: r.ChangedAt.Time,
+		Reason:    typeconvert.PgtypeTextToPtrString(r.Reason),
+	}
+	if r.CreatedBy.Valid {
+		cb := typeconvert.PgtypeUUIDToGoogleUUID(r.CreatedBy)
+		rev.Company.CreatedBy = &cb
+	}
+	if r.Actor.Valid {
+		actor := typeconvert.PgtypeUUIDToGoogleUUID(r.Actor)
+		rev.Actor = &actor
+	}
+	// A company's update fields describe its latest update or transition, creation and deletion don't count as one
+	if rev.Operation == domain.CompanyOperationUpdate || rev.Operation == domain.CompanyOperationTransition {
+		rev.Company.UpdatedAt = &rev.ChangedAt
+		rev.Company.UpdatedBy = rev.Actor
+	}
+	return rev
+}
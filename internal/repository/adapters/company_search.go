@@ -0,0 +1,175 @@
+package adapters
+
+import (
+	"context"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/repository"
+)
+
+const (
+	trgmExtension = "pg_trgm"
+	// How much context to keep around a match in fallback snippets, in bytes
+	fallbackSnippetRadius = 80
+	// The markers SearchCompanies puts around matches in snippets, replaced by <mark> tags once escaped
+	snippetStartSel = "\x02"
+	snippetStopSel  = "\x03"
+)
+
+// Search finds companies whose name or description match the query, best matches first.
+// Hits are ranked with full-text search and trigram similarity when pg_trgm is installed,
+// otherwise it falls back to a case-insensitive substring match.
+func (p *PGCompanyRepoAdapter) Search(
+	ctx context.Context,
+	query string,
+	limit int32,
+) ([]*domain.CompanySearchHit, error) {
+	pattern := "%" + escapeLikePattern(query) + "%"
+
+	available, err := p.fuzzySearchAvailable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !available {
+		return p.searchByPattern(ctx, query, pattern, limit)
+	}
+
+	rows, err := queriesFor(ctx, p.q).SearchCompanies(ctx, repository.SearchCompaniesParams{
+		Query:       query,
+		Pattern:     pattern,
+		ResultLimit: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]*domain.CompanySearchHit, 0, len(rows))
+	for i := range rows {
+		hit := &domain.CompanySearchHit{
+			Company: p.toDomainType(&rows[i].Company),
+			Rank:    rows[i].Rank,
+		}
+		if rows[i].Snippet != "" {
+			hit.Snippet = markSnippet(rows[i].Snippet)
+		}
+		hits = append(hits, hit)
+	}
+	return hits, nil
+}
+
+func (p *PGCompanyRepoAdapter) searchByPattern(
+	ctx context.Context,
+	query string,
+	pattern string,
+	limit int32,
+) ([]*domain.CompanySearchHit, error) {
+	rows, err := queriesFor(ctx, p.q).SearchCompaniesByPattern(ctx, repository.SearchCompaniesByPatternParams{
+		Pattern:     pattern,
+		ResultLimit: limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hits := make([]*domain.CompanySearchHit, 0, len(rows))
+	for i := range rows {
+		company := p.toDomainType(&rows[i].Company)
+		hit := &domain.CompanySearchHit{Company: company}
+		if company.Description != nil {
+			hit.Snippet = highlightMatch(*company.Description, query)
+		}
+		hits = append(hits, hit)
+	}
+	return hits, nil
+}
+
+// fuzzySearchAvailable reports whether the pg_trgm extension is installed.
+// Only a successful lookup is remembered, so a transient error gets retried on the next search.
+func (p *PGCompanyRepoAdapter) fuzzySearchAvailable(ctx context.Context) (bool, error) {
+	p.trgmMu.Lock()
+	defer p.trgmMu.Unlock()
+
+	if p.trgmChecked {
+		return p.trgmAvailable, nil
+	}
+
+	available, err := queriesFor(ctx, p.q).HasExtension(ctx, trgmExtension)
+	if err != nil {
+		return false, err
+	}
+	p.trgmChecked = true
+	p.trgmAvailable = available
+	return available, nil
+}
+
+// escapeLikePattern escapes the LIKE wildcards so user input only ever matches literally.
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		`%`, `\%`,
+		`_`, `\_`,
+	).Replace(s)
+}
+
+// markSnippet escapes a ts_headline snippet as HTML and turns the match markers into <mark> tags.
+func markSnippet(snippet string) *string {
+	marked := strings.NewReplacer(
+		snippetStartSel, "<mark>",
+		snippetStopSel, "</mark>",
+	).Replace(html.EscapeString(snippet))
+	return &marked
+}
+
+// highlightMatch mimics ts_headline for the fallback search: it cuts an excerpt around
+// the first case-insensitive occurrence of query and wraps the occurrence in <mark> tags.
+// If the query is not found the start of the text is returned unmarked. The text is escaped as HTML.
+func highlightMatch(text, query string) *string {
+	if text == "" {
+		return nil
+	}
+
+	start, end := -1, -1
+	for i := range text { // i steps over rune boundaries
+		if i+len(query) <= len(text) && strings.EqualFold(text[i:i+len(query)], query) {
+			start, end = i, i+len(query)
+			break
+		}
+	}
+	if start < 0 {
+		excerpt := html.EscapeString(text[:runeBoundaryBefore(text, min(len(text), 2*fallbackSnippetRadius))])
+		if len(excerpt) < len(text) {
+			excerpt += "..."
+		}
+		return &excerpt
+	}
+
+	from := runeBoundaryBefore(text, max(0, start-fallbackSnippetRadius))
+	to := runeBoundaryBefore(text, min(len(text), end+fallbackSnippetRadius))
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("...")
+	}
+	b.WriteString(html.EscapeString(text[from:start]))
+	b.WriteString("<mark>")
+	b.WriteString(html.EscapeString(text[start:end]))
+	b.WriteString("</mark>")
+	b.WriteString(html.EscapeString(text[end:to]))
+	if to < len(text) {
+		b.WriteString("...")
+	}
+
+	excerpt := b.String()
+	return &excerpt
+}
+
+// runeBoundaryBefore moves i back until it no longer splits a multi-byte rune.
+func runeBoundaryBefore(s string, i int) int {
+	for i > 0 && i < len(s) && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return i
+}
@@ -0,0 +1,27 @@
+package adapters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkSnippetEscapesDescription(t *testing.T) {
+	snippet := markSnippet(`<script>alert("x")</script> makes ` + snippetStartSel + "widgets" + snippetStopSel)
+	require.NotNil(t, snippet)
+	assert.Equal(t, `&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; makes <mark>widgets</mark>`, *snippet)
+}
+
+func TestHighlightMatchEscapesDescription(t *testing.T) {
+	snippet := highlightMatch(`<script>alert(1)</script> makes Widgets & <b>gadgets</b>`, "widgets")
+	require.NotNil(t, snippet)
+	assert.Equal(t,
+		`&lt;script&gt;alert(1)&lt;/script&gt; makes <mark>Widgets</mark> &amp; &lt;b&gt;gadgets&lt;/b&gt;`,
+		*snippet,
+	)
+
+	snippet = highlightMatch(`<script>alert(1)</script>`, "widgets")
+	require.NotNil(t, snippet)
+	assert.Equal(t, `&lt;script&gt;alert(1)&lt;/script&gt;`, *snippet)
+}
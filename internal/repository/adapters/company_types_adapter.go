@@ -0,0 +1,122 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/repository"
+	"github.com/Laelapa/CompanyRegistry/util/typeconvert"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type PGCompanyTypeRepoAdapter struct {
+	q *repository.Queries
+}
+
+func NewPGCompanyTypeRepoAdapter(q *repository.Queries) *PGCompanyTypeRepoAdapter {
+	return &PGCompanyTypeRepoAdapter{q: q}
+}
+
+// Create adds a company type to the catalog, active from the start.
+// If the type already exists, it returns domain.ErrConflict.
+func (p *PGCompanyTypeRepoAdapter) Create(
+	ctx context.Context,
+	t *domain.CompanyTypeDefinition,
+) (*domain.CompanyTypeDefinition, error) {
+	dbType, err := queriesFor(ctx, p.q).CreateCompanyType(ctx, repository.CreateCompanyTypeParams{
+		Name:        string(*t.Name),
+		Description: typeconvert.PtrStringToPgtypeText(t.Description),
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" { //pg:unique_violation
+			return nil, domain.ErrConflict
+		}
+		return nil, err
+	}
+	return toDomainCompanyType(&dbType), nil
+}
+
+// Get retrieves a company type by name, retired or not.
+// It returns domain.ErrNotFound if the type does not exist.
+func (p *PGCompanyTypeRepoAdapter) Get(
+	ctx context.Context,
+	name domain.CompanyType,
+) (*domain.CompanyTypeDefinition, error) {
+	dbType, err := queriesFor(ctx, p.q).GetCompanyType(ctx, string(name))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return toDomainCompanyType(&dbType), nil
+}
+
+// List retrieves the company types in the catalog ordered by name, retired ones only if includeInactive is set.
+func (p *PGCompanyTypeRepoAdapter) List(
+	ctx context.Context,
+	includeInactive bool,
+) ([]*domain.CompanyTypeDefinition, error) {
+	dbTypes, err := queriesFor(ctx, p.q).ListCompanyTypes(ctx, includeInactive)
+	if err != nil {
+		return nil, err
+	}
+	types := make([]*domain.CompanyTypeDefinition, 0, len(dbTypes))
+	for i := range dbTypes {
+		types = append(types, toDomainCompanyType(&dbTypes[i]))
+	}
+	return types, nil
+}
+
+// Update changes the description and whether a company type is active, leaving nil fields as they are.
+// It returns domain.ErrNotFound if the type does not exist.
+func (p *PGCompanyTypeRepoAdapter) Update(
+	ctx context.Context,
+	t *domain.CompanyTypeDefinition,
+) (*domain.CompanyTypeDefinition, error) {
+	dbType, err := queriesFor(ctx, p.q).UpdateCompanyType(ctx, repository.UpdateCompanyTypeParams{
+		Description: typeconvert.PtrStringToPgtypeText(t.Description),
+		Active:      typeconvert.PtrBoolToPgtypeBool(t.Active),
+		Name:        string(*t.Name),
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return toDomainCompanyType(&dbType), nil
+}
+
+// Delete removes a company type from the catalog.
+// It returns domain.ErrNotFound if the type does not exist
+// and domain.ErrConflict if a company, even a deleted one, still has it.
+func (p *PGCompanyTypeRepoAdapter) Delete(ctx context.Context, name domain.CompanyType) error {
+	_, err := queriesFor(ctx, p.q).DeleteCompanyType(ctx, string(name))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrNotFound
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { //pg:foreign_key_violation
+			return domain.ErrConflict
+		}
+		return err
+	}
+	return nil
+}
+
+func toDomainCompanyType(t *repository.CompanyType) *domain.CompanyTypeDefinition {
+	name := domain.CompanyType(t.Name)
+	return &domain.CompanyTypeDefinition{
+		Name:        &name,
+		Description: typeconvert.PgtypeTextToPtrString(t.Description),
+		Active:      &t.Active,
+		CreatedAt:   typeconvert.PgtypeTimestampToPtrTime(t.CreatedAt),
+		UpdatedAt:   typeconvert.PgtypeTimestampToPtrTime(t.UpdatedAt),
+	}
+}
@@ -0,0 +1,35 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// getSchemaVersion reads the version of the last applied migration.
+// goose_db_version is created by the migration tool rather than by a migration,
+// so sqlc doesn't know the table and the query lives here.
+const getSchemaVersion = `
+SELECT COALESCE(MAX(version_id), 0)
+FROM goose_db_version
+WHERE is_applied
+`
+
+type PGHealthAdapter struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGHealthAdapter(pool *pgxpool.Pool) *PGHealthAdapter {
+	return &PGHealthAdapter{pool: pool}
+}
+
+// Ping checks a connection can be acquired and the database responds.
+func (p *PGHealthAdapter) Ping(ctx context.Context) error {
+	return p.pool.Ping(ctx)
+}
+
+func (p *PGHealthAdapter) SchemaVersion(ctx context.Context) (int64, error) {
+	var version int64
+	err := p.pool.QueryRow(ctx, getSchemaVersion).Scan(&version)
+	return version, err
+}
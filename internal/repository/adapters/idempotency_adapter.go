@@ -0,0 +1,125 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/repository"
+	"github.com/Laelapa/CompanyRegistry/util/typeconvert"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type PGIdempotencyRepoAdapter struct {
+	q *repository.Queries
+}
+
+func NewPGIdempotencyRepoAdapter(q *repository.Queries) *PGIdempotencyRepoAdapter {
+	return &PGIdempotencyRepoAdapter{q: q}
+}
+
+// Claim records a request under its key, unless the key is held by a record that hasn't expired by now.
+// It reports whether the key was claimed.
+func (p *PGIdempotencyRepoAdapter) Claim(
+	ctx context.Context,
+	rec *domain.IdempotencyRecord,
+	now time.Time,
+) (bool, error) {
+	claimed, err := queriesFor(ctx, p.q).ClaimIdempotencyKey(ctx, repository.ClaimIdempotencyKeyParams{
+		UserID:         rec.UserID,
+		IdempotencyKey: rec.Key,
+		RequestHash:    rec.RequestHash,
+		ExpiresAt:      typeconvert.TimeToPgtypeTimestamp(rec.ExpiresAt.UTC()),
+		Now:            typeconvert.TimeToPgtypeTimestamp(now.UTC()),
+	})
+	if err != nil {
+		return false, err
+	}
+	return claimed > 0, nil
+}
+
+// Get retrieves the record of a key.
+// It returns domain.ErrNotFound if the key is not in use.
+func (p *PGIdempotencyRepoAdapter) Get(
+	ctx context.Context,
+	userID uuid.UUID,
+	key string,
+) (*domain.IdempotencyRecord, error) {
+	dbRecord, err := queriesFor(ctx, p.q).GetIdempotencyKey(ctx, repository.GetIdempotencyKeyParams{
+		UserID:         userID,
+		IdempotencyKey: key,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	rec := &domain.IdempotencyRecord{
+		UserID:      dbRecord.UserID,
+		Key:         dbRecord.IdempotencyKey,
+		RequestHash: dbRecord.RequestHash,
+		ExpiresAt:   dbRecord.ExpiresAt.Time,
+	}
+	if dbRecord.ResponseStatus.Valid {
+		var header map[string][]string
+		if err = json.Unmarshal(dbRecord.ResponseHeaders, &header); err != nil {
+			return nil, fmt.Errorf("invalid response headers of idempotency key %q: %w", key, err)
+		}
+		rec.Response = &domain.RecordedResponse{
+			Status: int(dbRecord.ResponseStatus.Int32),
+			Header: header,
+			Body:   dbRecord.ResponseBody,
+		}
+	}
+	return rec, nil
+}
+
+// Complete stores the response to the request holding a key, which is kept until expiresAt.
+func (p *PGIdempotencyRepoAdapter) Complete(
+	ctx context.Context,
+	userID uuid.UUID,
+	key string,
+	resp *domain.RecordedResponse,
+	expiresAt time.Time,
+) error {
+	header, err := json.Marshal(resp.Header)
+	if err != nil {
+		return err
+	}
+	return queriesFor(ctx, p.q).CompleteIdempotencyKey(ctx, repository.CompleteIdempotencyKeyParams{
+		UserID:         userID,
+		IdempotencyKey: key,
+		ResponseStatus: pgtype.Int4{
+			Int32: int32(min(resp.Status, math.MaxInt32)), //nolint:gosec // clamped
+			Valid: true,
+		},
+		ResponseHeaders: header,
+		ResponseBody:    resp.Body,
+		ExpiresAt:       typeconvert.TimeToPgtypeTimestamp(expiresAt.UTC()),
+	})
+}
+
+// DeleteExpired removes the records that expired before now and returns how many it removed.
+func (p *PGIdempotencyRepoAdapter) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	deleted, err := queriesFor(ctx, p.q).DeleteExpiredIdempotencyKeys(
+		ctx, typeconvert.TimeToPgtypeTimestamp(now.UTC()),
+	)
+	return int(deleted), err
+}
+
+// Release frees a key, so the request can be sent again with it.
+func (p *PGIdempotencyRepoAdapter) Release(ctx context.Context, userID uuid.UUID, key string) error {
+	return queriesFor(ctx, p.q).DeleteIdempotencyKey(ctx, repository.DeleteIdempotencyKeyParams{
+		UserID:         userID,
+		IdempotencyKey: key,
+	})
+}
@@ -0,0 +1,98 @@
+package adapters
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type PGOutboxRepoAdapter struct {
+	q *repository.Queries
+}
+
+func NewPGOutboxRepoAdapter(q *repository.Queries) *PGOutboxRepoAdapter {
+	return &PGOutboxRepoAdapter{q: q}
+}
+
+// Enqueue stores a message in the outbox.
+// Called within a transaction, the message only becomes visible to the relay once it commits.
+func (p *PGOutboxRepoAdapter) Enqueue(ctx context.Context, m *domain.OutboxMessage) error {
+	headers, err := json.Marshal(m.Headers)
+	if err != nil {
+		return err
+	}
+	return queriesFor(ctx, p.q).EnqueueOutboxMessage(ctx, repository.EnqueueOutboxMessageParams{
+		Topic:    m.Topic,
+		EventKey: m.Key,
+		Payload:  m.Payload,
+		Headers:  headers,
+	})
+}
+
+// Claim locks up to limit due messages, oldest first.
+// The locks are held until the surrounding transaction ends, so it should be called within one.
+func (p *PGOutboxRepoAdapter) Claim(ctx context.Context, limit int) ([]*domain.OutboxMessage, error) {
+	dbMessages, err := queriesFor(ctx, p.q).ClaimOutboxMessages(ctx, int32(min(limit, math.MaxInt32))) //nolint:gosec // clamped
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*domain.OutboxMessage, 0, len(dbMessages))
+	for i := range dbMessages {
+		var headers map[string]string
+		if err = json.Unmarshal(dbMessages[i].Headers, &headers); err != nil {
+			return nil, fmt.Errorf("invalid headers of outbox message %d: %w", dbMessages[i].ID, err)
+		}
+		messages = append(messages, &domain.OutboxMessage{
+			ID:       dbMessages[i].ID,
+			Topic:    dbMessages[i].Topic,
+			Key:      dbMessages[i].EventKey,
+			Payload:  dbMessages[i].Payload,
+			Headers:  headers,
+			Attempts: dbMessages[i].Attempts,
+		})
+	}
+	return messages, nil
+}
+
+// MarkPublished removes a published message from the outbox.
+func (p *PGOutboxRepoAdapter) MarkPublished(ctx context.Context, id int64) error {
+	return queriesFor(ctx, p.q).DeleteOutboxMessage(ctx, id)
+}
+
+// MarkFailed records a failed publish attempt and holds the message back for retryIn.
+func (p *PGOutboxRepoAdapter) MarkFailed(ctx context.Context, id int64, cause error, retryIn time.Duration) error {
+	return queriesFor(ctx, p.q).RescheduleOutboxMessage(ctx, repository.RescheduleOutboxMessageParams{
+		ID:        id,
+		LastError: pgtype.Text{String: cause.Error(), Valid: true},
+		RetryIn:   pgtype.Interval{Microseconds: retryIn.Microseconds(), Valid: true},
+	})
+}
+
+// Park records the last failed publish attempt of a message and stops relaying it.
+// Parked messages stay in the outbox for inspection and no longer hold back the later messages of their key.
+func (p *PGOutboxRepoAdapter) Park(ctx context.Context, id int64, cause error) error {
+	return queriesFor(ctx, p.q).ParkOutboxMessage(ctx, repository.ParkOutboxMessageParams{
+		ID:        id,
+		LastError: pgtype.Text{String: cause.Error(), Valid: true},
+	})
+}
+
+func (p *PGOutboxRepoAdapter) Backlog(ctx context.Context) (*domain.OutboxBacklog, error) {
+	backlog, err := queriesFor(ctx, p.q).GetOutboxBacklog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.OutboxBacklog{
+		Pending: backlog.Pending,
+		Lag:     time.Duration(backlog.LagSeconds * float64(time.Second)),
+		Parked:  backlog.Parked,
+	}, nil
+}
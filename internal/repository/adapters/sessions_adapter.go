@@ -0,0 +1,99 @@
+package adapters
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/repository"
+	"github.com/Laelapa/CompanyRegistry/util/typeconvert"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type PGSessionRepoAdapter struct {
+	q *repository.Queries
+}
+
+func NewPGSessionRepoAdapter(q *repository.Queries) *PGSessionRepoAdapter {
+	return &PGSessionRepoAdapter{q: q}
+}
+
+// CreateRefreshToken stores a new refresh token.
+func (p *PGSessionRepoAdapter) CreateRefreshToken(ctx context.Context, t *domain.RefreshToken) error {
+	return queriesFor(ctx, p.q).CreateRefreshToken(ctx, repository.CreateRefreshTokenParams{
+		UserID:    t.UserID,
+		FamilyID:  t.FamilyID,
+		TokenHash: t.TokenHash,
+		ExpiresAt: typeconvert.TimeToPgtypeTimestamp(t.ExpiresAt.UTC()),
+	})
+}
+
+// GetRefreshTokenForUpdate retrieves a refresh token by its hash and locks it until the surrounding transaction ends.
+// It returns domain.ErrNotFound if no such token exists.
+func (p *PGSessionRepoAdapter) GetRefreshTokenForUpdate(
+	ctx context.Context,
+	tokenHash []byte,
+) (*domain.RefreshToken, error) {
+	dbToken, err := queriesFor(ctx, p.q).GetRefreshTokenByHashForUpdate(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &domain.RefreshToken{
+		ID:        dbToken.ID,
+		UserID:    dbToken.UserID,
+		FamilyID:  dbToken.FamilyID,
+		TokenHash: dbToken.TokenHash,
+		ExpiresAt: dbToken.ExpiresAt.Time,
+		RotatedAt: typeconvert.PgtypeTimestampToPtrTime(dbToken.RotatedAt),
+		RevokedAt: typeconvert.PgtypeTimestampToPtrTime(dbToken.RevokedAt),
+	}, nil
+}
+
+// MarkRefreshTokenRotated records that a refresh token has been exchanged,
+// presenting it again afterwards counts as reuse.
+func (p *PGSessionRepoAdapter) MarkRefreshTokenRotated(ctx context.Context, id uuid.UUID) error {
+	return queriesFor(ctx, p.q).MarkRefreshTokenRotated(ctx, id)
+}
+
+// RevokeFamily revokes every refresh token of a family,
+// and with them the access tokens issued for the family.
+func (p *PGSessionRepoAdapter) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	return queriesFor(ctx, p.q).RevokeRefreshTokenFamily(ctx, familyID)
+}
+
+// RevokeAccessToken invalidates a single access token until its expiry.
+func (p *PGSessionRepoAdapter) RevokeAccessToken(ctx context.Context, jti uuid.UUID, expiresAt time.Time) error {
+	return queriesFor(ctx, p.q).RevokeAccessToken(ctx, repository.RevokeAccessTokenParams{
+		Jti:       jti,
+		ExpiresAt: typeconvert.TimeToPgtypeTimestamp(expiresAt.UTC()),
+	})
+}
+
+// DeleteExpired removes the revoked access tokens that expired before now,
+// and the refresh token families whose every token did. It returns how many rows it removed.
+func (p *PGSessionRepoAdapter) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	q := queriesFor(ctx, p.q)
+	expiredBefore := typeconvert.TimeToPgtypeTimestamp(now.UTC())
+	revocations, err := q.DeleteExpiredRevokedAccessTokens(ctx, expiredBefore)
+	if err != nil {
+		return 0, err
+	}
+	refreshTokens, err := q.DeleteExpiredRefreshTokenFamilies(ctx, expiredBefore)
+	return int(revocations + refreshTokens), err
+}
+
+// IsAccessTokenRevoked reports whether an access token has been revoked,
+// either on its own or along with its session's refresh token family.
+func (p *PGSessionRepoAdapter) IsAccessTokenRevoked(ctx context.Context, jti, familyID uuid.UUID) (bool, error) {
+	return queriesFor(ctx, p.q).IsAccessTokenRevoked(ctx, repository.IsAccessTokenRevokedParams{
+		Jti:      jti,
+		FamilyID: familyID,
+	})
+}
@@ -0,0 +1,42 @@
+package adapters
+
+import (
+	"context"
+
+	"github.com/Laelapa/CompanyRegistry/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Custom type for the context key to avoid collisions when using context.WithValue
+type txCtxKey struct{}
+
+type PGTransactor struct {
+	pool *pgxpool.Pool
+}
+
+func NewPGTransactor(pool *pgxpool.Pool) *PGTransactor {
+	return &PGTransactor{pool: pool}
+}
+
+// WithinTx runs fn inside a database transaction, committing if fn returns nil and rolling back otherwise.
+// Every adapter called with the context handed to fn takes part in the transaction.
+// Nested calls join the transaction that is already running.
+func (t *PGTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txCtxKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	return pgx.BeginFunc(ctx, t.pool, func(tx pgx.Tx) error {
+		return fn(context.WithValue(ctx, txCtxKey{}, tx))
+	})
+}
+
+// queriesFor binds q to the transaction carried by ctx, if there is one.
+func queriesFor(ctx context.Context, q *repository.Queries) *repository.Queries {
+	if tx, ok := ctx.Value(txCtxKey{}).(pgx.Tx); ok {
+		return q.WithTx(tx)
+	}
+	return q
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/Laelapa/CompanyRegistry/internal/domain"
 	"github.com/Laelapa/CompanyRegistry/internal/repository"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 )
@@ -27,7 +28,7 @@ func (p *PGUserRepoAdapter) Create(ctx context.Context, u *domain.User) (*domain
 		Username:     *u.Username,
 		PasswordHash: *u.PasswordHash,
 	}
-	dbUser, err := p.q.CreateUser(ctx, params)
+	dbUser, err := queriesFor(ctx, p.q).CreateUser(ctx, params)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" { // pg:unique_violation
@@ -39,8 +40,37 @@ func (p *PGUserRepoAdapter) Create(ctx context.Context, u *domain.User) (*domain
 	return p.toDomainType(&dbUser), nil
 }
 
+// GetByID retrieves a user by ID.
+// It returns domain.ErrNotFound if the user does not exist.
+func (p *PGUserRepoAdapter) GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	dbUser, err := queriesFor(ctx, p.q).GetUserByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return p.toDomainType(&dbUser), nil
+}
+
 func (p *PGUserRepoAdapter) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
-	dbUser, err := p.q.GetUserByUsername(ctx, username)
+	dbUser, err := queriesFor(ctx, p.q).GetUserByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return p.toDomainType(&dbUser), nil
+}
+
+// UpdateRole assigns a role to a user.
+// It returns domain.ErrNotFound if the user does not exist.
+func (p *PGUserRepoAdapter) UpdateRole(ctx context.Context, id uuid.UUID, role domain.Role) (*domain.User, error) {
+	dbUser, err := queriesFor(ctx, p.q).UpdateUserRole(ctx, repository.UpdateUserRoleParams{
+		ID:   id,
+		Role: string(role),
+	})
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrNotFound
@@ -52,9 +82,12 @@ func (p *PGUserRepoAdapter) GetByUsername(ctx context.Context, username string)
 
 // toDomain converts from DB model to domain model
 func (p *PGUserRepoAdapter) toDomainType(u *repository.User) *domain.User {
+	role := domain.Role(u.Role)
+
 	return &domain.User{
 		ID:           &u.ID,
 		Username:     &u.Username,
 		PasswordHash: &u.PasswordHash,
+		Role:         &role,
 	}
 }
@@ -12,25 +12,105 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const createCompanies = `-- name: CreateCompanies :many
+INSERT INTO companies (
+    name,
+    description,
+    employee_count,
+    company_type,
+    status,
+    created_by
+)
+SELECT
+    r.name,
+    NULLIF(r.description, ''),
+    r.employee_count,
+    r.company_type,
+    r.status,
+    $1
+FROM unnest(
+    $2::text[],
+    $3::text[],
+    $4::int[],
+    $5::text[],
+    $6::text[]
+) AS r(name, description, employee_count, company_type, status)
+ON CONFLICT (name) WHERE deleted_at IS NULL DO NOTHING
+RETURNING id, name, description, employee_count, company_type, created_at, updated_at, created_by, updated_by, version, deleted_at, deleted_by, status, registered
+`
+
+type CreateCompaniesParams struct {
+	CreatedBy      pgtype.UUID `json:"created_by"`
+	Names          []string    `json:"names"`
+	Descriptions   []string    `json:"descriptions"`
+	EmployeeCounts []int32     `json:"employee_counts"`
+	CompanyTypes   []string    `json:"company_types"`
+	Statuses       []string    `json:"statuses"`
+}
+
+// Bulk insert of the rows of an import, one array per column.
+// Rows whose name is held by a live company are skipped, only the inserted companies are returned.
+func (q *Queries) CreateCompanies(ctx context.Context, arg CreateCompaniesParams) ([]Company, error) {
+	rows, err := q.db.Query(ctx, createCompanies,
+		arg.CreatedBy,
+		arg.Names,
+		arg.Descriptions,
+		arg.EmployeeCounts,
+		arg.CompanyTypes,
+		arg.Statuses,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Company
+	for rows.Next() {
+		var i Company
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.EmployeeCount,
+			&i.CompanyType,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Status,
+			&i.Registered,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createCompany = `-- name: CreateCompany :one
 INSERT INTO companies (
     name,
     description,
     employee_count,
-    registered,
     company_type,
+    status,
     created_by
 ) VALUES (
     $1, $2, $3, $4, $5, $6
-) RETURNING id, name, description, employee_count, registered, company_type, created_at, updated_at, created_by, updated_by
+) RETURNING id, name, description, employee_count, company_type, created_at, updated_at, created_by, updated_by, version, deleted_at, deleted_by, status, registered
 `
 
 type CreateCompanyParams struct {
 	Name          string      `json:"name"`
 	Description   pgtype.Text `json:"description"`
 	EmployeeCount int32       `json:"employee_count"`
-	Registered    bool        `json:"registered"`
 	CompanyType   string      `json:"company_type"`
+	Status        string      `json:"status"`
 	CreatedBy     pgtype.UUID `json:"created_by"`
 }
 
@@ -39,8 +119,8 @@ func (q *Queries) CreateCompany(ctx context.Context, arg CreateCompanyParams) (C
 		arg.Name,
 		arg.Description,
 		arg.EmployeeCount,
-		arg.Registered,
 		arg.CompanyType,
+		arg.Status,
 		arg.CreatedBy,
 	)
 	var i Company
@@ -49,71 +129,654 @@ func (q *Queries) CreateCompany(ctx context.Context, arg CreateCompanyParams) (C
 		&i.Name,
 		&i.Description,
 		&i.EmployeeCount,
-		&i.Registered,
 		&i.CompanyType,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CreatedBy,
 		&i.UpdatedBy,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.Registered,
 	)
 	return i, err
 }
 
+const declareCompanyExportCursor = `-- name: DeclareCompanyExportCursor :exec
+DECLARE company_export NO SCROLL CURSOR FOR
+SELECT id, name, description, employee_count, company_type, created_at, updated_at, created_by, updated_by, version, deleted_at, deleted_by, status, registered
+FROM companies
+WHERE
+    deleted_at IS NULL
+    AND ($1::text IS NULL OR company_type = $1)
+    AND ($2::boolean IS NULL OR registered = $2)
+    AND ($3::int IS NULL OR employee_count >= $3)
+    AND ($4::int IS NULL OR employee_count <= $4)
+    AND ($5::uuid IS NULL OR created_by = $5)
+ORDER BY created_at, ID
+`
+
+type DeclareCompanyExportCursorParams struct {
+	CompanyType      pgtype.Text `json:"company_type"`
+	Registered       pgtype.Bool `json:"registered"`
+	MinEmployeeCount pgtype.Int4 `json:"min_employee_count"`
+	MaxEmployeeCount pgtype.Int4 `json:"max_employee_count"`
+	CreatedBy        pgtype.UUID `json:"created_by"`
+}
+
+// Opens the company_export cursor over the live companies an export selects, oldest first.
+// The cursor only lives until the end of the transaction declaring it, see FetchCompanyExport.
+func (q *Queries) DeclareCompanyExportCursor(ctx context.Context, arg DeclareCompanyExportCursorParams) error {
+	_, err := q.db.Exec(ctx, declareCompanyExportCursor,
+		arg.CompanyType,
+		arg.Registered,
+		arg.MinEmployeeCount,
+		arg.MaxEmployeeCount,
+		arg.CreatedBy,
+	)
+	return err
+}
+
 const deleteCompany = `-- name: DeleteCompany :one
-DELETE FROM companies
+UPDATE companies
+SET
+    deleted_at = CURRENT_TIMESTAMP,
+    deleted_by = $1,
+    version = version + 1
+WHERE ID = $2
+    AND deleted_at IS NULL
+RETURNING id, name, description, employee_count, company_type, created_at, updated_at, created_by, updated_by, version, deleted_at, deleted_by, status, registered
+`
+
+type DeleteCompanyParams struct {
+	DeletedBy pgtype.UUID `json:"deleted_by"`
+	ID        uuid.UUID   `json:"id"`
+}
+
+// Soft deletion, the row is kept for restoring until PurgeDeletedCompanies removes it.
+func (q *Queries) DeleteCompany(ctx context.Context, arg DeleteCompanyParams) (Company, error) {
+	row := q.db.QueryRow(ctx, deleteCompany, arg.DeletedBy, arg.ID)
+	var i Company
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.EmployeeCount,
+		&i.CompanyType,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.Registered,
+	)
+	return i, err
+}
+
+const fetchCompanyExport = `-- name: FetchCompanyExport :many
+FETCH FORWARD 500 FROM company_export
+`
+
+// Reads the next batch of the company_export cursor, an empty or short batch means it's exhausted.
+// FETCH doesn't accept a parameter for its count.
+func (q *Queries) FetchCompanyExport(ctx context.Context) ([]Company, error) {
+	rows, err := q.db.Query(ctx, fetchCompanyExport)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Company
+	for rows.Next() {
+		var i Company
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.EmployeeCount,
+			&i.CompanyType,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Status,
+			&i.Registered,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getCompanyByID = `-- name: GetCompanyByID :one
+SELECT
+    companies.id, companies.name, companies.description, companies.employee_count, companies.company_type, companies.created_at, companies.updated_at, companies.created_by, companies.updated_by, companies.version, companies.deleted_at, companies.deleted_by, companies.status, companies.registered,
+    creator.username AS created_by_username,
+    updater.username AS updated_by_username
+FROM companies
+LEFT JOIN users AS creator ON creator.ID = companies.created_by
+LEFT JOIN users AS updater ON updater.ID = companies.updated_by
+WHERE companies.ID = $1
+    AND companies.deleted_at IS NULL
+`
+
+type GetCompanyByIDRow struct {
+	Company           Company     `json:"company"`
+	CreatedByUsername pgtype.Text `json:"created_by_username"`
+	UpdatedByUsername pgtype.Text `json:"updated_by_username"`
+}
+
+// The users who created and last updated the company are joined in for its audit metadata.
+func (q *Queries) GetCompanyByID(ctx context.Context, id uuid.UUID) (GetCompanyByIDRow, error) {
+	row := q.db.QueryRow(ctx, getCompanyByID, id)
+	var i GetCompanyByIDRow
+	err := row.Scan(
+		&i.Company.ID,
+		&i.Company.Name,
+		&i.Company.Description,
+		&i.Company.EmployeeCount,
+		&i.Company.CompanyType,
+		&i.Company.CreatedAt,
+		&i.Company.UpdatedAt,
+		&i.Company.CreatedBy,
+		&i.Company.UpdatedBy,
+		&i.Company.Version,
+		&i.Company.DeletedAt,
+		&i.Company.DeletedBy,
+		&i.Company.Status,
+		&i.Company.Registered,
+		&i.CreatedByUsername,
+		&i.UpdatedByUsername,
+	)
+	return i, err
+}
+
+const getCompanyByIDForUpdate = `-- name: GetCompanyByIDForUpdate :one
+SELECT id, name, description, employee_count, company_type, created_at, updated_at, created_by, updated_by, version, deleted_at, deleted_by, status, registered
+FROM companies
 WHERE ID = $1
-RETURNING ID
+    AND deleted_at IS NULL
+FOR UPDATE
 `
 
-func (q *Queries) DeleteCompany(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
-	row := q.db.QueryRow(ctx, deleteCompany, id)
-	err := row.Scan(&id)
-	return id, err
+// Locks the row until the end of the transaction.
+func (q *Queries) GetCompanyByIDForUpdate(ctx context.Context, id uuid.UUID) (Company, error) {
+	row := q.db.QueryRow(ctx, getCompanyByIDForUpdate, id)
+	var i Company
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.EmployeeCount,
+		&i.CompanyType,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.Registered,
+	)
+	return i, err
 }
 
 const getCompanyByName = `-- name: GetCompanyByName :one
-SELECT id, name, description, employee_count, registered, company_type, created_at, updated_at, created_by, updated_by
+SELECT
+    companies.id, companies.name, companies.description, companies.employee_count, companies.company_type, companies.created_at, companies.updated_at, companies.created_by, companies.updated_by, companies.version, companies.deleted_at, companies.deleted_by, companies.status, companies.registered,
+    creator.username AS created_by_username,
+    updater.username AS updated_by_username
 FROM companies
-WHERE name = $1
+LEFT JOIN users AS creator ON creator.ID = companies.created_by
+LEFT JOIN users AS updater ON updater.ID = companies.updated_by
+WHERE companies.name = $1
+    AND companies.deleted_at IS NULL
 `
 
-func (q *Queries) GetCompanyByName(ctx context.Context, name string) (Company, error) {
+type GetCompanyByNameRow struct {
+	Company           Company     `json:"company"`
+	CreatedByUsername pgtype.Text `json:"created_by_username"`
+	UpdatedByUsername pgtype.Text `json:"updated_by_username"`
+}
+
+// The users who created and last updated the company are joined in for its audit metadata.
+func (q *Queries) GetCompanyByName(ctx context.Context, name string) (GetCompanyByNameRow, error) {
 	row := q.db.QueryRow(ctx, getCompanyByName, name)
+	var i GetCompanyByNameRow
+	err := row.Scan(
+		&i.Company.ID,
+		&i.Company.Name,
+		&i.Company.Description,
+		&i.Company.EmployeeCount,
+		&i.Company.CompanyType,
+		&i.Company.CreatedAt,
+		&i.Company.UpdatedAt,
+		&i.Company.CreatedBy,
+		&i.Company.UpdatedBy,
+		&i.Company.Version,
+		&i.Company.DeletedAt,
+		&i.Company.DeletedBy,
+		&i.Company.Status,
+		&i.Company.Registered,
+		&i.CreatedByUsername,
+		&i.UpdatedByUsername,
+	)
+	return i, err
+}
+
+const getDeletedCompanyByIDForUpdate = `-- name: GetDeletedCompanyByIDForUpdate :one
+SELECT id, name, description, employee_count, company_type, created_at, updated_at, created_by, updated_by, version, deleted_at, deleted_by, status, registered
+FROM companies
+WHERE ID = $1
+    AND deleted_at IS NOT NULL
+FOR UPDATE
+`
+
+// Locks the row until the end of the transaction.
+func (q *Queries) GetDeletedCompanyByIDForUpdate(ctx context.Context, id uuid.UUID) (Company, error) {
+	row := q.db.QueryRow(ctx, getDeletedCompanyByIDForUpdate, id)
 	var i Company
 	err := row.Scan(
 		&i.ID,
 		&i.Name,
 		&i.Description,
 		&i.EmployeeCount,
+		&i.CompanyType,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
 		&i.Registered,
+	)
+	return i, err
+}
+
+const listCompanies = `-- name: ListCompanies :many
+SELECT
+    companies.id, companies.name, companies.description, companies.employee_count, companies.company_type, companies.created_at, companies.updated_at, companies.created_by, companies.updated_by, companies.version, companies.deleted_at, companies.deleted_by, companies.status, companies.registered,
+    creator.username AS created_by_username,
+    updater.username AS updated_by_username
+FROM companies
+LEFT JOIN users AS creator ON creator.ID = companies.created_by
+LEFT JOIN users AS updater ON updater.ID = companies.updated_by
+WHERE
+    companies.deleted_at IS NULL
+    AND ($1::text IS NULL OR company_type = $1)
+    AND ($2::boolean IS NULL OR registered = $2)
+    AND ($3::int IS NULL OR employee_count >= $3)
+    AND ($4::int IS NULL OR employee_count <= $4)
+    AND ($5::uuid IS NULL OR companies.created_by = $5)
+    AND (
+        $6::uuid IS NULL
+        OR CASE
+            WHEN $7::text = 'name' AND NOT $8::boolean
+                THEN (name, companies.ID) > ($9::text, $6)
+            WHEN $7 = 'name'
+                THEN (name, companies.ID) < ($9, $6)
+            WHEN $7 = 'employee_count' AND NOT $8
+                THEN (employee_count, companies.ID) > ($10::int, $6)
+            WHEN $7 = 'employee_count'
+                THEN (employee_count, companies.ID) < ($10, $6)
+            WHEN NOT $8
+                THEN (companies.created_at, companies.ID) > ($11::timestamp, $6)
+            ELSE (companies.created_at, companies.ID) < ($11, $6)
+        END
+    )
+ORDER BY
+    CASE WHEN $7 = 'name' AND NOT $8 THEN name END ASC,
+    CASE WHEN $7 = 'name' AND $8 THEN name END DESC,
+    CASE WHEN $7 = 'employee_count' AND NOT $8 THEN employee_count END ASC,
+    CASE WHEN $7 = 'employee_count' AND $8 THEN employee_count END DESC,
+    CASE WHEN $7 = 'created_at' AND NOT $8 THEN companies.created_at END ASC,
+    CASE WHEN $7 = 'created_at' AND $8 THEN companies.created_at END DESC,
+    CASE WHEN NOT $8 THEN companies.ID END ASC,
+    CASE WHEN $8 THEN companies.ID END DESC
+LIMIT $12
+`
+
+type ListCompaniesParams struct {
+	CompanyType         pgtype.Text      `json:"company_type"`
+	Registered          pgtype.Bool      `json:"registered"`
+	MinEmployeeCount    pgtype.Int4      `json:"min_employee_count"`
+	MaxEmployeeCount    pgtype.Int4      `json:"max_employee_count"`
+	CreatedBy           pgtype.UUID      `json:"created_by"`
+	CursorID            pgtype.UUID      `json:"cursor_id"`
+	SortBy              string           `json:"sort_by"`
+	Descending          bool             `json:"descending"`
+	CursorName          pgtype.Text      `json:"cursor_name"`
+	CursorEmployeeCount pgtype.Int4      `json:"cursor_employee_count"`
+	CursorCreatedAt     pgtype.Timestamp `json:"cursor_created_at"`
+	PageLimit           int32            `json:"page_limit"`
+}
+
+type ListCompaniesRow struct {
+	Company           Company     `json:"company"`
+	CreatedByUsername pgtype.Text `json:"created_by_username"`
+	UpdatedByUsername pgtype.Text `json:"updated_by_username"`
+}
+
+// Keyset pagination: resumes strictly after the cursor row in the requested order.
+// ID acts as a tie-breaker so rows sharing a sort value are never skipped or repeated.
+// The users who created and last updated each company are joined in for its audit metadata.
+func (q *Queries) ListCompanies(ctx context.Context, arg ListCompaniesParams) ([]ListCompaniesRow, error) {
+	rows, err := q.db.Query(ctx, listCompanies,
+		arg.CompanyType,
+		arg.Registered,
+		arg.MinEmployeeCount,
+		arg.MaxEmployeeCount,
+		arg.CreatedBy,
+		arg.CursorID,
+		arg.SortBy,
+		arg.Descending,
+		arg.CursorName,
+		arg.CursorEmployeeCount,
+		arg.CursorCreatedAt,
+		arg.PageLimit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCompaniesRow
+	for rows.Next() {
+		var i ListCompaniesRow
+		if err := rows.Scan(
+			&i.Company.ID,
+			&i.Company.Name,
+			&i.Company.Description,
+			&i.Company.EmployeeCount,
+			&i.Company.CompanyType,
+			&i.Company.CreatedAt,
+			&i.Company.UpdatedAt,
+			&i.Company.CreatedBy,
+			&i.Company.UpdatedBy,
+			&i.Company.Version,
+			&i.Company.DeletedAt,
+			&i.Company.DeletedBy,
+			&i.Company.Status,
+			&i.Company.Registered,
+			&i.CreatedByUsername,
+			&i.UpdatedByUsername,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const purgeDeletedCompanies = `-- name: PurgeDeletedCompanies :many
+DELETE FROM companies
+WHERE ID IN (
+    SELECT ID
+    FROM companies
+    WHERE deleted_at < $1::timestamp
+    ORDER BY deleted_at
+    LIMIT $2
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING id, name, description, employee_count, company_type, created_at, updated_at, created_by, updated_by, version, deleted_at, deleted_by, status, registered
+`
+
+type PurgeDeletedCompaniesParams struct {
+	DeletedBefore pgtype.Timestamp `json:"deleted_before"`
+	BatchSize     int32            `json:"batch_size"`
+}
+
+// Hard-deletes up to batch_size companies deleted before the cutoff.
+// Rows locked by a concurrent restore or purge are skipped rather than waited for.
+func (q *Queries) PurgeDeletedCompanies(ctx context.Context, arg PurgeDeletedCompaniesParams) ([]Company, error) {
+	rows, err := q.db.Query(ctx, purgeDeletedCompanies, arg.DeletedBefore, arg.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Company
+	for rows.Next() {
+		var i Company
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.EmployeeCount,
+			&i.CompanyType,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.CreatedBy,
+			&i.UpdatedBy,
+			&i.Version,
+			&i.DeletedAt,
+			&i.DeletedBy,
+			&i.Status,
+			&i.Registered,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreCompany = `-- name: RestoreCompany :one
+UPDATE companies
+SET
+    deleted_at = NULL,
+    deleted_by = NULL,
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = $1,
+    version = version + 1
+WHERE ID = $2
+    AND deleted_at IS NOT NULL
+RETURNING id, name, description, employee_count, company_type, created_at, updated_at, created_by, updated_by, version, deleted_at, deleted_by, status, registered
+`
+
+type RestoreCompanyParams struct {
+	UpdatedBy pgtype.UUID `json:"updated_by"`
+	ID        uuid.UUID   `json:"id"`
+}
+
+func (q *Queries) RestoreCompany(ctx context.Context, arg RestoreCompanyParams) (Company, error) {
+	row := q.db.QueryRow(ctx, restoreCompany, arg.UpdatedBy, arg.ID)
+	var i Company
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.EmployeeCount,
 		&i.CompanyType,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CreatedBy,
 		&i.UpdatedBy,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.Registered,
 	)
 	return i, err
 }
 
+const searchCompanies = `-- name: SearchCompanies :many
+SELECT
+    companies.id, companies.name, companies.description, companies.employee_count, companies.company_type, companies.created_at, companies.updated_at, companies.created_by, companies.updated_by, companies.version, companies.deleted_at, companies.deleted_by, companies.status, companies.registered,
+    (
+        ts_rank(
+            setweight(to_tsvector('simple', name), 'A')
+            || setweight(to_tsvector('english', coalesce(description, '')), 'B'),
+            websearch_to_tsquery('english', $1::text)
+        )
+        + similarity(name, $1)
+    )::real AS rank,
+    ts_headline(
+        'english',
+        translate(coalesce(description, ''), chr(2) || chr(3), ''),
+        websearch_to_tsquery('english', $1),
+        'StartSel="' || chr(2) || '", StopSel="' || chr(3) || '", MaxFragments=2, MaxWords=20, MinWords=5'
+    )::text AS snippet
+FROM companies
+WHERE
+    deleted_at IS NULL
+    AND (
+        (
+            setweight(to_tsvector('simple', name), 'A')
+            || setweight(to_tsvector('english', coalesce(description, '')), 'B')
+        ) @@ websearch_to_tsquery('english', $1)
+        OR name % $1
+        OR name ILIKE $2::text
+    )
+ORDER BY rank DESC, ID
+LIMIT $3
+`
+
+type SearchCompaniesParams struct {
+	Query       string `json:"query"`
+	Pattern     string `json:"pattern"`
+	ResultLimit int32  `json:"result_limit"`
+}
+
+type SearchCompaniesRow struct {
+	Company Company `json:"company"`
+	Rank    float32 `json:"rank"`
+	Snippet string  `json:"snippet"`
+}
+
+// Ranks companies by full-text relevance over name and description plus trigram similarity of the name.
+// Requires the pg_trgm extension, see SearchCompaniesByPattern for the fallback.
+// Snippets mark matches with chr(2) and chr(3), stripped from the description beforehand, so the adapter
+// can escape the description before turning them into markup.
+func (q *Queries) SearchCompanies(ctx context.Context, arg SearchCompaniesParams) ([]SearchCompaniesRow, error) {
+	rows, err := q.db.Query(ctx, searchCompanies, arg.Query, arg.Pattern, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchCompaniesRow
+	for rows.Next() {
+		var i SearchCompaniesRow
+		if err := rows.Scan(
+			&i.Company.ID,
+			&i.Company.Name,
+			&i.Company.Description,
+			&i.Company.EmployeeCount,
+			&i.Company.CompanyType,
+			&i.Company.CreatedAt,
+			&i.Company.UpdatedAt,
+			&i.Company.CreatedBy,
+			&i.Company.UpdatedBy,
+			&i.Company.Version,
+			&i.Company.DeletedAt,
+			&i.Company.DeletedBy,
+			&i.Company.Status,
+			&i.Company.Registered,
+			&i.Rank,
+			&i.Snippet,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchCompaniesByPattern = `-- name: SearchCompaniesByPattern :many
+SELECT companies.id, companies.name, companies.description, companies.employee_count, companies.company_type, companies.created_at, companies.updated_at, companies.created_by, companies.updated_by, companies.version, companies.deleted_at, companies.deleted_by, companies.status, companies.registered
+FROM companies
+WHERE deleted_at IS NULL
+    AND (name ILIKE $1::text OR description ILIKE $1)
+ORDER BY (name ILIKE $1) DESC, name, ID
+LIMIT $2
+`
+
+type SearchCompaniesByPatternParams struct {
+	Pattern     string `json:"pattern"`
+	ResultLimit int32  `json:"result_limit"`
+}
+
+type SearchCompaniesByPatternRow struct {
+	Company Company `json:"company"`
+}
+
+// Plain substring search used when pg_trgm is unavailable.
+// Name matches rank above description matches.
+func (q *Queries) SearchCompaniesByPattern(ctx context.Context, arg SearchCompaniesByPatternParams) ([]SearchCompaniesByPatternRow, error) {
+	rows, err := q.db.Query(ctx, searchCompaniesByPattern, arg.Pattern, arg.ResultLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchCompaniesByPatternRow
+	for rows.Next() {
+		var i SearchCompaniesByPatternRow
+		if err := rows.Scan(
+			&i.Company.ID,
+			&i.Company.Name,
+			&i.Company.Description,
+			&i.Company.EmployeeCount,
+			&i.Company.CompanyType,
+			&i.Company.CreatedAt,
+			&i.Company.UpdatedAt,
+			&i.Company.CreatedBy,
+			&i.Company.UpdatedBy,
+			&i.Company.Version,
+			&i.Company.DeletedAt,
+			&i.Company.DeletedBy,
+			&i.Company.Status,
+			&i.Company.Registered,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateCompany = `-- name: UpdateCompany :one
 UPDATE companies
 SET
     name = COALESCE($1, name),
     description = COALESCE($2, description),
     employee_count = COALESCE($3, employee_count),
-    registered = COALESCE($4, registered),
-    company_type = COALESCE($5, company_type),
+    company_type = COALESCE($4, company_type),
     updated_at = CURRENT_TIMESTAMP,
-    updated_by = $6
-WHERE ID = $7
-RETURNING id, name, description, employee_count, registered, company_type, created_at, updated_at, created_by, updated_by
+    updated_by = $5,
+    version = version + 1
+WHERE ID = $6
+    AND deleted_at IS NULL
+RETURNING id, name, description, employee_count, company_type, created_at, updated_at, created_by, updated_by, version, deleted_at, deleted_by, status, registered
 `
 
 type UpdateCompanyParams struct {
 	Name          pgtype.Text `json:"name"`
 	Description   pgtype.Text `json:"description"`
 	EmployeeCount pgtype.Int4 `json:"employee_count"`
-	Registered    pgtype.Bool `json:"registered"`
 	CompanyType   pgtype.Text `json:"company_type"`
 	UpdatedBy     pgtype.UUID `json:"updated_by"`
 	ID            uuid.UUID   `json:"id"`
@@ -124,7 +787,6 @@ func (q *Queries) UpdateCompany(ctx context.Context, arg UpdateCompanyParams) (C
 		arg.Name,
 		arg.Description,
 		arg.EmployeeCount,
-		arg.Registered,
 		arg.CompanyType,
 		arg.UpdatedBy,
 		arg.ID,
@@ -135,12 +797,57 @@ func (q *Queries) UpdateCompany(ctx context.Context, arg UpdateCompanyParams) (C
 		&i.Name,
 		&i.Description,
 		&i.EmployeeCount,
+		&i.CompanyType,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.CreatedBy,
+		&i.UpdatedBy,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
 		&i.Registered,
+	)
+	return i, err
+}
+
+const updateCompanyStatus = `-- name: UpdateCompanyStatus :one
+UPDATE companies
+SET
+    status = $1,
+    updated_at = CURRENT_TIMESTAMP,
+    updated_by = $2,
+    version = version + 1
+WHERE ID = $3
+    AND deleted_at IS NULL
+RETURNING id, name, description, employee_count, company_type, created_at, updated_at, created_by, updated_by, version, deleted_at, deleted_by, status, registered
+`
+
+type UpdateCompanyStatusParams struct {
+	Status    string      `json:"status"`
+	UpdatedBy pgtype.UUID `json:"updated_by"`
+	ID        uuid.UUID   `json:"id"`
+}
+
+// Statuses only change through the lifecycle transitions the service allows, see UpdateCompany.
+func (q *Queries) UpdateCompanyStatus(ctx context.Context, arg UpdateCompanyStatusParams) (Company, error) {
+	row := q.db.QueryRow(ctx, updateCompanyStatus, arg.Status, arg.UpdatedBy, arg.ID)
+	var i Company
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.EmployeeCount,
 		&i.CompanyType,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.CreatedBy,
 		&i.UpdatedBy,
+		&i.Version,
+		&i.DeletedAt,
+		&i.DeletedBy,
+		&i.Status,
+		&i.Registered,
 	)
 	return i, err
 }
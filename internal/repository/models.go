@@ -14,12 +14,82 @@ type Company struct {
 	Name          string           `json:"name"`
 	Description   pgtype.Text      `json:"description"`
 	EmployeeCount int32            `json:"employee_count"`
-	Registered    bool             `json:"registered"`
 	CompanyType   string           `json:"company_type"`
 	CreatedAt     pgtype.Timestamp `json:"created_at"`
 	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
 	CreatedBy     pgtype.UUID      `json:"created_by"`
 	UpdatedBy     pgtype.UUID      `json:"updated_by"`
+	Version       int64            `json:"version"`
+	DeletedAt     pgtype.Timestamp `json:"deleted_at"`
+	DeletedBy     pgtype.UUID      `json:"deleted_by"`
+	Status        string           `json:"status"`
+	Registered    bool             `json:"registered"`
+}
+
+type CompanyRevision struct {
+	CompanyID     uuid.UUID        `json:"company_id"`
+	Revision      int64            `json:"revision"`
+	Operation     string           `json:"operation"`
+	Name          string           `json:"name"`
+	Description   pgtype.Text      `json:"description"`
+	EmployeeCount int32            `json:"employee_count"`
+	CompanyType   string           `json:"company_type"`
+	Actor         pgtype.UUID      `json:"actor"`
+	ChangedAt     pgtype.Timestamp `json:"changed_at"`
+	ValidUntil    pgtype.Timestamp `json:"valid_until"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	CreatedBy     pgtype.UUID      `json:"created_by"`
+	Status        string           `json:"status"`
+	Reason        pgtype.Text      `json:"reason"`
+	Registered    bool             `json:"registered"`
+}
+
+type CompanyType struct {
+	Name        string           `json:"name"`
+	Description pgtype.Text      `json:"description"`
+	Active      bool             `json:"active"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	UpdatedAt   pgtype.Timestamp `json:"updated_at"`
+}
+
+type IdempotencyKey struct {
+	UserID          uuid.UUID        `json:"user_id"`
+	IdempotencyKey  string           `json:"idempotency_key"`
+	RequestHash     []byte           `json:"request_hash"`
+	ResponseStatus  pgtype.Int4      `json:"response_status"`
+	ResponseHeaders []byte           `json:"response_headers"`
+	ResponseBody    []byte           `json:"response_body"`
+	CreatedAt       pgtype.Timestamp `json:"created_at"`
+	ExpiresAt       pgtype.Timestamp `json:"expires_at"`
+}
+
+type Outbox struct {
+	ID            int64            `json:"id"`
+	Topic         string           `json:"topic"`
+	EventKey      string           `json:"event_key"`
+	Payload       []byte           `json:"payload"`
+	Attempts      int32            `json:"attempts"`
+	LastError     pgtype.Text      `json:"last_error"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+	NextAttemptAt pgtype.Timestamp `json:"next_attempt_at"`
+	Headers       []byte           `json:"headers"`
+	ParkedAt      pgtype.Timestamp `json:"parked_at"`
+}
+
+type RefreshToken struct {
+	ID        uuid.UUID        `json:"id"`
+	UserID    uuid.UUID        `json:"user_id"`
+	FamilyID  uuid.UUID        `json:"family_id"`
+	TokenHash []byte           `json:"token_hash"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+	RotatedAt pgtype.Timestamp `json:"rotated_at"`
+	RevokedAt pgtype.Timestamp `json:"revoked_at"`
+}
+
+type RevokedAccessToken struct {
+	Jti       uuid.UUID        `json:"jti"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
 }
 
 type User struct {
@@ -28,4 +98,5 @@ type User struct {
 	PasswordHash string           `json:"password_hash"`
 	CreatedAt    pgtype.Timestamp `json:"created_at"`
 	LastLogin    pgtype.Timestamp `json:"last_login"`
+	Role         string           `json:"role"`
 }
@@ -2,10 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
 
 	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
 	"github.com/Laelapa/CompanyRegistry/util/ctxutils"
 
 	"go.uber.org/zap"
@@ -13,35 +13,37 @@ import (
 
 // HandleCreateCompany processes requests to create a new company.
 // It expects a userID in the request context - set by the jwt authentication middleware.
+// Companies are created pending and approved through transitions, so registered can only be false if it's sent.
 func (h *Handler) HandleCreateCompany(w http.ResponseWriter, r *http.Request) {
 	var rBody CreateCompanyRequest
 	h.logger.Info("Processing Create Company request", h.logger.ReqFields(r)...)
 
 	if err := json.NewDecoder(r.Body).Decode(&rBody); err != nil {
 		h.logger.Warn("Failed to decode request body", append(h.logger.ReqFields(r), zap.Error(err))...)
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		h.writeProblem(w, r, problem.BadRequest("Malformed request body"))
 		return
 	}
 
 	if err := h.validator.Struct(rBody); err != nil {
 		h.logger.Warn("Invalid request data", append(h.logger.ReqFields(r), zap.Error(err))...)
-		http.Error(w, "Bad request: Invalid data", http.StatusBadRequest)
+		h.writeProblem(w, r, problem.Validation(err))
 		return
 	}
 
 	userID, ok := ctxutils.GetUserIDFromContext(r.Context())
 	if !ok {
 		h.logger.Error("Failed to get user ID from context", h.logger.ReqFields(r)...)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		h.writeProblem(w, r, problem.Unauthorized())
 		return
 	}
 
 	companyType := domain.CompanyType(rBody.CompanyType)
+	status := domain.CompanyStatusPending
 	newCompany := &domain.Company{
 		Name:          &rBody.Name,
 		Description:   rBody.Description,
 		EmployeeCount: rBody.EmployeeCount,
-		Registered:    rBody.Registered,
+		Status:        &status,
 		CompanyType:   &companyType,
 		CreatedBy:     &userID,
 	}
@@ -49,14 +51,10 @@ func (h *Handler) HandleCreateCompany(w http.ResponseWriter, r *http.Request) {
 	createdCompany, err := h.service.Company.Create(r.Context(), newCompany)
 	if err != nil {
 		h.logger.Error("Failed to create company", append(h.logger.ReqFields(r), zap.Error(err))...)
-		switch {
-		case errors.Is(err, domain.ErrConflict):
-			http.Error(w, "Conflict: Company already exists", http.StatusConflict)
-		case errors.Is(err, domain.ErrBadRequest):
-			http.Error(w, "Bad request: Invalid company data", http.StatusBadRequest)
-		default:
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrConflict:   "Company already exists",
+			domain.ErrBadRequest: "Invalid company data",
+		}))
 		return
 	}
 
@@ -65,10 +63,11 @@ func (h *Handler) HandleCreateCompany(w http.ResponseWriter, r *http.Request) {
 	respMarshalled, err := json.Marshal(response)
 	if err != nil {
 		h.logger.Error("Failed to marshal response", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		h.writeProblem(w, r, problem.Internal())
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", companyETag(createdCompany))
 	w.WriteHeader(http.StatusCreated)
 	if _, err := w.Write(respMarshalled); err != nil {
 		h.logger.Error("Failed to write response", zap.Error(err))
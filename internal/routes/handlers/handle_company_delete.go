@@ -1,32 +1,52 @@
 package handlers
 
 import (
-	"errors"
 	"net/http"
 
+	"github.com/Laelapa/CompanyRegistry/internal/authz"
 	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+// HandleDeleteCompany processes requests to delete a company.
+// It expects a userID and role in the request context - set by the jwt authentication middleware.
+// Only the company's creator, editors and admins may delete it.
+// An If-Match header makes the deletion conditional on the company's ETag, failing with 412 if it has changed.
 func (h *Handler) HandleDeleteCompany(w http.ResponseWriter, r *http.Request) {
 	id, pErr := uuid.Parse(r.PathValue("id"))
 	if pErr != nil {
 		h.logger.Warn("Invalid company ID in path", append(h.logger.ReqFields(r), zap.Error(pErr))...)
-		http.Error(w, "Bad request: Invalid ID", http.StatusBadRequest)
+		h.writeProblem(w, r, problem.BadRequest("Invalid ID"))
 		return
 	}
 
 	h.logger.Info("Processing Delete Company request", h.logger.ReqFields(r)...)
 
-	if err := h.service.Company.Delete(r.Context(), id); err != nil {
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		h.logger.Warn("Invalid If-Match header", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrBadRequest: "Invalid If-Match header",
+		}))
+		return
+	}
+
+	principal, ok := authz.PrincipalFromContext(r.Context())
+	if !ok {
+		h.logger.Error("Failed to get user ID from context", h.logger.ReqFields(r)...)
+		h.writeProblem(w, r, problem.Unauthorized())
+		return
+	}
+
+	if err = h.service.Company.Delete(r.Context(), principal, id, expectedVersion); err != nil {
 		h.logger.Error("Failed to delete company", append(h.logger.ReqFields(r), zap.Error(err))...)
-		if errors.Is(err, domain.ErrNotFound) {
-			http.Error(w, "Company not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrNotFound:           "Company not found",
+			domain.ErrPreconditionFailed: "Company has been modified since it was read",
+		}))
 		return
 	}
 
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+)
+
+// companyETag is the strong entity tag of the company's current version.
+func companyETag(c *domain.Company) string {
+	return strconv.Quote(strconv.FormatInt(*c.Version, 10))
+}
+
+// parseIfMatch reads the company version a conditional request is based on from its If-Match header.
+// A missing header or "*" matches any version and yields nil. A single entity tag is supported.
+// Weak tags never match, as If-Match compares strongly, which is reported as domain.ErrPreconditionFailed.
+func parseIfMatch(r *http.Request) (*int64, error) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil //nolint:nilnil // no version to match is not an error
+	}
+	if strings.HasPrefix(header, "W/") {
+		return nil, fmt.Errorf("weak entity tag %s in If-Match: %w", header, domain.ErrPreconditionFailed)
+	}
+
+	unquoted, err := strconv.Unquote(header)
+	if err != nil {
+		return nil, fmt.Errorf("malformed If-Match %q: %w", header, domain.ErrBadRequest)
+	}
+	version, err := strconv.ParseInt(unquoted, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unknown entity tag %s in If-Match: %w", header, domain.ErrPreconditionFailed)
+	}
+	return &version, nil
+}
+
+// companyLastModified is when the company was last updated, or created if it never was.
+// It's truncated to the second precision of HTTP dates.
+func companyLastModified(c *domain.Company) time.Time {
+	switch {
+	case c.UpdatedAt != nil:
+		return c.UpdatedAt.UTC().Truncate(time.Second)
+	case c.CreatedAt != nil:
+		return c.CreatedAt.UTC().Truncate(time.Second)
+	default:
+		return time.Time{}
+	}
+}
+
+// notModified evaluates the conditional headers of a GET request against the current state of a resource.
+// If-None-Match takes precedence, If-Modified-Since is only considered without it.
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		for tag := range strings.SplitSeq(header, ",") {
+			tag = strings.TrimSpace(tag)
+			// If-None-Match compares weakly
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	return !lastModified.After(since)
+}
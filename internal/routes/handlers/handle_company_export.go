@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// companyExportFormat is a representation an export can be negotiated in.
+type companyExportFormat struct {
+	mediaType string
+	extension string
+	newWriter func(w io.Writer) companyExportWriter
+}
+
+// companyExportWriter encodes the records of an export as they are read.
+type companyExportWriter interface {
+	Begin() error
+	Write(rec CompanyExportRecord) error
+	End() error
+}
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+type ndjsonExportWriter struct {
+	enc *json.Encoder
+}
+
+type jsonArrayExportWriter struct {
+	w       io.Writer
+	written bool
+}
+
+const (
+	exportBufferSize = 32 << 10
+	// Records written between flushes, each flush also extends the write deadline by exportWriteWindow,
+	// up to the deadline of the whole export
+	exportFlushEvery  = 500
+	exportWriteWindow = 30 * time.Second
+)
+
+// companyExportFormats in order of preference when the Accept header allows several equally.
+var companyExportFormats = []companyExportFormat{
+	{
+		mediaType: "application/json",
+		extension: "json",
+		newWriter: func(w io.Writer) companyExportWriter { return &jsonArrayExportWriter{w: w} },
+	},
+	{
+		mediaType: "application/x-ndjson",
+		extension: "ndjson",
+		newWriter: func(w io.Writer) companyExportWriter { return &ndjsonExportWriter{enc: json.NewEncoder(w)} },
+	},
+	{
+		mediaType: "text/csv",
+		extension: "csv",
+		newWriter: func(w io.Writer) companyExportWriter { return &csvExportWriter{w: csv.NewWriter(w)} },
+	},
+}
+
+// companyExportColumns are the CSV columns of an export, named like the JSON fields of CompanyExportRecord.
+var companyExportColumns = []string{
+	"id", "name", "description", "employee_count", "status", "registered", "company_type",
+	"version", "created_at", "created_by", "updated_at", "updated_by",
+}
+
+// HandleExportCompanies processes requests to export every company matching the filters of a listing.
+// It expects a userID in the request context - set by the jwt authentication middleware.
+// The companies are streamed as they are read from the database, as a JSON array, NDJSON or CSV
+// depending on the Accept header, and include their audit fields.
+// A failure after the response has started aborts it, so clients never mistake a partial export for a full one.
+// Exports taking longer than the export timeout are aborted, so slow clients can't keep their transaction open.
+func (h *Handler) HandleExportCompanies(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Processing Export Companies request", h.logger.ReqFields(r)...)
+
+	rQuery, err := parseCompanyFilterQuery(r.URL.Query())
+	if err != nil {
+		h.logger.Warn("Failed to parse query parameters", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.BadRequest("Malformed query parameters"))
+		return
+	}
+
+	if err = h.validator.Struct(rQuery); err != nil {
+		h.logger.Warn("Invalid request data", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.Validation(err))
+		return
+	}
+
+	format, ok := negotiateCompanyExportFormat(r.Header.Get("Accept"))
+	if !ok {
+		h.logger.Warn("No acceptable export format", h.logger.ReqFields(r)...)
+		h.writeProblem(w, r, problem.New(http.StatusNotAcceptable, problem.CodeNotAcceptable,
+			"Exports are available as application/json, application/x-ndjson or text/csv"))
+		return
+	}
+
+	ctx := r.Context()
+	var deadline time.Time
+	if h.exportTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.exportTimeout)
+		defer cancel()
+		deadline, _ = ctx.Deadline()
+	}
+
+	bw := bufio.NewWriterSize(w, exportBufferSize)
+	ew := format.newWriter(bw)
+	rc := http.NewResponseController(w)
+	started := false
+	written := 0
+
+	// The response only starts with the first company, so a failure to open the export can still be reported
+	start := func() error {
+		started = true
+		w.Header().Set("Content-Type", format.mediaType)
+		w.Header().Set("Content-Disposition", `attachment; filename="companies.`+format.extension+`"`)
+		w.WriteHeader(http.StatusOK)
+		return ew.Begin()
+	}
+
+	err = h.service.Company.Export(ctx, convertToCompanyFilter(rQuery), func(c *domain.Company) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		if err := ew.Write(convertToCompanyExportRecord(c)); err != nil {
+			return err
+		}
+		written++
+		if written%exportFlushEvery != 0 {
+			return nil
+		}
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+		if err := rc.Flush(); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return err
+		}
+		next := time.Now().Add(exportWriteWindow)
+		if !deadline.IsZero() && deadline.Before(next) {
+			next = deadline
+		}
+		dErr := rc.SetWriteDeadline(next)
+		if dErr != nil && !errors.Is(dErr, http.ErrNotSupported) {
+			return dErr
+		}
+		return nil
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		err = ew.End()
+	}
+	if err == nil {
+		err = bw.Flush()
+	}
+
+	if err != nil {
+		h.logger.Error("Failed to export companies", append(h.logger.ReqFields(r), zap.Error(err))...)
+		if !started {
+			h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+				domain.ErrBadRequest: "Invalid export filters",
+			}))
+			return
+		}
+		// The status has been sent, cutting the connection is the only way left to tell the client
+		panic(http.ErrAbortHandler)
+	}
+
+	h.logger.Info("Company export request processed", append(h.logger.ReqFields(r), zap.Int("companies", written))...)
+}
+
+// negotiateCompanyExportFormat picks the export format the Accept header prefers.
+// A missing header accepts anything. Media ranges with a zero or malformed quality are ignored.
+func negotiateCompanyExportFormat(accept string) (companyExportFormat, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return companyExportFormats[0], true
+	}
+
+	var best companyExportFormat
+	bestQ, found := 0.0, false
+	for part := range strings.SplitSeq(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		// Ties go to the range listed first
+		if q <= bestQ {
+			continue
+		}
+		for _, f := range companyExportFormats {
+			if mediaRangeMatches(mediaType, f.mediaType) {
+				best, bestQ, found = f, q, true
+				break
+			}
+		}
+	}
+	return best, found
+}
+
+// mediaRangeMatches reports whether a media range of an Accept header covers mediaType.
+// application/ndjson is taken as another name for application/x-ndjson.
+func mediaRangeMatches(mediaRange, mediaType string) bool {
+	if mediaRange == "application/ndjson" {
+		mediaRange = "application/x-ndjson"
+	}
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+	rangeType, subtype, _ := strings.Cut(mediaRange, "/")
+	return subtype == "*" && strings.HasPrefix(mediaType, rangeType+"/")
+}
+
+func (e *csvExportWriter) Begin() error {
+	return e.w.Write(companyExportColumns)
+}
+
+func (e *csvExportWriter) Write(rec CompanyExportRecord) error {
+	description := ""
+	if rec.Description != nil {
+		description = *rec.Description
+	}
+	return e.w.Write([]string{
+		rec.ID.String(),
+		rec.Name,
+		description,
+		strconv.FormatInt(int64(rec.EmployeeCount), 10),
+		rec.Status,
+		strconv.FormatBool(rec.Registered),
+		rec.CompanyType,
+		strconv.FormatInt(rec.Version, 10),
+		csvTime(rec.CreatedAt),
+		csvUUID(rec.CreatedBy),
+		csvTime(rec.UpdatedAt),
+		csvUUID(rec.UpdatedBy),
+	})
+}
+
+func (e *csvExportWriter) End() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *ndjsonExportWriter) Begin() error { return nil }
+
+func (e *ndjsonExportWriter) Write(rec CompanyExportRecord) error {
+	return e.enc.Encode(rec)
+}
+
+func (e *ndjsonExportWriter) End() error { return nil }
+
+func (e *jsonArrayExportWriter) Begin() error {
+	_, err := io.WriteString(e.w, "[")
+	return err
+}
+
+func (e *jsonArrayExportWriter) Write(rec CompanyExportRecord) error {
+	raw, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if e.written {
+		if _, err = io.WriteString(e.w, ","); err != nil {
+			return err
+		}
+	}
+	e.written = true
+	_, err = e.w.Write(raw)
+	return err
+}
+
+func (e *jsonArrayExportWriter) End() error {
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+func convertToCompanyExportRecord(c *domain.Company) CompanyExportRecord {
+	resp := convertToCompanyResponse(c)
+	version := int64(0)
+	if c.Version != nil {
+		version = *c.Version
+	}
+	return CompanyExportRecord{
+		ID:            resp.ID,
+		Name:          resp.Name,
+		Description:   resp.Description,
+		EmployeeCount: resp.EmployeeCount,
+		Status:        resp.Status,
+		Registered:    resp.Registered,
+		CompanyType:   resp.CompanyType,
+		Version:       version,
+		CreatedAt:     c.CreatedAt,
+		CreatedBy:     auditActor(c.CreatedBy),
+		UpdatedAt:     c.UpdatedAt,
+		UpdatedBy:     auditActor(c.UpdatedBy),
+	}
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func csvUUID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
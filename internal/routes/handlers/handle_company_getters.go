@@ -3,41 +3,126 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
+// companyExpansions are the optional parts of a company response a client asked for.
+type companyExpansions struct {
+	meta bool
+}
+
+// HandleGetCompanyByID processes requests to retrieve a company by ID.
+// With an as_of query parameter it retrieves the company as it was at that instant instead.
+// With expand=meta the response includes the company's audit metadata.
+func (h *Handler) HandleGetCompanyByID(w http.ResponseWriter, r *http.Request) {
+	id, pErr := uuid.Parse(r.PathValue("id"))
+	if pErr != nil {
+		h.logger.Warn("Invalid company ID in path", append(h.logger.ReqFields(r), zap.Error(pErr))...)
+		h.writeProblem(w, r, problem.BadRequest("Invalid ID"))
+		return
+	}
+
+	asOf, pErr := parseAsOf(r.URL.Query())
+	if pErr != nil {
+		h.logger.Warn("Invalid as_of in query", append(h.logger.ReqFields(r), zap.Error(pErr))...)
+		h.writeProblem(w, r, problem.BadRequest("Invalid as_of"))
+		return
+	}
+
+	expand, pErr := parseExpand(r.URL.Query(), asOf)
+	if pErr != nil {
+		h.logger.Warn("Invalid expand in query", append(h.logger.ReqFields(r), zap.Error(pErr))...)
+		h.writeProblem(w, r, problem.BadRequest("Invalid expand: "+pErr.Error()))
+		return
+	}
+
+	h.logger.Info("Processing Get Company By ID request", h.logger.ReqFields(r)...)
+
+	var company *domain.Company
+	var err error
+	if asOf != nil {
+		company, err = h.service.Company.GetAsOf(r.Context(), id, *asOf)
+	} else {
+		company, err = h.service.Company.GetByID(r.Context(), id)
+	}
+	h.respondWithFetchedCompany(w, r, company, expand, err)
+}
+
 // HandleGetCompanyByName processes requests to retrieve a company by name.
+// With expand=meta the response includes the company's audit metadata.
 func (h *Handler) HandleGetCompanyByName(w http.ResponseWriter, r *http.Request) {
 	name := r.PathValue("name")
 	if name == "" {
 		h.logger.Warn("Company name missing from path", h.logger.ReqFields(r)...)
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		h.writeProblem(w, r, problem.BadRequest("Missing company name"))
+		return
+	}
+
+	expand, pErr := parseExpand(r.URL.Query(), nil)
+	if pErr != nil {
+		h.logger.Warn("Invalid expand in query", append(h.logger.ReqFields(r), zap.Error(pErr))...)
+		h.writeProblem(w, r, problem.BadRequest("Invalid expand: "+pErr.Error()))
 		return
 	}
 
 	h.logger.Info("Processing Get Company By Name request", h.logger.ReqFields(r)...)
 
 	company, err := h.service.Company.GetByName(r.Context(), name)
+	h.respondWithFetchedCompany(w, r, company, expand, err)
+}
+
+// respondWithFetchedCompany writes the outcome of a single company lookup,
+// shared by the getters so they only differ in how they identify the company.
+// Clients revalidating with If-None-Match or If-Modified-Since get a 304 if the company is unchanged.
+func (h *Handler) respondWithFetchedCompany(
+	w http.ResponseWriter,
+	r *http.Request,
+	company *domain.Company,
+	expand companyExpansions,
+	err error,
+) {
 	if err != nil {
 		h.logger.Info("Failed to get company", append(h.logger.ReqFields(r), zap.Error(err))...)
-		if errors.Is(err, domain.ErrNotFound) {
-			http.Error(w, "Company not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrNotFound:   "Company not found",
+			domain.ErrBadRequest: "as_of cannot be in the future",
+		}))
+		return
+	}
+
+	// Caches may store the company but have to revalidate it on every use
+	w.Header().Set("Cache-Control", "no-cache")
+	etag := companyETag(company)
+	w.Header().Set("ETag", etag)
+	lastModified := companyLastModified(company)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+	if notModified(r, etag, lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		h.logger.Info("Company not modified", h.logger.ReqFields(r)...)
 		return
 	}
 
 	response := convertToCompanyResponse(company)
+	if expand.meta {
+		response.Meta = convertToCompanyMetaResponse(company)
+	}
 
 	respMarshalled, err := json.Marshal(response)
 	if err != nil {
 		h.logger.Error("Failed to marshal response", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		h.writeProblem(w, r, problem.Internal())
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -47,3 +132,40 @@ func (h *Handler) HandleGetCompanyByName(w http.ResponseWriter, r *http.Request)
 	}
 	h.logger.Info("Company Get request processed", h.logger.ReqFields(r)...)
 }
+
+// parseAsOf reads the optional as_of query parameter, an RFC 3339 timestamp.
+// It returns nil if the parameter is absent.
+func parseAsOf(q url.Values) (*time.Time, error) {
+	v := q.Get("as_of")
+	if v == "" {
+		return nil, nil //nolint:nilnil // no as_of means the current state
+	}
+	asOf, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return nil, fmt.Errorf("invalid as_of value: %w", err)
+	}
+	return &asOf, nil
+}
+
+// parseExpand reads the optional expand query parameter, a comma separated list of the optional parts
+// of a company response to include. meta, the audit metadata, is the only one so far and isn't kept
+// for past states, so it can't be combined with as_of.
+func parseExpand(q url.Values, asOf *time.Time) (companyExpansions, error) {
+	var expand companyExpansions
+	v := q.Get("expand")
+	if v == "" {
+		return expand, nil
+	}
+	for part := range strings.SplitSeq(v, ",") {
+		switch strings.TrimSpace(part) {
+		case "meta":
+			expand.meta = true
+		default:
+			return expand, fmt.Errorf("unknown expansion %q", part)
+		}
+	}
+	if expand.meta && asOf != nil {
+		return expand, errors.New("meta cannot be combined with as_of")
+	}
+	return expand, nil
+}
@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// HandleGetCompanyHistory processes requests to list the revisions of a company, newest first.
+// The history of deleted companies stays available. Pages are continued with the returned cursor.
+func (h *Handler) HandleGetCompanyHistory(w http.ResponseWriter, r *http.Request) {
+	id, pErr := uuid.Parse(r.PathValue("id"))
+	if pErr != nil {
+		h.logger.Warn("Invalid company ID in path", append(h.logger.ReqFields(r), zap.Error(pErr))...)
+		h.writeProblem(w, r, problem.BadRequest("Invalid ID"))
+		return
+	}
+
+	h.logger.Info("Processing Get Company History request", h.logger.ReqFields(r)...)
+
+	rQuery, err := parseCompanyHistoryQuery(r.URL.Query())
+	if err != nil {
+		h.logger.Warn("Failed to parse query parameters", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.BadRequest("Malformed query parameters"))
+		return
+	}
+	if err = h.validator.Struct(rQuery); err != nil {
+		h.logger.Warn("Invalid request data", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.Validation(err))
+		return
+	}
+
+	page, err := h.service.Company.History(r.Context(), id, rQuery.Cursor, rQuery.Limit)
+	if err != nil {
+		h.logger.Info("Failed to get company history", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrNotFound:   "Company not found",
+			domain.ErrBadRequest: "Invalid listing parameters",
+		}))
+		return
+	}
+
+	response := CompanyHistoryResponse{
+		Revisions: make([]CompanyRevisionResponse, 0, len(page.Revisions)),
+	}
+	for _, rev := range page.Revisions {
+		response.Revisions = append(response.Revisions, CompanyRevisionResponse{
+			Revision:  rev.Revision,
+			Operation: string(rev.Operation),
+			Actor:     rev.Actor,
+			ChangedAt: rev.ChangedAt,
+			Reason:    rev.Reason,
+			Company:   convertToCompanyResponse(rev.Company),
+		})
+	}
+	if page.Next != nil {
+		next := strconv.FormatInt(*page.Next, 10)
+		response.NextCursor = &next
+	}
+
+	respMarshalled, err := json.Marshal(response)
+	if err != nil {
+		h.logger.Error("Failed to marshal response", zap.Error(err))
+		h.writeProblem(w, r, problem.Internal())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(respMarshalled); err != nil {
+		h.logger.Error("Failed to write response", zap.Error(err))
+	}
+	h.logger.Info("Company History request processed", h.logger.ReqFields(r)...)
+}
+
+// HandleGetCompanyDiff processes requests to compare two revisions of a company field by field.
+func (h *Handler) HandleGetCompanyDiff(w http.ResponseWriter, r *http.Request) {
+	id, pErr := uuid.Parse(r.PathValue("id"))
+	if pErr != nil {
+		h.logger.Warn("Invalid company ID in path", append(h.logger.ReqFields(r), zap.Error(pErr))...)
+		h.writeProblem(w, r, problem.BadRequest("Invalid ID"))
+		return
+	}
+
+	h.logger.Info("Processing Get Company Diff request", h.logger.ReqFields(r)...)
+
+	rQuery, err := parseCompanyDiffQuery(r.URL.Query())
+	if err != nil {
+		h.logger.Warn("Failed to parse query parameters", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.BadRequest("Malformed query parameters"))
+		return
+	}
+	if err = h.validator.Struct(rQuery); err != nil {
+		h.logger.Warn("Invalid request data", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.Validation(err))
+		return
+	}
+
+	changes, err := h.service.Company.Diff(r.Context(), id, rQuery.From, rQuery.To)
+	if err != nil {
+		h.logger.Info("Failed to diff company revisions", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrNotFound: "Company revision not found",
+		}))
+		return
+	}
+
+	response := CompanyDiffResponse{
+		From:    rQuery.From,
+		To:      rQuery.To,
+		Changes: make([]CompanyFieldChangeResponse, 0, len(changes)),
+	}
+	for _, c := range changes {
+		response.Changes = append(response.Changes, CompanyFieldChangeResponse{
+			Field: c.Field,
+			From:  c.From,
+			To:    c.To,
+		})
+	}
+
+	respMarshalled, err := json.Marshal(response)
+	if err != nil {
+		h.logger.Error("Failed to marshal response", zap.Error(err))
+		h.writeProblem(w, r, problem.Internal())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(respMarshalled); err != nil {
+		h.logger.Error("Failed to write response", zap.Error(err))
+	}
+	h.logger.Info("Company Diff request processed", h.logger.ReqFields(r)...)
+}
+
+// parseCompanyHistoryQuery converts the raw query string into a CompanyHistoryRequest.
+// The cursor is the revision the page starts below.
+func parseCompanyHistoryQuery(q url.Values) (CompanyHistoryRequest, error) {
+	var rQuery CompanyHistoryRequest
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return rQuery, fmt.Errorf("invalid limit value: %w", err)
+		}
+		rQuery.Limit = int32(limit)
+	}
+	if v := q.Get("cursor"); v != "" {
+		cursor, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return rQuery, fmt.Errorf("invalid cursor value: %w", err)
+		}
+		rQuery.Cursor = &cursor
+	}
+	return rQuery, nil
+}
+
+// parseCompanyDiffQuery converts the raw query string into a CompanyDiffRequest.
+// Missing revisions are left zero for the validator to report.
+func parseCompanyDiffQuery(q url.Values) (CompanyDiffRequest, error) {
+	var rQuery CompanyDiffRequest
+	if v := q.Get("from"); v != "" {
+		from, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return rQuery, fmt.Errorf("invalid from value: %w", err)
+		}
+		rQuery.From = from
+	}
+	if v := q.Get("to"); v != "" {
+		to, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return rQuery, fmt.Errorf("invalid to value: %w", err)
+		}
+		rQuery.To = to
+	}
+	return rQuery, nil
+}
@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
+	"github.com/Laelapa/CompanyRegistry/internal/service"
+	"github.com/Laelapa/CompanyRegistry/util/ctxutils"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// maxImportBodySize bounds the size of an import upload
+	maxImportBodySize = 10 << 20
+	// Longest NDJSON line accepted, a company's description alone may take 3000 characters
+	maxImportLineSize = 64 << 10
+)
+
+// errTooManyImportRows stops reading an upload with more rows than an import may have.
+var errTooManyImportRows = fmt.Errorf("import cannot exceed %d rows", service.MaxCompanyImportRows)
+
+// importColumns are the CSV columns an import may have, by the CreateCompanyRequest field they set.
+var importColumns = []string{"name", "description", "employee_count", "registered", "company_type"}
+
+// HandleImportCompanies processes requests to create companies in bulk from a CSV or NDJSON upload.
+// It expects a userID in the request context - set by the jwt authentication middleware.
+// The upload's format is taken from its Content-Type. CSV uploads start with a header row naming
+// their columns, NDJSON uploads carry one company per line, in the shape of a create request.
+// Every row is validated like a create request and the outcome of each is reported.
+// The mode query parameter decides what happens when some rows can't be created, see domain.CompanyImportMode.
+// A failed all-or-nothing import is answered with 422 and the report of what went wrong.
+func (h *Handler) HandleImportCompanies(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Processing Import Companies request", h.logger.ReqFields(r)...)
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		mediaType = ""
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportBodySize)
+	var rows []*domain.CompanyImportRow
+	switch mediaType {
+	case "text/csv":
+		rows, err = h.readCSVImport(body)
+	case "application/x-ndjson", "application/ndjson":
+		rows, err = h.readNDJSONImport(body)
+	default:
+		h.logger.Warn("Unsupported import media type", h.logger.ReqFields(r)...)
+		h.writeProblem(w, r, problem.New(http.StatusUnsupportedMediaType, problem.CodeUnsupportedMediaType,
+			"Imports must be text/csv or application/x-ndjson"))
+		return
+	}
+	if err != nil {
+		h.logger.Warn("Failed to read import", append(h.logger.ReqFields(r), zap.Error(err))...)
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			h.writeProblem(w, r, problem.New(http.StatusRequestEntityTooLarge, problem.CodePayloadTooLarge,
+				fmt.Sprintf("Imports cannot exceed %d bytes", maxImportBodySize)))
+		case errors.Is(err, errTooManyImportRows):
+			h.writeProblem(w, r, problem.BadRequest(
+				fmt.Sprintf("Imports cannot exceed %d rows", service.MaxCompanyImportRows)))
+		default:
+			h.writeProblem(w, r, problem.BadRequest("Malformed import: "+err.Error()))
+		}
+		return
+	}
+
+	userID, ok := ctxutils.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("Failed to get user ID from context", h.logger.ReqFields(r)...)
+		h.writeProblem(w, r, problem.Unauthorized())
+		return
+	}
+
+	mode := domain.CompanyImportMode(r.URL.Query().Get("mode"))
+	report, err := h.service.Company.Import(r.Context(), userID, rows, mode)
+	if err != nil {
+		h.logger.Error("Failed to import companies", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrBadRequest: "Invalid import",
+		}))
+		return
+	}
+	if mode == "" {
+		mode = domain.CompanyImportAllOrNothing
+	}
+
+	response := convertToCompanyImportResponse(report, mode)
+
+	respMarshalled, err := json.Marshal(response)
+	if err != nil {
+		h.logger.Error("Failed to marshal response", zap.Error(err))
+		h.writeProblem(w, r, problem.Internal())
+		return
+	}
+	status := http.StatusOK
+	if mode == domain.CompanyImportAllOrNothing && !report.Committed {
+		status = http.StatusUnprocessableEntity
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(respMarshalled); err != nil {
+		h.logger.Error("Failed to write response", zap.Error(err))
+	}
+	h.logger.Info("Company import request processed successfully", h.logger.ReqFields(r)...)
+}
+
+// readCSVImport reads the rows of a CSV upload. Empty cells leave their field unset.
+// Rows with the wrong number of cells or unparsable values are marked invalid, an unusable header
+// or malformed CSV fails the whole upload.
+func (h *Handler) readCSVImport(body io.Reader) ([]*domain.CompanyImportRow, error) {
+	cr := csv.NewReader(body)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("missing header row")
+	}
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(header))
+	for i, col := range header {
+		col = strings.ToLower(strings.TrimSpace(col))
+		if !slices.Contains(importColumns, col) {
+			return nil, fmt.Errorf("unknown column %q", col)
+		}
+		if seen[col] {
+			return nil, fmt.Errorf("duplicate column %q", col)
+		}
+		seen[col] = true
+		header[i] = col
+	}
+
+	var rows []*domain.CompanyImportRow
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if len(rows) == service.MaxCompanyImportRows {
+			return nil, errTooManyImportRows
+		}
+		row := &domain.CompanyImportRow{Row: len(rows) + 1}
+		rows = append(rows, row)
+
+		if errors.Is(err, csv.ErrFieldCount) {
+			markImportRowInvalid(row, domain.CompanyImportProblem{
+				Rule:    "columns",
+				Message: fmt.Sprintf("row has %d cells, the header %d", len(record), len(header)),
+			})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var req CreateCompanyRequest
+		for i, cell := range record {
+			if cell == "" {
+				continue
+			}
+			if p := setImportField(&req, header[i], cell); p != nil {
+				row.Problems = append(row.Problems, *p)
+			}
+		}
+		if len(row.Problems) > 0 {
+			row.Status = domain.CompanyImportInvalid
+			continue
+		}
+		h.prepareImportRow(row, req)
+	}
+}
+
+// readNDJSONImport reads the rows of an NDJSON upload, skipping blank lines.
+// Lines that aren't a JSON object are marked invalid.
+func (h *Handler) readNDJSONImport(body io.Reader) ([]*domain.CompanyImportRow, error) {
+	sc := bufio.NewScanner(body)
+	sc.Buffer(make([]byte, 0, 4096), maxImportLineSize)
+
+	var rows []*domain.CompanyImportRow
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		if len(rows) == service.MaxCompanyImportRows {
+			return nil, errTooManyImportRows
+		}
+		row := &domain.CompanyImportRow{Row: len(rows) + 1}
+		rows = append(rows, row)
+
+		var req CreateCompanyRequest
+		if err := json.Unmarshal([]byte(line), &req); err != nil {
+			markImportRowInvalid(row, domain.CompanyImportProblem{Rule: "json", Message: "line is not a valid company"})
+			continue
+		}
+		h.prepareImportRow(row, req)
+	}
+	return rows, sc.Err()
+}
+
+// prepareImportRow validates a parsed row like a create request, setting the company to create
+// or marking the row invalid.
+func (h *Handler) prepareImportRow(row *domain.CompanyImportRow, req CreateCompanyRequest) {
+	if err := h.validator.Struct(req); err != nil {
+		row.Status = domain.CompanyImportInvalid
+		for _, fe := range problem.Validation(err).Errors {
+			row.Problems = append(row.Problems, domain.CompanyImportProblem(fe))
+		}
+		return
+	}
+
+	companyType := domain.CompanyType(req.CompanyType)
+	status := domain.CompanyStatusPending
+	row.Company = &domain.Company{
+		Name:          &req.Name,
+		Description:   req.Description,
+		EmployeeCount: req.EmployeeCount,
+		Status:        &status,
+		CompanyType:   &companyType,
+	}
+}
+
+// setImportField sets the field a CSV column stands for, reporting cells that can't be parsed.
+func setImportField(req *CreateCompanyRequest, column, cell string) *domain.CompanyImportProblem {
+	switch column {
+	case "name":
+		req.Name = cell
+	case "description":
+		req.Description = &cell
+	case "employee_count":
+		n, err := strconv.ParseInt(cell, 10, 32)
+		if err != nil {
+			return &domain.CompanyImportProblem{
+				Field: column, Rule: "integer", Message: column + " must be a whole number",
+			}
+		}
+		count := int32(n)
+		req.EmployeeCount = &count
+	case "registered":
+		b, err := strconv.ParseBool(cell)
+		if err != nil {
+			return &domain.CompanyImportProblem{
+				Field: column, Rule: "boolean", Message: column + " must be true or false",
+			}
+		}
+		req.Registered = &b
+	case "company_type":
+		req.CompanyType = cell
+	}
+	return nil
+}
+
+func markImportRowInvalid(row *domain.CompanyImportRow, p domain.CompanyImportProblem) {
+	row.Status = domain.CompanyImportInvalid
+	row.Problems = append(row.Problems, p)
+}
+
+func convertToCompanyImportResponse(
+	report *domain.CompanyImportReport,
+	mode domain.CompanyImportMode,
+) CompanyImportResponse {
+	rows := make([]CompanyImportRowResponse, 0, len(report.Rows))
+	for _, row := range report.Rows {
+		rr := CompanyImportRowResponse{
+			Row:    row.Row,
+			Status: string(row.Status),
+		}
+		if row.Company != nil {
+			rr.Name = row.Company.Name
+			// Companies of an import that wasn't committed don't exist
+			if report.Committed {
+				rr.ID = row.Company.ID
+			}
+		}
+		for _, p := range row.Problems {
+			rr.Errors = append(rr.Errors, problem.FieldError(p))
+		}
+		rows = append(rows, rr)
+	}
+	return CompanyImportResponse{
+		Mode:      string(mode),
+		Committed: report.Committed,
+		Created:   report.Created,
+		Skipped:   report.Skipped,
+		Invalid:   report.Invalid,
+		Rows:      rows,
+	}
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// companyCursorToken is the wire format of domain.CompanyCursor.
+// Clients only ever see it base64 encoded and should treat it as opaque.
+type companyCursorToken struct {
+	ID            uuid.UUID `json:"id"`
+	Name          string    `json:"n"`
+	EmployeeCount int32     `json:"e"`
+	CreatedAt     time.Time `json:"c"`
+}
+
+// HandleListCompanies processes requests to list companies page by page.
+// Filters, sorting and the page cursor are all read from the query string.
+// With expand=meta each company includes its audit metadata.
+func (h *Handler) HandleListCompanies(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Processing List Companies request", h.logger.ReqFields(r)...)
+
+	rQuery, err := parseListCompaniesQuery(r.URL.Query())
+	if err != nil {
+		h.logger.Warn("Failed to parse query parameters", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.BadRequest("Malformed query parameters"))
+		return
+	}
+
+	if err = h.validator.Struct(rQuery); err != nil {
+		h.logger.Warn("Invalid request data", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.Validation(err))
+		return
+	}
+
+	expand, err := parseExpand(r.URL.Query(), rQuery.AsOf)
+	if err != nil {
+		h.logger.Warn("Invalid expand in query", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.BadRequest("Invalid expand: "+err.Error()))
+		return
+	}
+
+	params := domain.CompanyListParams{
+		Filter:     convertToCompanyFilter(rQuery.CompanyFilterRequest),
+		SortBy:     domain.CompanySortField(rQuery.SortBy),
+		Descending: rQuery.Order == "desc",
+		Limit:      rQuery.Limit,
+		AsOf:       rQuery.AsOf,
+	}
+	if rQuery.Cursor != "" {
+		params.After, err = decodeCompanyCursor(rQuery.Cursor)
+		if err != nil {
+			h.logger.Warn("Invalid page cursor", append(h.logger.ReqFields(r), zap.Error(err))...)
+			h.writeProblem(w, r, problem.BadRequest("Invalid cursor"))
+			return
+		}
+	}
+
+	page, err := h.service.Company.List(r.Context(), params)
+	if err != nil {
+		h.logger.Error("Failed to list companies", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrBadRequest: "Invalid listing parameters",
+		}))
+		return
+	}
+
+	response := CompanyListResponse{
+		Companies: make([]CompanyResponse, 0, len(page.Companies)),
+	}
+	for _, c := range page.Companies {
+		resp := convertToCompanyResponse(c)
+		if expand.meta {
+			resp.Meta = convertToCompanyMetaResponse(c)
+		}
+		response.Companies = append(response.Companies, resp)
+	}
+	if page.Next != nil {
+		next, cErr := encodeCompanyCursor(page.Next)
+		if cErr != nil {
+			h.logger.Error("Failed to encode page cursor", zap.Error(cErr))
+			h.writeProblem(w, r, problem.Internal())
+			return
+		}
+		response.NextCursor = &next
+	}
+
+	respMarshalled, err := json.Marshal(response)
+	if err != nil {
+		h.logger.Error("Failed to marshal response", zap.Error(err))
+		h.writeProblem(w, r, problem.Internal())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(respMarshalled); err != nil {
+		h.logger.Error("Failed to write response", zap.Error(err))
+	}
+
+	h.logger.Info("Company List request processed", h.logger.ReqFields(r)...)
+}
+
+// parseListCompaniesQuery converts the raw query string into a ListCompaniesRequest.
+// It only checks that values are well-formed, range checks are left to the validator.
+func parseListCompaniesQuery(q url.Values) (ListCompaniesRequest, error) {
+	rQuery := ListCompaniesRequest{
+		SortBy: q.Get("sort_by"),
+		Order:  q.Get("order"),
+		Cursor: q.Get("cursor"),
+	}
+
+	filter, err := parseCompanyFilterQuery(q)
+	if err != nil {
+		return rQuery, err
+	}
+	rQuery.CompanyFilterRequest = filter
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return rQuery, fmt.Errorf("invalid limit value: %w", err)
+		}
+		rQuery.Limit = int32(limit)
+	}
+	asOf, err := parseAsOf(q)
+	if err != nil {
+		return rQuery, err
+	}
+	rQuery.AsOf = asOf
+
+	return rQuery, nil
+}
+
+// parseCompanyFilterQuery converts the filtering parameters of the raw query string into a CompanyFilterRequest.
+func parseCompanyFilterQuery(q url.Values) (CompanyFilterRequest, error) {
+	var rQuery CompanyFilterRequest
+
+	if v := q.Get("company_type"); v != "" {
+		rQuery.CompanyType = &v
+	}
+	if v := q.Get("registered"); v != "" {
+		registered, err := strconv.ParseBool(v)
+		if err != nil {
+			return rQuery, fmt.Errorf("invalid registered value: %w", err)
+		}
+		rQuery.Registered = &registered
+	}
+	if v := q.Get("min_employees"); v != "" {
+		minCount, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return rQuery, fmt.Errorf("invalid min_employees value: %w", err)
+		}
+		minCount32 := int32(minCount)
+		rQuery.MinEmployeeCount = &minCount32
+	}
+	if v := q.Get("max_employees"); v != "" {
+		maxCount, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return rQuery, fmt.Errorf("invalid max_employees value: %w", err)
+		}
+		maxCount32 := int32(maxCount)
+		rQuery.MaxEmployeeCount = &maxCount32
+	}
+	if v := q.Get("created_by"); v != "" {
+		createdBy, err := uuid.Parse(v)
+		if err != nil {
+			return rQuery, fmt.Errorf("invalid created_by value: %w", err)
+		}
+		rQuery.CreatedBy = &createdBy
+	}
+
+	return rQuery, nil
+}
+
+func convertToCompanyFilter(f CompanyFilterRequest) domain.CompanyFilter {
+	filter := domain.CompanyFilter{
+		Registered:       f.Registered,
+		MinEmployeeCount: f.MinEmployeeCount,
+		MaxEmployeeCount: f.MaxEmployeeCount,
+		CreatedBy:        f.CreatedBy,
+	}
+	if f.CompanyType != nil {
+		ct := domain.CompanyType(*f.CompanyType)
+		filter.CompanyType = &ct
+	}
+	return filter
+}
+
+func encodeCompanyCursor(c *domain.CompanyCursor) (string, error) {
+	raw, err := json.Marshal(companyCursorToken{
+		ID:            c.ID,
+		Name:          c.Name,
+		EmployeeCount: c.EmployeeCount,
+		CreatedAt:     c.CreatedAt,
+	})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func decodeCompanyCursor(s string) (*domain.CompanyCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("cursor is not valid base64: %w", err)
+	}
+
+	var token companyCursorToken
+	if err = json.Unmarshal(raw, &token); err != nil {
+		return nil, fmt.Errorf("cursor is malformed: %w", err)
+	}
+	if token.ID == uuid.Nil {
+		return nil, errors.New("cursor is missing its company ID")
+	}
+
+	return &domain.CompanyCursor{
+		ID:            token.ID,
+		Name:          token.Name,
+		EmployeeCount: token.EmployeeCount,
+		CreatedAt:     token.CreatedAt,
+	}, nil
+}
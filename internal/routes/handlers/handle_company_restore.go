@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Laelapa/CompanyRegistry/internal/authz"
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// HandleRestoreCompany processes requests to restore a deleted company that hasn't been purged yet.
+// It expects a userID and role in the request context - set by the jwt authentication middleware.
+// Only the company's creator, editors and admins may restore it.
+func (h *Handler) HandleRestoreCompany(w http.ResponseWriter, r *http.Request) {
+	id, pErr := uuid.Parse(r.PathValue("id"))
+	if pErr != nil {
+		h.logger.Warn("Invalid company ID in path", append(h.logger.ReqFields(r), zap.Error(pErr))...)
+		h.writeProblem(w, r, problem.BadRequest("Invalid ID"))
+		return
+	}
+
+	h.logger.Info("Processing Restore Company request", h.logger.ReqFields(r)...)
+
+	principal, ok := authz.PrincipalFromContext(r.Context())
+	if !ok {
+		h.logger.Error("Failed to get user ID from context", h.logger.ReqFields(r)...)
+		h.writeProblem(w, r, problem.Unauthorized())
+		return
+	}
+
+	restoredCompany, err := h.service.Company.Restore(r.Context(), principal, id)
+	if err != nil {
+		h.logger.Error("Failed to restore company", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrNotFound: "Deleted company not found",
+			domain.ErrConflict: "Company name has been taken by another company",
+		}))
+		return
+	}
+
+	response := convertToCompanyResponse(restoredCompany)
+
+	respMarshalled, err := json.Marshal(response)
+	if err != nil {
+		h.logger.Error("Failed to marshal response", zap.Error(err))
+		h.writeProblem(w, r, problem.Internal())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", companyETag(restoredCompany))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(respMarshalled); err != nil {
+		h.logger.Error("Failed to write response", zap.Error(err))
+	}
+	h.logger.Info("Company restored successfully", h.logger.ReqFields(r)...)
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
+
+	"go.uber.org/zap"
+)
+
+// HandleSearchCompanies processes full-text/fuzzy company search requests.
+// It expects the search terms in the q query parameter and an optional result limit.
+func (h *Handler) HandleSearchCompanies(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		h.logger.Warn("Search query missing", h.logger.ReqFields(r)...)
+		h.writeProblem(w, r, problem.BadRequest("Missing search query"))
+		return
+	}
+
+	var limit int32
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			h.logger.Warn("Invalid search limit", append(h.logger.ReqFields(r), zap.Error(err))...)
+			h.writeProblem(w, r, problem.BadRequest("Invalid limit"))
+			return
+		}
+		limit = int32(parsed)
+	}
+
+	h.logger.Info("Processing Search Companies request", h.logger.ReqFields(r)...)
+
+	hits, err := h.service.Company.Search(r.Context(), query, limit)
+	if err != nil {
+		h.logger.Error("Failed to search companies", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrBadRequest: "Invalid search parameters",
+		}))
+		return
+	}
+
+	response := CompanySearchResponse{
+		Results: make([]CompanySearchResult, 0, len(hits)),
+	}
+	for _, hit := range hits {
+		response.Results = append(response.Results, CompanySearchResult{
+			Company: convertToCompanyResponse(hit.Company),
+			Rank:    hit.Rank,
+			Snippet: hit.Snippet,
+		})
+	}
+
+	respMarshalled, err := json.Marshal(response)
+	if err != nil {
+		h.logger.Error("Failed to marshal response", zap.Error(err))
+		h.writeProblem(w, r, problem.Internal())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(respMarshalled); err != nil {
+		h.logger.Error("Failed to write response", zap.Error(err))
+	}
+
+	h.logger.Info("Company Search request processed", h.logger.ReqFields(r)...)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Laelapa/CompanyRegistry/internal/authz"
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// HandleTransitionCompany processes requests to put a company through a lifecycle action, such as approving
+// or suspending it. The action's reason is recorded in the company's history.
+// It expects a userID and role in the request context - set by the jwt authentication middleware.
+// Only editors and admins may transition companies, creators can't approve their own.
+// Actions that aren't allowed from the company's current status fail with 409.
+// An If-Match header makes the transition conditional on the company's ETag, failing with 412 if it has changed.
+func (h *Handler) HandleTransitionCompany(w http.ResponseWriter, r *http.Request) {
+	id, pErr := uuid.Parse(r.PathValue("id"))
+	if pErr != nil {
+		h.logger.Warn("Invalid company ID in path", append(h.logger.ReqFields(r), zap.Error(pErr))...)
+		h.writeProblem(w, r, problem.BadRequest("Invalid ID"))
+		return
+	}
+
+	var rBody TransitionCompanyRequest
+	h.logger.Info("Processing Transition Company request", h.logger.ReqFields(r)...)
+
+	if err := json.NewDecoder(r.Body).Decode(&rBody); err != nil {
+		h.logger.Warn("Failed to decode request body", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.BadRequest("Malformed request body"))
+		return
+	}
+
+	if err := h.validator.Struct(rBody); err != nil {
+		h.logger.Warn("Invalid request data", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.Validation(err))
+		return
+	}
+
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		h.logger.Warn("Invalid If-Match header", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrBadRequest: "Invalid If-Match header",
+		}))
+		return
+	}
+
+	principal, ok := authz.PrincipalFromContext(r.Context())
+	if !ok {
+		h.logger.Error("Failed to get user ID from context", h.logger.ReqFields(r)...)
+		h.writeProblem(w, r, problem.Unauthorized())
+		return
+	}
+
+	transitioned, err := h.service.Company.Transition(
+		r.Context(), principal, id, domain.CompanyAction(rBody.Action), rBody.Reason, expectedVersion,
+	)
+	if err != nil {
+		h.logger.Error("Failed to transition company", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrNotFound:           "Company not found",
+			domain.ErrBadRequest:         "Invalid transition data",
+			domain.ErrInvalidTransition:  "Company's current status does not allow " + rBody.Action,
+			domain.ErrPreconditionFailed: "Company has been modified since it was read",
+		}))
+		return
+	}
+
+	response := convertToCompanyResponse(transitioned)
+
+	respMarshalled, err := json.Marshal(response)
+	if err != nil {
+		h.logger.Error("Failed to marshal response", zap.Error(err))
+		h.writeProblem(w, r, problem.Internal())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", companyETag(transitioned))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(respMarshalled); err != nil {
+		h.logger.Error("Failed to write response", zap.Error(err))
+	}
+	h.logger.Info("Company transitioned successfully", h.logger.ReqFields(r)...)
+}
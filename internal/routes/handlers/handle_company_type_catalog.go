@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
+
+	"go.uber.org/zap"
+)
+
+// HandleListCompanyTypes processes requests to list the company types companies can be given.
+// Retired types are only included with include_inactive=true.
+func (h *Handler) HandleListCompanyTypes(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Processing List Company Types request", h.logger.ReqFields(r)...)
+
+	includeInactive := false
+	if v := r.URL.Query().Get("include_inactive"); v != "" {
+		var err error
+		if includeInactive, err = strconv.ParseBool(v); err != nil {
+			h.logger.Warn("Failed to parse query parameters", append(h.logger.ReqFields(r), zap.Error(err))...)
+			h.writeProblem(w, r, problem.BadRequest("Malformed query parameters"))
+			return
+		}
+	}
+
+	types, err := h.service.CompanyType.List(r.Context(), includeInactive)
+	if err != nil {
+		h.logger.Error("Failed to list company types", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.Internal())
+		return
+	}
+
+	response := CompanyTypeListResponse{
+		CompanyTypes: make([]CompanyTypeResponse, 0, len(types)),
+	}
+	for _, t := range types {
+		response.CompanyTypes = append(response.CompanyTypes, convertToCompanyTypeResponse(t))
+	}
+
+	h.writeCompanyTypeResponse(w, r, http.StatusOK, response)
+	h.logger.Info("Company Type List request processed", h.logger.ReqFields(r)...)
+}
+
+// HandleCreateCompanyType processes requests to add a company type.
+// Authorization is left to the authorization middleware guarding the route.
+func (h *Handler) HandleCreateCompanyType(w http.ResponseWriter, r *http.Request) {
+	var rBody CreateCompanyTypeRequest
+	h.logger.Info("Processing Create Company Type request", h.logger.ReqFields(r)...)
+
+	if err := json.NewDecoder(r.Body).Decode(&rBody); err != nil {
+		h.logger.Warn("Failed to decode request body", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.BadRequest("Malformed request body"))
+		return
+	}
+
+	if err := h.validator.Struct(rBody); err != nil {
+		h.logger.Warn("Invalid request data", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.Validation(err))
+		return
+	}
+
+	name := domain.CompanyType(rBody.Name)
+	createdType, err := h.service.CompanyType.Create(r.Context(), &domain.CompanyTypeDefinition{
+		Name:        &name,
+		Description: rBody.Description,
+	})
+	if err != nil {
+		h.logger.Error("Failed to create company type", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrConflict:   "Company type already exists",
+			domain.ErrBadRequest: "Invalid company type data",
+		}))
+		return
+	}
+
+	h.writeCompanyTypeResponse(w, r, http.StatusCreated, convertToCompanyTypeResponse(createdType))
+	h.logger.Info("Company type created successfully", h.logger.ReqFields(r)...)
+}
+
+// HandleUpdateCompanyType processes requests to change a company type, or to retire or reinstate it.
+// Authorization is left to the authorization middleware guarding the route.
+func (h *Handler) HandleUpdateCompanyType(w http.ResponseWriter, r *http.Request) {
+	name := domain.CompanyType(r.PathValue("name"))
+
+	var rBody UpdateCompanyTypeRequest
+	h.logger.Info("Processing Update Company Type request", h.logger.ReqFields(r)...)
+
+	if err := json.NewDecoder(r.Body).Decode(&rBody); err != nil {
+		h.logger.Warn("Failed to decode request body", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.BadRequest("Malformed request body"))
+		return
+	}
+
+	if err := h.validator.Struct(rBody); err != nil {
+		h.logger.Warn("Invalid request data", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.Validation(err))
+		return
+	}
+
+	updatedType, err := h.service.CompanyType.Update(r.Context(), &domain.CompanyTypeDefinition{
+		Name:        &name,
+		Description: rBody.Description,
+		Active:      rBody.Active,
+	})
+	if err != nil {
+		h.logger.Error("Failed to update company type", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrNotFound:   "Company type not found",
+			domain.ErrBadRequest: "Invalid company type data",
+		}))
+		return
+	}
+
+	h.writeCompanyTypeResponse(w, r, http.StatusOK, convertToCompanyTypeResponse(updatedType))
+	h.logger.Info("Company type updated successfully", h.logger.ReqFields(r)...)
+}
+
+// HandleDeleteCompanyType processes requests to remove a company type.
+// Authorization is left to the authorization middleware guarding the route.
+// Types still given to a company can't be removed, only retired.
+func (h *Handler) HandleDeleteCompanyType(w http.ResponseWriter, r *http.Request) {
+	name := domain.CompanyType(r.PathValue("name"))
+	h.logger.Info("Processing Delete Company Type request", h.logger.ReqFields(r)...)
+
+	if err := h.service.CompanyType.Delete(r.Context(), name); err != nil {
+		h.logger.Error("Failed to delete company type", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrNotFound: "Company type not found",
+			domain.ErrConflict: "Company type is still in use, retire it instead",
+		}))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	h.logger.Info("Successfully deleted company type", h.logger.ReqFields(r)...)
+}
+
+func (h *Handler) writeCompanyTypeResponse(w http.ResponseWriter, r *http.Request, status int, response any) {
+	respMarshalled, err := json.Marshal(response)
+	if err != nil {
+		h.logger.Error("Failed to marshal response", zap.Error(err))
+		h.writeProblem(w, r, problem.Internal())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if _, err := w.Write(respMarshalled); err != nil {
+		h.logger.Error("Failed to write response", zap.Error(err))
+	}
+}
@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"time"
+
 	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
 
 	"github.com/google/uuid"
 )
@@ -10,16 +13,65 @@ type CreateCompanyRequest struct {
 	Name          string  `json:"name"           validate:"required,max=15"`
 	Description   *string `json:"description"    validate:"omitempty,max=3000"`
 	EmployeeCount *int32  `json:"employee_count" validate:"required,gte=0"`
-	Registered    *bool   `json:"registered"     validate:"required"`
-	CompanyType   string  `json:"company_type"   validate:"required,oneof='Corporation' 'NonProfit' 'Cooperative' 'Sole Proprietorship'"`
+	Registered    *bool   `json:"registered"     validate:"omitempty,eq=false"` // companies start pending
+	CompanyType   string  `json:"company_type"   validate:"required,max=20"`
 }
 
 type UpdateCompanyRequest struct {
 	Name          *string `json:"name"           validate:"omitempty,max=15"`
 	Description   *string `json:"description"    validate:"omitempty,max=3000"`
 	EmployeeCount *int32  `json:"employee_count" validate:"omitempty,gte=0"`
-	Registered    *bool   `json:"registered"     validate:"omitempty"`
-	CompanyType   *string `json:"company_type"   validate:"omitempty,oneof='Corporation' 'NonProfit' 'Cooperative' 'Sole Proprietorship'"`
+	Registered    *bool   `json:"registered"` // must agree with the status, which only changes through transitions
+	CompanyType   *string `json:"company_type"   validate:"omitempty,max=20"`
+}
+
+// TransitionCompanyRequest is a lifecycle action to put a company through.
+type TransitionCompanyRequest struct {
+	Action string `json:"action" validate:"required,oneof=approve reject suspend reinstate liquidate dissolve"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// CompanyFilterRequest holds the query parameters that select companies, shared by listings and exports.
+type CompanyFilterRequest struct {
+	CompanyType      *string    `query:"company_type"  validate:"omitempty,max=20"` // retired types included
+	Registered       *bool      `query:"registered"    validate:"omitempty"`
+	MinEmployeeCount *int32     `query:"min_employees" validate:"omitempty,gte=0"`
+	MaxEmployeeCount *int32     `query:"max_employees" validate:"omitempty,gte=0"`
+	CreatedBy        *uuid.UUID `query:"created_by"    validate:"omitempty"`
+}
+
+// ListCompaniesRequest holds the query parameters of a company listing.
+type ListCompaniesRequest struct {
+	CompanyFilterRequest
+
+	SortBy string     `query:"sort_by" validate:"omitempty,oneof=created_at name employee_count"`
+	Order  string     `query:"order"   validate:"omitempty,oneof=asc desc"`
+	Limit  int32      `query:"limit"   validate:"omitempty,gte=1,lte=100"`
+	Cursor string     `query:"cursor"  validate:"omitempty,base64rawurl"`
+	AsOf   *time.Time `query:"as_of"   validate:"omitempty"`
+}
+
+// CompanyHistoryRequest holds the query parameters of a company history listing.
+type CompanyHistoryRequest struct {
+	Limit  int32  `query:"limit"  validate:"omitempty,gte=1,lte=100"`
+	Cursor *int64 `query:"cursor" validate:"omitempty,gte=1"`
+}
+
+// CompanyDiffRequest holds the query parameters of a comparison between two revisions.
+type CompanyDiffRequest struct {
+	From int64 `query:"from" validate:"required,gte=1"`
+	To   int64 `query:"to"   validate:"required,gte=1"`
+}
+
+type CreateCompanyTypeRequest struct {
+	Name        string  `json:"name"        validate:"required,max=20"`
+	Description *string `json:"description" validate:"omitempty,max=300"`
+}
+
+// UpdateCompanyTypeRequest changes a company type, setting active to false retires it.
+type UpdateCompanyTypeRequest struct {
+	Description *string `json:"description" validate:"omitempty,max=300"`
+	Active      *bool   `json:"active"      validate:"omitempty"`
 }
 
 type CompanyResponse struct {
@@ -27,8 +79,115 @@ type CompanyResponse struct {
 	Name          string    `json:"name"`
 	Description   *string   `json:"description,omitempty"`
 	EmployeeCount int32     `json:"employee_count"`
-	Registered    bool      `json:"registered"`
+	Status        string    `json:"status"`
+	Registered    bool      `json:"registered"` // derived from Status
 	CompanyType   string    `json:"company_type"`
+	// Meta is only included when requested with expand=meta
+	Meta *CompanyMetaResponse `json:"meta,omitempty"`
+}
+
+// CompanyMetaResponse is the audit metadata of a company.
+type CompanyMetaResponse struct {
+	Version   int64                 `json:"version"`
+	CreatedAt *time.Time            `json:"created_at"`
+	CreatedBy *CompanyActorResponse `json:"created_by"`
+	UpdatedAt *time.Time            `json:"updated_at"`
+	UpdatedBy *CompanyActorResponse `json:"updated_by"`
+}
+
+// CompanyActorResponse is a user who acted on a company. Username is empty if the user no longer exists.
+type CompanyActorResponse struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username,omitempty"`
+}
+
+// CompanyExportRecord is a company as exported, including the audit fields CompanyResponse leaves out.
+// Its JSON names are also the CSV column names.
+type CompanyExportRecord struct {
+	ID            uuid.UUID  `json:"id"`
+	Name          string     `json:"name"`
+	Description   *string    `json:"description"`
+	EmployeeCount int32      `json:"employee_count"`
+	Status        string     `json:"status"`
+	Registered    bool       `json:"registered"`
+	CompanyType   string     `json:"company_type"`
+	Version       int64      `json:"version"`
+	CreatedAt     *time.Time `json:"created_at"`
+	CreatedBy     *uuid.UUID `json:"created_by"`
+	UpdatedAt     *time.Time `json:"updated_at"`
+	UpdatedBy     *uuid.UUID `json:"updated_by"`
+}
+
+type CompanyListResponse struct {
+	Companies  []CompanyResponse `json:"companies"`
+	NextCursor *string           `json:"next_cursor,omitempty"`
+}
+
+// CompanyImportRowResponse is the outcome of a single import row.
+// ID is set for rows created by a committed import, Errors for invalid rows.
+type CompanyImportRowResponse struct {
+	Row    int                  `json:"row"`
+	Status string               `json:"status"`
+	ID     *uuid.UUID           `json:"id,omitempty"`
+	Name   *string              `json:"name,omitempty"`
+	Errors []problem.FieldError `json:"errors,omitempty"`
+}
+
+type CompanyImportResponse struct {
+	Mode      string                     `json:"mode"`
+	Committed bool                       `json:"committed"`
+	Created   int                        `json:"created"`
+	Skipped   int                        `json:"skipped"`
+	Invalid   int                        `json:"invalid"`
+	Rows      []CompanyImportRowResponse `json:"rows"`
+}
+
+type CompanyRevisionResponse struct {
+	Revision  int64           `json:"revision"`
+	Operation string          `json:"operation"`
+	Actor     *uuid.UUID      `json:"actor"`
+	ChangedAt time.Time       `json:"changed_at"`
+	Reason    *string         `json:"reason,omitempty"` // given for transitions
+	Company   CompanyResponse `json:"company"`
+}
+
+type CompanyHistoryResponse struct {
+	Revisions  []CompanyRevisionResponse `json:"revisions"`
+	NextCursor *string                   `json:"next_cursor,omitempty"`
+}
+
+type CompanyFieldChangeResponse struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+type CompanyDiffResponse struct {
+	From    int64                        `json:"from"`
+	To      int64                        `json:"to"`
+	Changes []CompanyFieldChangeResponse `json:"changes"`
+}
+
+type CompanySearchResult struct {
+	Company CompanyResponse `json:"company"`
+	Rank    float32         `json:"rank"`
+	Snippet *string         `json:"snippet,omitempty"`
+}
+
+type CompanySearchResponse struct {
+	Results []CompanySearchResult `json:"results"`
+}
+
+type CompanyTypeResponse struct {
+	Name        string     `json:"name"`
+	Description *string    `json:"description,omitempty"`
+	Active      bool       `json:"active"`
+	CreatedAt   *time.Time `json:"created_at"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+}
+
+type CompanyTypeListResponse struct {
+	CompanyTypes []CompanyTypeResponse `json:"company_types"`
 }
 
 func convertToCompanyResponse(c *domain.Company) CompanyResponse {
@@ -44,6 +203,10 @@ func convertToCompanyResponse(c *domain.Company) CompanyResponse {
 	if c.EmployeeCount != nil {
 		empCount = *c.EmployeeCount
 	}
+	status := ""
+	if c.Status != nil {
+		status = string(*c.Status)
+	}
 	registered := false
 	if c.Registered != nil {
 		registered = *c.Registered
@@ -57,7 +220,59 @@ func convertToCompanyResponse(c *domain.Company) CompanyResponse {
 		Name:          name,
 		Description:   c.Description,
 		EmployeeCount: empCount,
+		Status:        status,
 		Registered:    registered,
 		CompanyType:   cType,
 	}
 }
+
+func convertToCompanyMetaResponse(c *domain.Company) *CompanyMetaResponse {
+	meta := &CompanyMetaResponse{
+		CreatedAt: c.CreatedAt,
+		CreatedBy: convertToCompanyActorResponse(c.CreatedBy, c.CreatedByUsername),
+		UpdatedAt: c.UpdatedAt,
+		UpdatedBy: convertToCompanyActorResponse(c.UpdatedBy, c.UpdatedByUsername),
+	}
+	if c.Version != nil {
+		meta.Version = *c.Version
+	}
+	return meta
+}
+
+func convertToCompanyActorResponse(id *uuid.UUID, username *string) *CompanyActorResponse {
+	id = auditActor(id)
+	if id == nil {
+		return nil
+	}
+	actor := &CompanyActorResponse{ID: *id}
+	if username != nil {
+		actor.Username = *username
+	}
+	return actor
+}
+
+// auditActor drops the nil UUID companies carry for an actor that isn't known.
+func auditActor(id *uuid.UUID) *uuid.UUID {
+	if id == nil || *id == uuid.Nil {
+		return nil
+	}
+	return id
+}
+
+func convertToCompanyTypeResponse(t *domain.CompanyTypeDefinition) CompanyTypeResponse {
+	name := ""
+	if t.Name != nil {
+		name = string(*t.Name)
+	}
+	active := false
+	if t.Active != nil {
+		active = *t.Active
+	}
+	return CompanyTypeResponse{
+		Name:        name,
+		Description: t.Description,
+		Active:      active,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
@@ -2,23 +2,26 @@ package handlers
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
 
+	"github.com/Laelapa/CompanyRegistry/internal/authz"
 	"github.com/Laelapa/CompanyRegistry/internal/domain"
-	"github.com/Laelapa/CompanyRegistry/util/ctxutils"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
 
 	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
 // HandleUpdateCompany processes requests to (partially) update a company.
-// It expects a userID in the request context - set by the jwt authentication middleware.
+// It expects a userID and role in the request context - set by the jwt authentication middleware.
+// Only the company's creator, editors and admins may update it.
+// The status only changes through transitions, a registered field sent by older clients must agree with it.
+// An If-Match header makes the update conditional on the company's ETag, failing with 412 if it has changed.
 func (h *Handler) HandleUpdateCompany(w http.ResponseWriter, r *http.Request) {
 	id, pErr := uuid.Parse(r.PathValue("id"))
 	if pErr != nil {
 		h.logger.Warn("Invalid company ID in path", append(h.logger.ReqFields(r), zap.Error(pErr))...)
-		http.Error(w, "Bad request: Invalid ID", http.StatusBadRequest)
+		h.writeProblem(w, r, problem.BadRequest("Invalid ID"))
 		return
 	}
 
@@ -27,20 +30,29 @@ func (h *Handler) HandleUpdateCompany(w http.ResponseWriter, r *http.Request) {
 
 	if err := json.NewDecoder(r.Body).Decode(&rBody); err != nil {
 		h.logger.Warn("Failed to decode request body", append(h.logger.ReqFields(r), zap.Error(err))...)
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		h.writeProblem(w, r, problem.BadRequest("Malformed request body"))
 		return
 	}
 
 	if err := h.validator.Struct(rBody); err != nil {
 		h.logger.Warn("Invalid request data", append(h.logger.ReqFields(r), zap.Error(err))...)
-		http.Error(w, "Bad request: Invalid data", http.StatusBadRequest)
+		h.writeProblem(w, r, problem.Validation(err))
 		return
 	}
 
-	userID, ok := ctxutils.GetUserIDFromContext(r.Context())
+	expectedVersion, err := parseIfMatch(r)
+	if err != nil {
+		h.logger.Warn("Invalid If-Match header", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrBadRequest: "Invalid If-Match header",
+		}))
+		return
+	}
+
+	principal, ok := authz.PrincipalFromContext(r.Context())
 	if !ok {
 		h.logger.Error("Failed to get user ID from context", h.logger.ReqFields(r)...)
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		h.writeProblem(w, r, problem.Unauthorized())
 		return
 	}
 
@@ -57,22 +69,18 @@ func (h *Handler) HandleUpdateCompany(w http.ResponseWriter, r *http.Request) {
 		EmployeeCount: rBody.EmployeeCount,
 		Registered:    rBody.Registered,
 		CompanyType:   companyType,
-		UpdatedBy:     &userID,
+		Version:       expectedVersion,
 	}
 
-	updatedCompany, err := h.service.Company.Update(r.Context(), uc)
+	updatedCompany, err := h.service.Company.Update(r.Context(), principal, uc)
 	if err != nil {
 		h.logger.Error("Failed to update company", append(h.logger.ReqFields(r), zap.Error(err))...)
-		switch {
-		case errors.Is(err, domain.ErrNotFound):
-			http.Error(w, "Company not found", http.StatusNotFound)
-		case errors.Is(err, domain.ErrConflict):
-			http.Error(w, "Conflict: Company name already exists", http.StatusConflict)
-		case errors.Is(err, domain.ErrBadRequest):
-			http.Error(w, "Bad request: Invalid update data", http.StatusBadRequest)
-		default:
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrNotFound:           "Company not found",
+			domain.ErrConflict:           "Company name already exists",
+			domain.ErrBadRequest:         "Invalid update data, registered must agree with the company's status",
+			domain.ErrPreconditionFailed: "Company has been modified since it was read",
+		}))
 		return
 	}
 
@@ -81,10 +89,11 @@ func (h *Handler) HandleUpdateCompany(w http.ResponseWriter, r *http.Request) {
 	respMarshalled, err := json.Marshal(response)
 	if err != nil {
 		h.logger.Error("Failed to marshal response", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		h.writeProblem(w, r, problem.Internal())
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", companyETag(updatedCompany))
 	w.WriteHeader(http.StatusOK)
 	if _, err := w.Write(respMarshalled); err != nil {
 		h.logger.Error("Failed to write response", zap.Error(err))
@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
+	"github.com/Laelapa/CompanyRegistry/logging"
+
+	"go.uber.org/zap"
+)
+
+type HealthResponse struct {
+	Status string                              `json:"status"`
+	Checks map[string]DependencyHealthResponse `json:"checks,omitempty"`
+}
+
+type DependencyHealthResponse struct {
+	Status    string         `json:"status"`
+	Critical  bool           `json:"critical"`
+	LatencyMS float64        `json:"latency_ms"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+// HandleHealthz reports the process is alive. It checks nothing else,
+// a failing dependency should not get the process restarted.
+func (h *Handler) HandleHealthz(w http.ResponseWriter, r *http.Request) {
+	h.writeHealthResponse(w, r, http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+// HandleReadyz reports whether the service can take traffic, with a breakdown per dependency.
+// It responds 503 while a critical dependency is down or the server is shutting down.
+// Check errors are logged rather than returned, the endpoint is reachable without authentication.
+func (h *Handler) HandleReadyz(w http.ResponseWriter, r *http.Request) {
+	report := h.service.Health.Readiness(r.Context())
+
+	resp := HealthResponse{
+		Status: string(report.Status),
+		Checks: make(map[string]DependencyHealthResponse, len(report.Dependencies)),
+	}
+	for name, dep := range report.Dependencies {
+		if dep.Error != "" {
+			h.logger.Warn("Readiness check failed", append(
+				h.logger.ReqFields(r),
+				zap.String(logging.FieldDependency, name),
+				zap.String(logging.FieldError, dep.Error),
+			)...)
+		}
+		resp.Checks[name] = DependencyHealthResponse{
+			Status:    string(dep.Status),
+			Critical:  dep.Critical,
+			LatencyMS: float64(dep.Latency) / float64(time.Millisecond),
+			Details:   dep.Details,
+		}
+	}
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	h.writeHealthResponse(w, r, status, resp)
+}
+
+func (h *Handler) writeHealthResponse(w http.ResponseWriter, r *http.Request, status int, resp HealthResponse) {
+	respMarshalled, err := json.Marshal(resp)
+	if err != nil {
+		h.logger.Error("Failed to marshal health response", zap.Error(err))
+		h.writeProblem(w, r, problem.Internal())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if _, err := w.Write(respMarshalled); err != nil {
+		h.logger.Error("Failed to write response", zap.Error(err))
+	}
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
+
+	"go.uber.org/zap"
+)
+
+// HandleJWKS serves the public keys access tokens can be verified with.
+func (h *Handler) HandleJWKS(w http.ResponseWriter, r *http.Request) {
+	respMarshalled, err := json.Marshal(h.tokenAuthority.JWKS())
+	if err != nil {
+		h.logger.Error("Failed to marshal JWKS", zap.Error(err))
+		h.writeProblem(w, r, problem.Internal())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	// Short enough for verifiers to notice upcoming keys well before they start signing
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(respMarshalled); err != nil {
+		h.logger.Error("Failed to write response", zap.Error(err))
+	}
+	h.logger.Info("Served JWKS", h.logger.ReqFields(r)...)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
+
+	"go.uber.org/zap"
+)
+
+// HandleRefreshToken exchanges a refresh token for a new token pair.
+// The presented refresh token is rotated and cannot be used again.
+func (h *Handler) HandleRefreshToken(w http.ResponseWriter, r *http.Request) {
+	var rBody RefreshTokenRequest
+	h.logger.Info("Processing token refresh request", h.logger.ReqFields(r)...)
+
+	// Decode request body
+	if err := json.NewDecoder(r.Body).Decode(&rBody); err != nil {
+		h.logger.Warn(
+			"Failed to decode token refresh request body",
+			append(h.logger.ReqFields(r), zap.Error(err))...,
+		)
+		h.writeProblem(w, r, problem.BadRequest("Malformed request body"))
+		return
+	}
+
+	// Validate contents
+	if err := h.validator.Struct(rBody); err != nil {
+		h.logger.Warn(
+			"Invalid token refresh request data",
+			append(h.logger.ReqFields(r), zap.Error(err))...,
+		)
+		h.writeProblem(w, r, problem.Validation(err))
+		return
+	}
+
+	tokens, err := h.service.Session.Refresh(r.Context(), rBody.RefreshToken)
+	if err != nil {
+		if errors.Is(err, domain.ErrBadCredentials) {
+			h.logger.Warn("Token refresh rejected", append(h.logger.ReqFields(r), zap.Error(err))...)
+		} else {
+			h.logger.Error("Token refresh failed", append(h.logger.ReqFields(r), zap.Error(err))...)
+		}
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrBadCredentials: "Invalid refresh token",
+		}))
+		return
+	}
+
+	resp := newAuthResponse(tokens)
+
+	respMarshalled, err := json.Marshal(resp)
+	if err != nil {
+		h.logger.Error("Failed to marshal token refresh response", zap.Error(err))
+		h.writeProblem(w, r, problem.Internal())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(respMarshalled); err != nil {
+		h.logger.Error("Failed to write response", zap.Error(err))
+	}
+	h.logger.Info("Token refresh request processed", h.logger.ReqFields(r)...)
+}
@@ -2,10 +2,10 @@ package handlers
 
 import (
 	"encoding/json"
-	"errors"
 	"net/http"
 
 	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
 
 	"go.uber.org/zap"
 )
@@ -20,7 +20,7 @@ func (h *Handler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 			"Failed to decode login request body",
 			append(h.logger.ReqFields(r), zap.Error(err))...,
 		)
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		h.writeProblem(w, r, problem.BadRequest("Malformed request body"))
 		return
 	}
 
@@ -30,30 +30,28 @@ func (h *Handler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 			"Invalid login request data",
 			append(h.logger.ReqFields(r), zap.Error(err))...,
 		)
-		http.Error(w, "Bad request: Invalid data", http.StatusBadRequest)
+		h.writeProblem(w, r, problem.Validation(err))
 		return
 	}
 
-	accessToken, err := h.service.User.Login(r.Context(), rBody.Username, rBody.Password)
+	tokens, err := h.service.User.Login(r.Context(), rBody.Username, rBody.Password)
 	if err != nil {
 		h.logger.Error(
 			"User login failed",
 			append(h.logger.ReqFields(r), zap.Error(err))...,
 		)
-		if errors.Is(err, domain.ErrBadCredentials) {
-			http.Error(w, "Unauthorized: Login Failed", http.StatusUnauthorized)
-		} else {
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
-		}
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrBadCredentials: "Login failed",
+		}))
 		return
 	}
 
-	resp := AuthResponse{AccessToken: accessToken}
+	resp := newAuthResponse(tokens)
 
 	respMarshalled, err := json.Marshal(resp)
 	if err != nil {
 		h.logger.Error("Failed to marshal login response", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		h.writeProblem(w, r, problem.Internal())
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
+	"github.com/Laelapa/CompanyRegistry/util/ctxutils"
+
+	"go.uber.org/zap"
+)
+
+// HandleLogout ends the session of the access token the request was authenticated with,
+// revoking its refresh tokens and access tokens.
+// It expects the token info in the request context - set by the jwt authentication middleware.
+func (h *Handler) HandleLogout(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Processing logout request", h.logger.ReqFields(r)...)
+
+	token, ok := ctxutils.GetTokenInfoFromContext(r.Context())
+	if !ok {
+		h.logger.Error("Failed to get token info from context", h.logger.ReqFields(r)...)
+		h.writeProblem(w, r, problem.Unauthorized())
+		return
+	}
+
+	if err := h.service.Session.Logout(r.Context(), token.ID, token.SessionID, token.ExpiresAt); err != nil {
+		h.logger.Error("Failed to log out", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.Internal())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	h.logger.Info("Logout request processed", h.logger.ReqFields(r)...)
+}
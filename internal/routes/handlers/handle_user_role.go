@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
+	"github.com/Laelapa/CompanyRegistry/util/ctxutils"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// HandleAssignRole processes requests to change a user's role.
+// Authorization is left to the authorization middleware guarding the route.
+// It expects a userID in the request context - set by the jwt authentication middleware.
+func (h *Handler) HandleAssignRole(w http.ResponseWriter, r *http.Request) {
+	id, pErr := uuid.Parse(r.PathValue("id"))
+	if pErr != nil {
+		h.logger.Warn("Invalid user ID in path", append(h.logger.ReqFields(r), zap.Error(pErr))...)
+		h.writeProblem(w, r, problem.BadRequest("Invalid ID"))
+		return
+	}
+
+	var rBody AssignRoleRequest
+	h.logger.Info("Processing Assign Role request", h.logger.ReqFields(r)...)
+
+	// Decode request body
+	if err := json.NewDecoder(r.Body).Decode(&rBody); err != nil {
+		h.logger.Warn(
+			"Failed to decode assign role request body",
+			append(h.logger.ReqFields(r), zap.Error(err))...,
+		)
+		h.writeProblem(w, r, problem.BadRequest("Malformed request body"))
+		return
+	}
+
+	// Validate contents
+	if err := h.validator.Struct(rBody); err != nil {
+		h.logger.Warn(
+			"Invalid assign role request data",
+			append(h.logger.ReqFields(r), zap.Error(err))...,
+		)
+		h.writeProblem(w, r, problem.Validation(err))
+		return
+	}
+
+	userID, ok := ctxutils.GetUserIDFromContext(r.Context())
+	if !ok {
+		h.logger.Error("Failed to get user ID from context", h.logger.ReqFields(r)...)
+		h.writeProblem(w, r, problem.Unauthorized())
+		return
+	}
+
+	if err := h.service.User.AssignRole(r.Context(), userID, id, domain.Role(rBody.Role)); err != nil {
+		h.logger.Error("Failed to assign role", append(h.logger.ReqFields(r), zap.Error(err))...)
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrNotFound:   "User not found",
+			domain.ErrBadRequest: "Invalid role",
+		}))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+	h.logger.Info("Successfully assigned role", h.logger.ReqFields(r)...)
+}
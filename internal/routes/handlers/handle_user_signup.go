@@ -6,6 +6,7 @@ import (
 	"net/http"
 
 	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
 
 	"go.uber.org/zap"
 )
@@ -20,7 +21,7 @@ func (h *Handler) HandleSignup(w http.ResponseWriter, r *http.Request) {
 			"Failed to decode signup request body",
 			append(h.logger.ReqFields(r), zap.Error(err))...,
 		)
-		http.Error(w, "Bad request", http.StatusBadRequest)
+		h.writeProblem(w, r, problem.BadRequest("Malformed request body"))
 		return
 	}
 
@@ -30,33 +31,33 @@ func (h *Handler) HandleSignup(w http.ResponseWriter, r *http.Request) {
 			"Invalid signup request data",
 			append(h.logger.ReqFields(r), zap.Error(err))...,
 		)
-		http.Error(w, "Bad request: Invalid data", http.StatusBadRequest)
+		h.writeProblem(w, r, problem.Validation(err))
 		return
 	}
 
-	accessToken, err := h.service.User.Register(r.Context(), rBody.Username, rBody.Password)
+	tokens, err := h.service.User.Register(r.Context(), rBody.Username, rBody.Password)
 	if err != nil {
 		h.logger.Error(
 			"User registration failed",
 			append(h.logger.ReqFields(r), zap.Error(err))...,
 		)
-		switch {
-		case errors.Is(err, domain.ErrConflict):
-			http.Error(w, "Conflict: User already exists", http.StatusConflict)
-		case errors.Is(err, domain.ErrBadCredentials):
-			http.Error(w, "Bad request: Invalid credentials", http.StatusBadRequest)
-		default:
-			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		// Credentials are checked for being set here, not verified, so a failure is the client's mistake
+		if errors.Is(err, domain.ErrBadCredentials) {
+			h.writeProblem(w, r, problem.BadRequest("Invalid credentials"))
+			return
 		}
+		h.writeProblem(w, r, problem.FromError(err, problem.Messages{
+			domain.ErrConflict: "User already exists",
+		}))
 		return
 	}
 
-	resp := AuthResponse{AccessToken: accessToken}
+	resp := newAuthResponse(tokens)
 
 	respMarshalled, err := json.Marshal(resp)
 	if err != nil {
 		h.logger.Error("Failed to marshal signup response", zap.Error(err))
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		h.writeProblem(w, r, problem.Internal())
 		return
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -1,5 +1,11 @@
 package handlers
 
+import (
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+)
+
 type UserSignupRequest struct {
 	Username string `json:"username" validate:"required,max=255,alphanum"`
 	Password string `json:"password" validate:"required,max=72"`
@@ -10,6 +16,26 @@ type UserLoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+type AssignRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=admin editor viewer"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
 type AuthResponse struct {
-	AccessToken string `json:"access_token"`
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"` // seconds until the access token expires
+	RefreshToken string `json:"refresh_token"`
+}
+
+func newAuthResponse(tokens *domain.TokenPair) AuthResponse {
+	return AuthResponse{
+		AccessToken:  tokens.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(time.Until(tokens.AccessTokenExpiresAt).Seconds()),
+		RefreshToken: tokens.RefreshToken,
+	}
 }
@@ -1,12 +1,17 @@
 package handlers
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/Laelapa/CompanyRegistry/auth/tokenauthority"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
 	"github.com/Laelapa/CompanyRegistry/internal/service"
 	"github.com/Laelapa/CompanyRegistry/logging"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
 )
 
 type Handler struct {
@@ -15,6 +20,8 @@ type Handler struct {
 	tokenAuthority *tokenauthority.TokenAuthority
 	kafkaClient    *kgo.Client
 	validator      *validator.Validate
+	// exportTimeout bounds whole exports, zero leaves them unbounded
+	exportTimeout time.Duration
 }
 
 func New(
@@ -22,12 +29,28 @@ func New(
 	service *service.Service,
 	tokenAuthority *tokenauthority.TokenAuthority,
 	kafkaClient *kgo.Client,
+	exportTimeout time.Duration,
 ) *Handler {
 	return &Handler{
 		logger:         logger,
 		service:        service,
 		tokenAuthority: tokenAuthority,
 		kafkaClient:    kafkaClient,
-		validator:      validator.New(validator.WithRequiredStructEnabled()),
+		validator:      newValidator(),
+		exportTimeout:  exportTimeout,
+	}
+}
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	// Report validation failures under the names clients know the fields by
+	v.RegisterTagNameFunc(problem.FieldName)
+	return v
+}
+
+// writeProblem renders p as the response to r.
+func (h *Handler) writeProblem(w http.ResponseWriter, r *http.Request, p *problem.Problem) {
+	if err := problem.Write(w, r, p); err != nil {
+		h.logger.Error("Failed to write response", append(h.logger.ReqFields(r), zap.Error(err))...)
 	}
 }
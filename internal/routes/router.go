@@ -2,8 +2,10 @@ package routes
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/Laelapa/CompanyRegistry/auth/tokenauthority"
+	"github.com/Laelapa/CompanyRegistry/internal/authz"
 	"github.com/Laelapa/CompanyRegistry/internal/middleware"
 	"github.com/Laelapa/CompanyRegistry/internal/routes/handlers"
 	"github.com/Laelapa/CompanyRegistry/internal/service"
@@ -18,28 +20,71 @@ func Setup(
 	service *service.Service,
 	tokenAuthority *tokenauthority.TokenAuthority,
 	kafkaClient *kgo.Client,
+	exportTimeout time.Duration,
 ) *http.ServeMux {
 	mux := http.NewServeMux()
 	// fileServer := http.StripPrefix("/static/", http.FileServer(http.Dir(staticDir)))
 
-	h := handlers.New(logger, service, tokenAuthority, kafkaClient)
+	h := handlers.New(logger, service, tokenAuthority, kafkaClient, exportTimeout)
 
 	// Wrapper for handlers that require authenticated access
 	withAuth := func(handler func(http.ResponseWriter, *http.Request)) http.Handler {
-		return middleware.AuthenticateWithJWT(tokenAuthority, logger)(http.HandlerFunc(handler))
+		return middleware.AuthenticateWithJWT(tokenAuthority, service.Session, logger)(http.HandlerFunc(handler))
+	}
+	// Wrapper for handlers that additionally require the principal to satisfy an authorization rule.
+	// Rules that depend on the resource itself are enforced by the service layer.
+	withAuthz := func(rule authz.Rule, handler func(http.ResponseWriter, *http.Request)) http.Handler {
+		return withAuth(middleware.Authorize(rule, logger)(http.HandlerFunc(handler)).ServeHTTP)
+	}
+	// Wrapper for mutating handlers whose response is replayed to retries sent with the same Idempotency-Key.
+	// Behind withAuth the keys are scoped to the authenticated user.
+	// Responses are recorded in plaintext, so handlers that issue tokens must use reissuing instead.
+	idempotent := func(handler func(http.ResponseWriter, *http.Request)) http.Handler {
+		return middleware.Idempotency(service.Idempotency, logger)(http.HandlerFunc(handler))
+	}
+	// Wrapper for idempotent handlers that issue tokens, retries after a success are answered by reissue
+	// with fresh tokens rather than the recorded ones.
+	reissuing := func(handler, reissue func(http.ResponseWriter, *http.Request)) http.Handler {
+		return middleware.IdempotencyReissuing(
+			service.Idempotency, logger, http.HandlerFunc(reissue),
+		)(http.HandlerFunc(handler))
 	}
 
 	// mux.Handle("GET /static/", fileServer)
 	mux.HandleFunc("GET /openapi.json", h.HandleGetOpenAPI)
 	mux.HandleFunc("GET /docs", h.HandleSwaggerUI)
+	mux.HandleFunc("GET /.well-known/jwks.json", h.HandleJWKS)
+	mux.HandleFunc("GET /healthz", h.HandleHealthz)
+	mux.HandleFunc("GET /readyz", h.HandleReadyz)
 
 	mux.HandleFunc("POST /api/v1/login", h.HandleLogin)
-	mux.HandleFunc("POST /api/v1/signup", h.HandleSignup)
+	// A retried signup logs in with the same credentials
+	mux.Handle("POST /api/v1/signup", reissuing(h.HandleSignup, h.HandleLogin))
+	mux.HandleFunc("POST /api/v1/token/refresh", h.HandleRefreshToken)
+	mux.Handle("POST /api/v1/logout", withAuth(h.HandleLogout))
+	mux.Handle("PUT /api/v1/user/{id}/role", withAuthz(authz.CanAssignRoles, h.HandleAssignRole))
 
-	mux.HandleFunc("GET /api/v1/company/{name}", h.HandleGetCompanyByName)
-	mux.Handle("POST /api/v1/company", withAuth(h.HandleCreateCompany))
+	mux.HandleFunc("GET /api/v1/companies", h.HandleListCompanies)
+	mux.HandleFunc("GET /api/v1/companies/search", h.HandleSearchCompanies)
+	mux.HandleFunc("GET /api/v1/company/by-name/{name}", h.HandleGetCompanyByName)
+	mux.HandleFunc("GET /api/v1/company/{id}", h.HandleGetCompanyByID)
+	mux.Handle("POST /api/v1/company", withAuth(idempotent(h.HandleCreateCompany).ServeHTTP))
+	mux.Handle("POST /api/v1/companies/import", withAuth(h.HandleImportCompanies))
+	mux.Handle("GET /api/v1/companies/export", withAuth(h.HandleExportCompanies))
 	mux.Handle("PATCH /api/v1/company/{id}", withAuth(h.HandleUpdateCompany))
 	mux.Handle("DELETE /api/v1/company/{id}", withAuth(h.HandleDeleteCompany))
+	mux.Handle("POST /api/v1/company/{id}/restore", withAuth(h.HandleRestoreCompany))
+	mux.Handle(
+		"POST /api/v1/company/{id}/transitions",
+		withAuthz(authz.CanGovernCompanyLifecycle, h.HandleTransitionCompany),
+	)
+	mux.Handle("GET /api/v1/company/{id}/history", withAuth(h.HandleGetCompanyHistory))
+	mux.Handle("GET /api/v1/company/{id}/history/diff", withAuth(h.HandleGetCompanyDiff))
+
+	mux.HandleFunc("GET /api/v1/company-types", h.HandleListCompanyTypes)
+	mux.Handle("POST /api/v1/company-types", withAuthz(authz.CanManageCompanyTypes, h.HandleCreateCompanyType))
+	mux.Handle("PATCH /api/v1/company-types/{name}", withAuthz(authz.CanManageCompanyTypes, h.HandleUpdateCompanyType))
+	mux.Handle("DELETE /api/v1/company-types/{name}", withAuthz(authz.CanManageCompanyTypes, h.HandleDeleteCompanyType))
 
 	return mux
 }
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// MaxCompanyImportRows bounds the rows of a single import, all of them are written in one transaction
+	MaxCompanyImportRows = 10000
+	// Rows inserted per statement
+	companyImportBatchSize = 500
+)
+
+// errImportRolledBack aborts the transaction of an import that must not be committed.
+var errImportRolledBack = errors.New("import rolled back")
+
+// Import creates companies in bulk on behalf of createdBy and reports the outcome of every row.
+// Rows already marked invalid are reported as they are, the others get the checks of Create,
+// rows of an unknown or retired company type being invalid.
+// Rows naming an existing company, or one named by an earlier row, are skipped.
+// All rows are written in a single transaction, which mode decides whether to commit, an empty mode
+// being all-or-nothing. A dry run or a failed all-or-nothing import reports the rows it would have created.
+// It returns domain.ErrBadRequest if the mode is unknown or the number of rows out of range.
+func (u *CompanyService) Import(
+	ctx context.Context,
+	createdBy uuid.UUID,
+	rows []*domain.CompanyImportRow,
+	mode domain.CompanyImportMode,
+) (_ *domain.CompanyImportReport, err error) {
+	ctx, span := u.tracer.Start(ctx, "CompanyService.Import")
+	defer func() { tracing.End(span, err) }()
+
+	switch mode {
+	case "":
+		mode = domain.CompanyImportAllOrNothing
+	case domain.CompanyImportDryRun, domain.CompanyImportAllOrNothing, domain.CompanyImportBestEffort:
+	default:
+		return nil, fmt.Errorf("unsupported import mode %q: %w", mode, domain.ErrBadRequest)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("import has no rows: %w", domain.ErrBadRequest)
+	}
+	if len(rows) > MaxCompanyImportRows {
+		return nil, fmt.Errorf("import cannot exceed %d rows: %w", MaxCompanyImportRows, domain.ErrBadRequest)
+	}
+	span.SetAttributes(attribute.String("import.mode", string(mode)), attribute.Int("import.rows", len(rows)))
+
+	types, err := u.types.assignable(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := make([]*domain.CompanyImportRow, 0, len(rows))
+	names := make(map[string]struct{}, len(rows))
+	for _, row := range rows {
+		if row.Status == domain.CompanyImportInvalid {
+			continue
+		}
+		row.Company.CreatedBy = &createdBy
+		if cErr := checkNewCompany(row.Company); cErr != nil {
+			row.Status = domain.CompanyImportInvalid
+			row.Problems = append(row.Problems, domain.CompanyImportProblem{Rule: "required", Message: cErr.Error()})
+			continue
+		}
+		if _, ok := types[*row.Company.CompanyType]; !ok {
+			row.Status = domain.CompanyImportInvalid
+			row.Problems = append(row.Problems, domain.CompanyImportProblem{
+				Field: "company_type", Rule: "company_type", Message: "company_type is unknown or retired",
+			})
+			continue
+		}
+		// The insert would skip one of two rows with the same name, not necessarily the later one
+		if _, taken := names[*row.Company.Name]; taken {
+			row.Status = domain.CompanyImportSkipped
+			continue
+		}
+		names[*row.Company.Name] = struct{}{}
+		pending = append(pending, row)
+	}
+
+	report := &domain.CompanyImportReport{Rows: rows}
+	err = u.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		for batch := range slices.Chunk(pending, companyImportBatchSize) {
+			if err := u.importBatch(ctx, createdBy, batch); err != nil {
+				return err
+			}
+		}
+		countImportRows(report)
+		if mode == domain.CompanyImportDryRun || (mode == domain.CompanyImportAllOrNothing && report.Created < len(rows)) {
+			return errImportRolledBack
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, errImportRolledBack):
+		return report, nil
+	case err != nil:
+		return nil, err
+	}
+
+	report.Committed = true
+	for range report.Created {
+		u.metrics.CompanyCreated()
+	}
+	return report, nil
+}
+
+// importBatch creates the companies of a batch of rows along with their revisions and events,
+// and marks each row created or skipped.
+func (u *CompanyService) importBatch(ctx context.Context, createdBy uuid.UUID, batch []*domain.CompanyImportRow) error {
+	companies := make([]*domain.Company, 0, len(batch))
+	for _, row := range batch {
+		companies = append(companies, row.Company)
+	}
+	created, err := u.repo.CreateMany(ctx, companies, createdBy)
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]*domain.Company, len(created))
+	ids := make([]uuid.UUID, 0, len(created))
+	for _, c := range created {
+		byName[*c.Name] = c
+		ids = append(ids, *c.ID)
+	}
+	if err = u.repo.RecordCreations(ctx, ids); err != nil {
+		return err
+	}
+
+	for _, row := range batch {
+		c, ok := byName[*row.Company.Name]
+		if !ok {
+			row.Status = domain.CompanyImportSkipped
+			continue
+		}
+		row.Company, row.Status = c, domain.CompanyImportCreated
+		err = enqueueEvent(
+			ctx, u.outbox, u.topic, u.eventSource, EventTypeCompanyCreated, *c.ID,
+			CompanyEventData{
+				SchemaVersion: EventSchemaVersion,
+				Actor:         createdBy,
+				Company:       newCompanySnapshot(c),
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func countImportRows(report *domain.CompanyImportReport) {
+	report.Created, report.Skipped, report.Invalid = 0, 0, 0
+	for _, row := range report.Rows {
+		switch row.Status {
+		case domain.CompanyImportCreated:
+			report.Created++
+		case domain.CompanyImportSkipped:
+			report.Skipped++
+		case domain.CompanyImportInvalid:
+			report.Invalid++
+		case domain.CompanyImportPending:
+		}
+	}
+}
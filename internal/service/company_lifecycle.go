@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Laelapa/CompanyRegistry/internal/authz"
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/tracing"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// companyTransition is the status an action moves a company to, from any of the statuses it's allowed in.
+type companyTransition struct {
+	from []domain.CompanyStatus
+	to   domain.CompanyStatus
+}
+
+// maxTransitionReasonLength bounds the reason recorded with a transition.
+const maxTransitionReasonLength = 500
+
+// companyTransitions is the lifecycle of a company. Dissolved companies stay dissolved.
+var companyTransitions = map[domain.CompanyAction]companyTransition{
+	domain.CompanyActionApprove: {
+		from: []domain.CompanyStatus{domain.CompanyStatusPending},
+		to:   domain.CompanyStatusActive,
+	},
+	domain.CompanyActionReject: {
+		from: []domain.CompanyStatus{domain.CompanyStatusPending},
+		to:   domain.CompanyStatusDissolved,
+	},
+	domain.CompanyActionSuspend: {
+		from: []domain.CompanyStatus{domain.CompanyStatusActive},
+		to:   domain.CompanyStatusSuspended,
+	},
+	domain.CompanyActionReinstate: {
+		from: []domain.CompanyStatus{domain.CompanyStatusSuspended},
+		to:   domain.CompanyStatusActive,
+	},
+	domain.CompanyActionLiquidate: {
+		from: []domain.CompanyStatus{domain.CompanyStatusActive, domain.CompanyStatusSuspended},
+		to:   domain.CompanyStatusInLiquidation,
+	},
+	domain.CompanyActionDissolve: {
+		from: []domain.CompanyStatus{domain.CompanyStatusInLiquidation},
+		to:   domain.CompanyStatusDissolved,
+	},
+}
+
+// Transition puts a company through a lifecycle action on behalf of actor, for the given reason.
+// A non-nil expectedVersion makes the transition conditional on the company still being at that version.
+// It returns domain.ErrBadRequest for unknown actions or a missing reason
+// and domain.ErrInvalidTransition if the action isn't allowed from the company's current status.
+// If the company does not exist, it returns domain.ErrNotFound.
+// If the actor may not govern the lifecycle of companies, it returns an *authz.Denial wrapping domain.ErrForbidden.
+// If the company has moved past expectedVersion, it returns domain.ErrPreconditionFailed.
+func (u *CompanyService) Transition(
+	ctx context.Context,
+	actor authz.Principal,
+	id uuid.UUID,
+	action domain.CompanyAction,
+	reason string,
+	expectedVersion *int64,
+) (_ *domain.Company, err error) {
+	ctx, span := u.tracer.Start(ctx, "CompanyService.Transition", trace.WithAttributes(
+		attrCompanyID(id),
+		attribute.String("company.action", string(action)),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	transition, ok := companyTransitions[action]
+	if !ok {
+		return nil, fmt.Errorf("unknown action %q: %w", action, domain.ErrBadRequest)
+	}
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("a reason is required: %w", domain.ErrBadRequest)
+	}
+	if utf8.RuneCountInString(reason) > maxTransitionReasonLength {
+		return nil, fmt.Errorf("reason cannot exceed %d characters: %w", maxTransitionReasonLength, domain.ErrBadRequest)
+	}
+
+	var previous, transitioned *domain.Company
+	err = u.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if previous, err = u.repo.GetByIDForUpdate(ctx, id); err != nil {
+			return err
+		}
+		if err = authz.CanGovernCompanyLifecycle(actor); err != nil {
+			return err
+		}
+		if err = checkCompanyVersion(previous, expectedVersion); err != nil {
+			return err
+		}
+		if !slices.Contains(transition.from, *previous.Status) {
+			return fmt.Errorf("cannot %s a company that is %s: %w", action, *previous.Status, domain.ErrInvalidTransition)
+		}
+
+		if transitioned, err = u.repo.UpdateStatus(ctx, id, transition.to, actor.UserID); err != nil {
+			return err
+		}
+		err = u.repo.RecordRevision(ctx, &domain.CompanyRevision{
+			Revision:  *transitioned.Version,
+			Operation: domain.CompanyOperationTransition,
+			Company:   transitioned,
+			Actor:     &actor.UserID,
+			Reason:    &reason,
+		})
+		if err != nil {
+			return err
+		}
+		return enqueueEvent(
+			ctx, u.outbox, u.topic, u.eventSource, EventTypeCompanyStatusChanged, id,
+			CompanyStatusChangedEventData{
+				SchemaVersion: EventSchemaVersion,
+				Actor:         actor.UserID,
+				Action:        string(action),
+				From:          string(*previous.Status),
+				To:            string(transition.to),
+				Reason:        reason,
+				Company:       newCompanySnapshot(transitioned),
+			},
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+	u.forgetCompanies(*transitioned.Name)
+	u.metrics.CompanyTransitioned()
+
+	return transitioned, nil
+}
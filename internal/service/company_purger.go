@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/config"
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/metrics"
+	"github.com/Laelapa/CompanyRegistry/logging"
+
+	"go.uber.org/zap"
+)
+
+// CompanyPurger removes deleted companies for good once their retention period is over.
+// Their history is kept, only the restorable copy goes away.
+type CompanyPurger struct {
+	repo        CompanyRecycleBin
+	transactor  Transactor
+	outbox      EventOutbox
+	logger      *logging.Logger
+	metrics     *metrics.Metrics
+	cfg         *config.CompanyPurgeConfig
+	topic       string
+	eventSource string
+}
+
+// NewCompanyPurger creates a CompanyPurger.
+// A nil outbox disables event publishing, a nil metrics records nothing.
+func NewCompanyPurger(
+	repo CompanyRecycleBin,
+	transactor Transactor,
+	outbox EventOutbox,
+	logger *logging.Logger,
+	metrics *metrics.Metrics,
+	cfg *config.CompanyPurgeConfig,
+	topic,
+	eventSource string,
+) *CompanyPurger {
+	return &CompanyPurger{
+		repo:        repo,
+		transactor:  transactor,
+		outbox:      outbox,
+		logger:      logger,
+		metrics:     metrics,
+		cfg:         cfg,
+		topic:       topic,
+		eventSource: eventSource,
+	}
+}
+
+// Run purges expired deletions every configured interval until ctx is cancelled.
+func (p *CompanyPurger) Run(ctx context.Context) {
+	p.logger.Info(
+		"Company purge job started",
+		zap.Duration(logging.FieldPurgeRetention, p.cfg.Retention),
+		zap.Duration(logging.FieldPurgeInterval, p.cfg.Interval),
+	)
+
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := p.Purge(ctx)
+			if err != nil {
+				p.logger.Error("Failed to purge deleted companies", zap.Error(err))
+			}
+			if purged > 0 {
+				p.logger.Info("Purged deleted companies", zap.Int(logging.FieldPurgedCount, purged))
+			}
+		}
+	}
+}
+
+// Purge removes every company deleted longer than the retention period ago, batch after batch,
+// and returns how many it removed. Each batch is committed along with its company.purged events.
+func (p *CompanyPurger) Purge(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-p.cfg.Retention)
+	total := 0
+	for ctx.Err() == nil {
+		purged, err := p.purgeBatch(ctx, cutoff)
+		if err != nil {
+			return total, err
+		}
+		total += purged
+		if purged < p.cfg.BatchSize {
+			break
+		}
+	}
+	return total, nil
+}
+
+func (p *CompanyPurger) purgeBatch(ctx context.Context, cutoff time.Time) (int, error) {
+	var purged []*domain.Company
+	err := p.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if purged, err = p.repo.PurgeDeleted(ctx, cutoff, p.cfg.BatchSize); err != nil {
+			return err
+		}
+		for _, c := range purged {
+			data := CompanyPurgedEventData{
+				SchemaVersion: EventSchemaVersion,
+				DeletedBy:     c.DeletedBy,
+				Company:       newCompanySnapshot(c),
+			}
+			if c.DeletedAt != nil {
+				data.DeletedAt = *c.DeletedAt
+			}
+			err = enqueueEvent(ctx, p.outbox, p.topic, p.eventSource, EventTypeCompanyPurged, *c.ID, data)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	p.metrics.CompaniesPurged(len(purged))
+	return len(purged), nil
+}
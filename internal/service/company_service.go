@@ -2,134 +2,627 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/Laelapa/CompanyRegistry/auth/tokenauthority"
+	"github.com/Laelapa/CompanyRegistry/internal/authz"
 	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/metrics"
+	"github.com/Laelapa/CompanyRegistry/internal/tracing"
 	"github.com/Laelapa/CompanyRegistry/logging"
 
 	"github.com/google/uuid"
-	"go.uber.org/zap"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-type CompanyRepository interface {
-	Create(ctx context.Context, c *domain.Company) (*domain.Company, error)
+// CompanyReader is the read side of the company persistence port.
+type CompanyReader interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.Company, error)
 	GetByName(ctx context.Context, name string) (*domain.Company, error)
+	List(ctx context.Context, params domain.CompanyListParams) (*domain.CompanyPage, error)
+	Search(ctx context.Context, query string, limit int32) ([]*domain.CompanySearchHit, error)
+	// Export hands every company matching the filter to fn, it must run within a transaction
+	Export(ctx context.Context, filter domain.CompanyFilter, fn func(*domain.Company) error) error
+}
+
+// CompanyWriter is the write side of the company persistence port.
+type CompanyWriter interface {
+	Create(ctx context.Context, c *domain.Company) (*domain.Company, error)
+	// CreateMany creates companies in bulk, skipping those whose name is taken, and returns the created ones
+	CreateMany(ctx context.Context, companies []*domain.Company, createdBy uuid.UUID) ([]*domain.Company, error)
+	GetByIDForUpdate(ctx context.Context, id uuid.UUID) (*domain.Company, error)
 	Update(ctx context.Context, c *domain.Company) (*domain.Company, error)
-	Delete(ctx context.Context, id uuid.UUID) error
+	// Delete soft-deletes a company, it's kept for restoring until it's purged
+	Delete(ctx context.Context, id, deletedBy uuid.UUID) (*domain.Company, error)
+}
+
+// CompanyRecycleBin holds deleted companies until they're restored or purged.
+type CompanyRecycleBin interface {
+	GetDeletedByIDForUpdate(ctx context.Context, id uuid.UUID) (*domain.Company, error)
+	Restore(ctx context.Context, id, restoredBy uuid.UUID) (*domain.Company, error)
+	PurgeDeleted(ctx context.Context, deletedBefore time.Time, limit int) ([]*domain.Company, error)
+}
+
+// CompanyLifecycle moves companies between the statuses of their lifecycle.
+type CompanyLifecycle interface {
+	// UpdateStatus sets the status of a company, whether the transition is allowed isn't checked
+	UpdateStatus(
+		ctx context.Context,
+		id uuid.UUID,
+		status domain.CompanyStatus,
+		updatedBy uuid.UUID,
+	) (*domain.Company, error)
+}
+
+// CompanyHistory persists the revisions of companies.
+type CompanyHistory interface {
+	RecordRevision(ctx context.Context, rev *domain.CompanyRevision) error
+	// RecordCreations records the creation of companies created in bulk
+	RecordCreations(ctx context.Context, companyIDs []uuid.UUID) error
+	GetRevision(ctx context.Context, companyID uuid.UUID, revision int64) (*domain.CompanyRevision, error)
+	ListRevisions(
+		ctx context.Context,
+		companyID uuid.UUID,
+		before *int64,
+		limit int32,
+	) (*domain.CompanyRevisionPage, error)
+	// GetAsOf returns the state a company was in at the given instant
+	GetAsOf(ctx context.Context, id uuid.UUID, asOf time.Time) (*domain.Company, error)
+}
+
+type CompanyRepository interface {
+	CompanyReader
+	CompanyWriter
+	CompanyLifecycle
+	CompanyHistory
+	CompanyRecycleBin
+}
+
+// CompanyCache holds companies by name in front of the repository. It is filled on reads and
+// entries are removed after every committed mutation. *cache.Cache satisfies it.
+type CompanyCache interface {
+	Get(name string) (*domain.Company, bool)
+	Generation() uint64
+	// Add must drop the company if anything was removed since the generation was taken
+	Add(name string, c *domain.Company, generation uint64)
+	Remove(names ...string)
 }
 
 type CompanyService struct {
 	repo           CompanyRepository
+	types          *CompanyTypeService
+	nameCache      CompanyCache
+	transactor     Transactor
+	outbox         EventOutbox
 	tokenAuthority *tokenauthority.TokenAuthority
 	logger         *logging.Logger
-	producer       EventProducer
+	metrics        *metrics.Metrics
+	tracer         trace.Tracer
 	topic          string
+	eventSource    string
 }
 
-const defaultPublishEventTimeout = 5 * time.Second
+const (
+	defaultCompanyPageSize = 20
+	maxCompanyPageSize     = 100
+	maxSearchQueryLength   = 200
+)
 
+// NewCompanyService creates a CompanyService.
+// A nil nameCache disables caching lookups by name.
+// A nil outbox disables event publishing, eventSource is the CloudEvents source of the published events.
+// A nil metrics records nothing.
 func NewCompanyService(
 	repo CompanyRepository,
+	types *CompanyTypeService,
+	nameCache CompanyCache,
+	transactor Transactor,
+	outbox EventOutbox,
 	tokenAuthority *tokenauthority.TokenAuthority,
 	logger *logging.Logger,
-	producer EventProducer,
-	topic string,
+	metrics *metrics.Metrics,
+	topic,
+	eventSource string,
 ) *CompanyService {
 	return &CompanyService{
 		repo:           repo,
+		types:          types,
+		nameCache:      nameCache,
+		transactor:     transactor,
+		outbox:         outbox,
 		tokenAuthority: tokenAuthority,
 		logger:         logger,
-		producer:       producer,
+		metrics:        metrics,
+		tracer:         tracing.Tracer(),
 		topic:          topic,
+		eventSource:    eventSource,
 	}
 }
 
-// GetByName retrieves a company by its name.
+// GetByID retrieves a company by its ID.
 // It returns domain.ErrNotFound if the company does not exist.
-func (u *CompanyService) GetByName(ctx context.Context, name string) (*domain.Company, error) {
-	return u.repo.GetByName(ctx, name)
+func (u *CompanyService) GetByID(ctx context.Context, id uuid.UUID) (_ *domain.Company, err error) {
+	ctx, span := u.tracer.Start(ctx, "CompanyService.GetByID", trace.WithAttributes(attrCompanyID(id)))
+	defer func() { tracing.End(span, err) }()
+
+	return u.repo.GetByID(ctx, id)
 }
 
-// Create creates a new company.
-// If uniqueness constraints are violated, it returns domain.ErrConflict.
-func (u *CompanyService) Create(ctx context.Context, c *domain.Company) (*domain.Company, error) {
-	if c.Name == nil {
-		return nil, fmt.Errorf("company name is required: %w", domain.ErrBadRequest)
+// GetAsOf retrieves the state a company was in at the given instant, even if it has been deleted since.
+// It returns domain.ErrNotFound if the company did not exist at that instant
+// and domain.ErrBadRequest if the instant is in the future.
+func (u *CompanyService) GetAsOf(ctx context.Context, id uuid.UUID, asOf time.Time) (_ *domain.Company, err error) {
+	ctx, span := u.tracer.Start(ctx, "CompanyService.GetAsOf", trace.WithAttributes(attrCompanyID(id)))
+	defer func() { tracing.End(span, err) }()
+
+	if err := checkAsOf(asOf); err != nil {
+		return nil, err
 	}
-	if c.EmployeeCount == nil {
-		return nil, fmt.Errorf("employee count is required: %w", domain.ErrBadRequest)
+	return u.repo.GetAsOf(ctx, id, asOf)
+}
+
+// GetByName retrieves a company by its name, from the cache if it's enabled.
+// The company returned must not be modified, it may be shared with other callers.
+// It returns domain.ErrNotFound if the company does not exist.
+func (u *CompanyService) GetByName(ctx context.Context, name string) (_ *domain.Company, err error) {
+	ctx, span := u.tracer.Start(ctx, "CompanyService.GetByName")
+	defer func() { tracing.End(span, err) }()
+
+	if u.nameCache == nil {
+		return u.repo.GetByName(ctx, name)
 	}
-	if c.Registered == nil {
-		return nil, fmt.Errorf("registered status is required: %w", domain.ErrBadRequest)
+	if company, ok := u.nameCache.Get(name); ok {
+		span.SetAttributes(attribute.Bool("cache.hit", true))
+		return company, nil
 	}
-	if c.CompanyType == nil {
-		return nil, fmt.Errorf("company type is required: %w", domain.ErrBadRequest)
+	span.SetAttributes(attribute.Bool("cache.hit", false))
+
+	generation := u.nameCache.Generation()
+	company, err := u.repo.GetByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	u.nameCache.Add(name, company, generation)
+	return company, nil
+}
+
+// List retrieves a page of companies matching the filter.
+// A zero limit falls back to the default page size and an empty sort field to creation time.
+// If params.AsOf is set, the companies are listed as they were at that instant, including those deleted since.
+// It returns domain.ErrBadRequest if the listing parameters are invalid.
+func (u *CompanyService) List(
+	ctx context.Context,
+	params domain.CompanyListParams,
+) (_ *domain.CompanyPage, err error) {
+	ctx, span := u.tracer.Start(ctx, "CompanyService.List")
+	defer func() { tracing.End(span, err) }()
+
+	if params.Limit == 0 {
+		params.Limit = defaultCompanyPageSize
+	}
+	if params.Limit < 0 || params.Limit > maxCompanyPageSize {
+		return nil, fmt.Errorf("page size must be between 1 and %d: %w", maxCompanyPageSize, domain.ErrBadRequest)
+	}
+
+	switch params.SortBy {
+	case "":
+		params.SortBy = domain.CompanySortByCreatedAt
+	case domain.CompanySortByCreatedAt, domain.CompanySortByName, domain.CompanySortByEmployeeCount:
+	default:
+		return nil, fmt.Errorf("unsupported sort field %q: %w", params.SortBy, domain.ErrBadRequest)
 	}
 
-	createdCompany, err := u.repo.Create(ctx, c)
+	if err := checkCompanyFilter(params.Filter); err != nil {
+		return nil, err
+	}
+
+	if params.AsOf != nil {
+		if err := checkAsOf(*params.AsOf); err != nil {
+			return nil, err
+		}
+	}
+
+	return u.repo.List(ctx, params)
+}
+
+// Export streams every company matching the filter to fn, oldest first, without holding them all in memory.
+// The companies are read from a single snapshot, so the export is consistent however long it takes.
+// An error returned by fn stops the export and is returned as is.
+// It returns domain.ErrBadRequest if the filter is invalid.
+func (u *CompanyService) Export(
+	ctx context.Context,
+	filter domain.CompanyFilter,
+	fn func(*domain.Company) error,
+) (err error) {
+	ctx, span := u.tracer.Start(ctx, "CompanyService.Export")
+	defer func() { tracing.End(span, err) }()
+
+	if err = checkCompanyFilter(filter); err != nil {
+		return err
+	}
+
+	return u.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		return u.repo.Export(ctx, filter, fn)
+	})
+}
+
+// Search finds companies by (partial or misspelled) name and by words in their description.
+// Results are ordered by relevance, a zero limit falls back to the default page size.
+// It returns domain.ErrBadRequest if the query is blank or too long.
+func (u *CompanyService) Search(
+	ctx context.Context,
+	query string,
+	limit int32,
+) (_ []*domain.CompanySearchHit, err error) {
+	ctx, span := u.tracer.Start(ctx, "CompanyService.Search")
+	defer func() { tracing.End(span, err) }()
+
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("search query is required: %w", domain.ErrBadRequest)
+	}
+	if utf8.RuneCountInString(query) > maxSearchQueryLength {
+		return nil, fmt.Errorf("search query cannot exceed %d characters: %w", maxSearchQueryLength, domain.ErrBadRequest)
+	}
+
+	if limit == 0 {
+		limit = defaultCompanyPageSize
+	}
+	if limit < 0 || limit > maxCompanyPageSize {
+		return nil, fmt.Errorf("result limit must be between 1 and %d: %w", maxCompanyPageSize, domain.ErrBadRequest)
+	}
+
+	return u.repo.Search(ctx, query, limit)
+}
+
+// Create creates a new company pending approval, it's approved through Transition.
+// Companies in any other status are refused with domain.ErrBadRequest.
+// If the company type is unknown or retired, it returns domain.ErrUnknownCompanyType.
+// If uniqueness constraints are violated, it returns domain.ErrConflict.
+func (u *CompanyService) Create(ctx context.Context, c *domain.Company) (_ *domain.Company, err error) {
+	ctx, span := u.tracer.Start(ctx, "CompanyService.Create")
+	defer func() { tracing.End(span, err) }()
+
+	if err = checkNewCompany(c); err != nil {
+		return nil, err
+	}
+	if err = u.types.checkAssignable(ctx, *c.CompanyType); err != nil {
+		return nil, err
+	}
+
+	var createdCompany *domain.Company
+	err = u.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if createdCompany, err = u.repo.Create(ctx, c); err != nil {
+			return err
+		}
+		err = u.recordRevision(ctx, domain.CompanyOperationCreate, createdCompany, *createdCompany.Version, *c.CreatedBy)
+		if err != nil {
+			return err
+		}
+		return enqueueEvent(
+			ctx, u.outbox, u.topic, u.eventSource, EventTypeCompanyCreated, *createdCompany.ID,
+			CompanyEventData{
+				SchemaVersion: EventSchemaVersion,
+				Actor:         *c.CreatedBy,
+				Company:       newCompanySnapshot(createdCompany),
+			},
+		)
+	})
 	if err != nil {
 		return nil, err
 	}
+	span.SetAttributes(attrCompanyID(*createdCompany.ID))
+	u.metrics.CompanyCreated()
 
-	go u.publishEvent(context.WithoutCancel(ctx), "CREATE", *createdCompany.ID)
 	return createdCompany, nil
 }
 
-// Update updates an existing company.
+// Update updates an existing company on behalf of actor.
+// If c carries a version, the update only applies to that version of the company.
 // If the company does not exist, it returns domain.ErrNotFound.
+// If the actor may not modify the company, it returns an *authz.Denial wrapping domain.ErrForbidden.
+// If the company has moved past the version of c, it returns domain.ErrPreconditionFailed.
+// If c changes the company type to an unknown or retired one, it returns domain.ErrUnknownCompanyType,
+// a company keeps its type after it's retired.
+// If c carries registered and it disagrees with the company's status, it returns domain.ErrBadRequest:
+// registered follows the status, which only changes through Transition.
 // If uniqueness constraints are violated, it returns domain.ErrConflict.
-func (u *CompanyService) Update(ctx context.Context, c *domain.Company) (*domain.Company, error) {
+func (u *CompanyService) Update(
+	ctx context.Context,
+	actor authz.Principal,
+	c *domain.Company,
+) (_ *domain.Company, err error) {
+	ctx, span := u.tracer.Start(ctx, "CompanyService.Update")
+	defer func() { tracing.End(span, err) }()
+
 	if c.ID == nil {
 		return nil, fmt.Errorf("company ID is required: %w", domain.ErrBadRequest)
 	}
-	if c.UpdatedBy == nil {
-		return nil, fmt.Errorf("updated_by is required: %w", domain.ErrBadRequest)
-	}
+	span.SetAttributes(attrCompanyID(*c.ID))
+	c.UpdatedBy = &actor.UserID
 
-	updatedCompany, err := u.repo.Update(ctx, c)
+	var previous, updatedCompany *domain.Company
+	err = u.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		// Lock the row so both the authorization and the event see the state this update is applied to
+		var err error
+		if previous, err = u.repo.GetByIDForUpdate(ctx, *c.ID); err != nil {
+			return err
+		}
+		if err = authz.CanModifyCompany(actor, previous); err != nil {
+			return err
+		}
+		if err = checkCompanyVersion(previous, c.Version); err != nil {
+			return err
+		}
+		if c.Registered != nil && *c.Registered != previous.Status.Registered() {
+			return fmt.Errorf(
+				"registered %t disagrees with status %s: %w", *c.Registered, *previous.Status, domain.ErrBadRequest,
+			)
+		}
+		if c.CompanyType != nil && *c.CompanyType != *previous.CompanyType {
+			if err = u.types.checkAssignable(ctx, *c.CompanyType); err != nil {
+				return err
+			}
+		}
+		if updatedCompany, err = u.repo.Update(ctx, c); err != nil {
+			return err
+		}
+		err = u.recordRevision(ctx, domain.CompanyOperationUpdate, updatedCompany, *updatedCompany.Version, actor.UserID)
+		if err != nil {
+			return err
+		}
+
+		before, after := newCompanySnapshot(previous), newCompanySnapshot(updatedCompany)
+		return enqueueEvent(
+			ctx, u.outbox, u.topic, u.eventSource, EventTypeCompanyUpdated, *updatedCompany.ID,
+			CompanyUpdatedEventData{
+				SchemaVersion: EventSchemaVersion,
+				Actor:         actor.UserID,
+				Before:        before,
+				After:         after,
+				ChangedFields: changedCompanyFields(before, after),
+			},
+		)
+	})
 	if err != nil {
 		return nil, err
 	}
+	u.forgetCompanies(*previous.Name, *updatedCompany.Name)
+	u.metrics.CompanyUpdated()
 
-	go u.publishEvent(context.WithoutCancel(ctx), "UPDATE", *updatedCompany.ID)
 	return updatedCompany, nil
 }
 
-// Delete deletes a company by ID.
+// Delete deletes a company by ID on behalf of actor. The company can be restored until it's purged.
+// A non-nil expectedVersion makes the deletion conditional on the company still being at that version.
 // It returns domain.ErrNotFound if the company does not exist.
-func (u *CompanyService) Delete(ctx context.Context, id uuid.UUID) error {
-	if err := u.repo.Delete(ctx, id); err != nil {
+// If the actor may not modify the company, it returns an *authz.Denial wrapping domain.ErrForbidden.
+// If the company has moved past expectedVersion, it returns domain.ErrPreconditionFailed.
+func (u *CompanyService) Delete(
+	ctx context.Context,
+	actor authz.Principal,
+	id uuid.UUID,
+	expectedVersion *int64,
+) (err error) {
+	ctx, span := u.tracer.Start(ctx, "CompanyService.Delete", trace.WithAttributes(attrCompanyID(id)))
+	defer func() { tracing.End(span, err) }()
+
+	var deletedCompany *domain.Company
+	err = u.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		company, err := u.repo.GetByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err = authz.CanModifyCompany(actor, company); err != nil {
+			return err
+		}
+		if err = checkCompanyVersion(company, expectedVersion); err != nil {
+			return err
+		}
+
+		if deletedCompany, err = u.repo.Delete(ctx, id, actor.UserID); err != nil {
+			return err
+		}
+		err = u.recordRevision(ctx, domain.CompanyOperationDelete, deletedCompany, *deletedCompany.Version, actor.UserID)
+		if err != nil {
+			return err
+		}
+		return enqueueEvent(
+			ctx, u.outbox, u.topic, u.eventSource, EventTypeCompanyDeleted, id,
+			CompanyEventData{
+				SchemaVersion: EventSchemaVersion,
+				Actor:         actor.UserID,
+				Company:       newCompanySnapshot(deletedCompany),
+			},
+		)
+	})
+	if err != nil {
 		return err
 	}
+	u.forgetCompanies(*deletedCompany.Name)
+	u.metrics.CompanyDeleted()
 
-	go u.publishEvent(context.WithoutCancel(ctx), "DELETE", id)
 	return nil
 }
 
-func (u *CompanyService) publishEvent(ctx context.Context, eventType string, id uuid.UUID) {
-	// pub/sub not configured
-	if u.producer == nil {
-		return
+// Restore undoes the deletion of a company on behalf of actor, as long as it hasn't been purged yet.
+// It returns domain.ErrNotFound if there's no deleted company with that ID.
+// If the actor may not modify the company, it returns an *authz.Denial wrapping domain.ErrForbidden.
+// If a company has taken its name in the meantime, it returns domain.ErrConflict.
+func (u *CompanyService) Restore(
+	ctx context.Context,
+	actor authz.Principal,
+	id uuid.UUID,
+) (_ *domain.Company, err error) {
+	ctx, span := u.tracer.Start(ctx, "CompanyService.Restore", trace.WithAttributes(attrCompanyID(id)))
+	defer func() { tracing.End(span, err) }()
+
+	var restoredCompany *domain.Company
+	err = u.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		company, err := u.repo.GetDeletedByIDForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err = authz.CanModifyCompany(actor, company); err != nil {
+			return err
+		}
+
+		if restoredCompany, err = u.repo.Restore(ctx, id, actor.UserID); err != nil {
+			return err
+		}
+		err = u.recordRevision(ctx, domain.CompanyOperationRestore, restoredCompany, *restoredCompany.Version, actor.UserID)
+		if err != nil {
+			return err
+		}
+		return enqueueEvent(
+			ctx, u.outbox, u.topic, u.eventSource, EventTypeCompanyRestored, id,
+			CompanyEventData{
+				SchemaVersion: EventSchemaVersion,
+				Actor:         actor.UserID,
+				Company:       newCompanySnapshot(restoredCompany),
+			},
+		)
+	})
+	if err != nil {
+		return nil, err
 	}
+	u.forgetCompanies(*restoredCompany.Name)
+	u.metrics.CompanyRestored()
 
-	ctx, cancel := context.WithTimeout(ctx, defaultPublishEventTimeout) //TODO: Should export timeout to config
-	defer cancel()
+	return restoredCompany, nil
+}
+
+// History retrieves a page of a company's revisions, newest first, starting below the before revision.
+// Deleted companies keep their history. A zero limit falls back to the default page size.
+// It returns domain.ErrNotFound if the company has never existed
+// and domain.ErrBadRequest if the page size is out of range.
+func (u *CompanyService) History(
+	ctx context.Context,
+	id uuid.UUID,
+	before *int64,
+	limit int32,
+) (_ *domain.CompanyRevisionPage, err error) {
+	ctx, span := u.tracer.Start(ctx, "CompanyService.History", trace.WithAttributes(attrCompanyID(id)))
+	defer func() { tracing.End(span, err) }()
+
+	if limit == 0 {
+		limit = defaultCompanyPageSize
+	}
+	if limit < 0 || limit > maxCompanyPageSize {
+		return nil, fmt.Errorf("page size must be between 1 and %d: %w", maxCompanyPageSize, domain.ErrBadRequest)
+	}
 
-	eventData := map[string]any{
-		"event": eventType,
+	page, err := u.repo.ListRevisions(ctx, id, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	// Later pages may legitimately be empty, a first page only is if there's no history at all
+	if before == nil && len(page.Revisions) == 0 {
+		return nil, fmt.Errorf("no history of company %s: %w", id, domain.ErrNotFound)
 	}
+	return page, nil
+}
+
+// Diff lists the user editable fields that differ between two revisions of a company,
+// with their values in the from and to revisions.
+// It returns domain.ErrNotFound if the company has no such revisions.
+func (u *CompanyService) Diff(
+	ctx context.Context,
+	id uuid.UUID,
+	from, to int64,
+) (_ []domain.CompanyFieldChange, err error) {
+	ctx, span := u.tracer.Start(ctx, "CompanyService.Diff", trace.WithAttributes(attrCompanyID(id)))
+	defer func() { tracing.End(span, err) }()
 
-	marshalledEvent, err := json.Marshal(eventData)
+	fromRev, err := u.repo.GetRevision(ctx, id, from)
+	if err != nil {
+		return nil, fmt.Errorf("revision %d: %w", from, err)
+	}
+	toRev, err := u.repo.GetRevision(ctx, id, to)
 	if err != nil {
-		u.logger.Error("Failed to marshal event", zap.Error(err))
-		return
+		return nil, fmt.Errorf("revision %d: %w", to, err)
+	}
+	return companyFieldChanges(newCompanySnapshot(fromRev.Company), newCompanySnapshot(toRev.Company)), nil
+}
+
+// recordRevision adds the state of c after an operation by actor to its history.
+// It must run in the transaction of the operation, so the history can't miss a change.
+func (u *CompanyService) recordRevision(
+	ctx context.Context,
+	op domain.CompanyOperation,
+	c *domain.Company,
+	revision int64,
+	actor uuid.UUID,
+) error {
+	return u.repo.RecordRevision(ctx, &domain.CompanyRevision{
+		Revision:  revision,
+		Operation: op,
+		Company:   c,
+		Actor:     &actor,
+	})
+}
+
+// forgetCompanies drops companies from the cache once a mutation of them is committed.
+// Dropping them any earlier would let a concurrent read cache the state the mutation replaces.
+func (u *CompanyService) forgetCompanies(names ...string) {
+	if u.nameCache != nil {
+		u.nameCache.Remove(names...)
 	}
+}
 
-	if pErr := u.producer.Produce(ctx, u.topic, id.String(), marshalledEvent); pErr != nil {
-		u.logger.Error("Failed to produce event", zap.Error(pErr))
-		return
+// checkNewCompany fails with domain.ErrBadRequest unless c carries everything a company is created with.
+func checkNewCompany(c *domain.Company) error {
+	if c.Name == nil {
+		return fmt.Errorf("company name is required: %w", domain.ErrBadRequest)
+	}
+	if c.EmployeeCount == nil {
+		return fmt.Errorf("employee count is required: %w", domain.ErrBadRequest)
+	}
+	if c.Status == nil {
+		return fmt.Errorf("company status is required: %w", domain.ErrBadRequest)
+	}
+	if *c.Status != domain.CompanyStatusPending {
+		return fmt.Errorf("companies are created pending, not %s: %w", *c.Status, domain.ErrBadRequest)
+	}
+	if c.CompanyType == nil {
+		return fmt.Errorf("company type is required: %w", domain.ErrBadRequest)
+	}
+	if c.CreatedBy == nil {
+		return fmt.Errorf("created_by is required: %w", domain.ErrBadRequest)
 	}
+	return nil
+}
+
+// checkCompanyVersion fails with domain.ErrPreconditionFailed unless the company is at the expected version.
+// A nil expected version matches any. The company should be locked, or it could change right after the check.
+func checkCompanyVersion(c *domain.Company, expected *int64) error {
+	if expected == nil || *c.Version == *expected {
+		return nil
+	}
+	return fmt.Errorf("company is at version %d, not %d: %w", *c.Version, *expected, domain.ErrPreconditionFailed)
+}
+
+// checkAsOf fails with domain.ErrBadRequest if asOf is in the future, whose state isn't known yet.
+func checkAsOf(asOf time.Time) error {
+	if asOf.After(time.Now()) {
+		return fmt.Errorf("as_of %s is in the future: %w", asOf.Format(time.RFC3339), domain.ErrBadRequest)
+	}
+	return nil
+}
+
+// checkCompanyFilter fails with domain.ErrBadRequest if no company could match the filter.
+func checkCompanyFilter(f domain.CompanyFilter) error {
+	if f.MinEmployeeCount != nil && f.MaxEmployeeCount != nil && *f.MinEmployeeCount > *f.MaxEmployeeCount {
+		return fmt.Errorf("employee count range is empty: %w", domain.ErrBadRequest)
+	}
+	return nil
+}
+
+func attrCompanyID(id uuid.UUID) attribute.KeyValue {
+	return attribute.String("company.id", id.String())
 }
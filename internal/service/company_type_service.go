@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CompanyTypeRepository persists the company type catalog.
+type CompanyTypeRepository interface {
+	Create(ctx context.Context, t *domain.CompanyTypeDefinition) (*domain.CompanyTypeDefinition, error)
+	Get(ctx context.Context, name domain.CompanyType) (*domain.CompanyTypeDefinition, error)
+	List(ctx context.Context, includeInactive bool) ([]*domain.CompanyTypeDefinition, error)
+	// Update leaves the nil fields of t as they are
+	Update(ctx context.Context, t *domain.CompanyTypeDefinition) (*domain.CompanyTypeDefinition, error)
+	Delete(ctx context.Context, name domain.CompanyType) error
+}
+
+// CompanyTypeService manages the catalog of types companies can be given.
+type CompanyTypeService struct {
+	repo   CompanyTypeRepository
+	tracer trace.Tracer
+}
+
+func NewCompanyTypeService(repo CompanyTypeRepository) *CompanyTypeService {
+	return &CompanyTypeService{
+		repo:   repo,
+		tracer: tracing.Tracer(),
+	}
+}
+
+// List retrieves the company types ordered by name, retired ones only if includeInactive is set.
+func (u *CompanyTypeService) List(
+	ctx context.Context,
+	includeInactive bool,
+) (_ []*domain.CompanyTypeDefinition, err error) {
+	ctx, span := u.tracer.Start(ctx, "CompanyTypeService.List")
+	defer func() { tracing.End(span, err) }()
+
+	return u.repo.List(ctx, includeInactive)
+}
+
+// Create adds an active company type to the catalog.
+// It returns domain.ErrBadRequest if the name is blank and domain.ErrConflict if the type already exists.
+func (u *CompanyTypeService) Create(
+	ctx context.Context,
+	t *domain.CompanyTypeDefinition,
+) (_ *domain.CompanyTypeDefinition, err error) {
+	ctx, span := u.tracer.Start(ctx, "CompanyTypeService.Create")
+	defer func() { tracing.End(span, err) }()
+
+	if t.Name == nil || strings.TrimSpace(string(*t.Name)) == "" {
+		return nil, fmt.Errorf("company type name is required: %w", domain.ErrBadRequest)
+	}
+	span.SetAttributes(attrCompanyType(*t.Name))
+
+	return u.repo.Create(ctx, t)
+}
+
+// Update changes the description of a company type or retires and reinstates it.
+// Retiring a type leaves the companies that have it as they are.
+// It returns domain.ErrNotFound if the type does not exist.
+func (u *CompanyTypeService) Update(
+	ctx context.Context,
+	t *domain.CompanyTypeDefinition,
+) (_ *domain.CompanyTypeDefinition, err error) {
+	ctx, span := u.tracer.Start(ctx, "CompanyTypeService.Update")
+	defer func() { tracing.End(span, err) }()
+
+	if t.Name == nil {
+		return nil, fmt.Errorf("company type name is required: %w", domain.ErrBadRequest)
+	}
+	span.SetAttributes(attrCompanyType(*t.Name))
+
+	return u.repo.Update(ctx, t)
+}
+
+// Delete removes a company type from the catalog.
+// It returns domain.ErrNotFound if the type does not exist and domain.ErrConflict if any company,
+// including deleted ones awaiting the purge, still has it. Such types can be retired instead.
+func (u *CompanyTypeService) Delete(ctx context.Context, name domain.CompanyType) (err error) {
+	ctx, span := u.tracer.Start(ctx, "CompanyTypeService.Delete", trace.WithAttributes(attrCompanyType(name)))
+	defer func() { tracing.End(span, err) }()
+
+	return u.repo.Delete(ctx, name)
+}
+
+// checkAssignable fails with domain.ErrUnknownCompanyType unless companies can be given the type,
+// that is unless it's in the catalog and active.
+func (u *CompanyTypeService) checkAssignable(ctx context.Context, name domain.CompanyType) error {
+	t, err := u.repo.Get(ctx, name)
+	if errors.Is(err, domain.ErrNotFound) {
+		return fmt.Errorf("company type %q: %w", name, domain.ErrUnknownCompanyType)
+	}
+	if err != nil {
+		return err
+	}
+	if !*t.Active {
+		return fmt.Errorf("company type %q is retired: %w", name, domain.ErrUnknownCompanyType)
+	}
+	return nil
+}
+
+// assignable retrieves the names of the types companies can be given, for checking many companies at once.
+func (u *CompanyTypeService) assignable(ctx context.Context) (map[domain.CompanyType]struct{}, error) {
+	types, err := u.repo.List(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[domain.CompanyType]struct{}, len(types))
+	for _, t := range types {
+		names[*t.Name] = struct{}{}
+	}
+	return names, nil
+}
+
+func attrCompanyType(name domain.CompanyType) attribute.KeyValue {
+	return attribute.String("company_type.name", string(name))
+}
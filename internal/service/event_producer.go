@@ -5,5 +5,5 @@ import (
 )
 
 type EventProducer interface {
-	Produce(ctx context.Context, topic string, key string, value []byte) error
+	Produce(ctx context.Context, topic string, key string, value []byte, headers map[string]string) error
 }
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// CloudEvent is the CloudEvents 1.0 envelope of every published event, in structured JSON mode.
+// See https://github.com/cloudevents/spec/blob/v1.0.2/cloudevents/spec.md
+type CloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Time            time.Time `json:"time"`
+	Subject         string    `json:"subject"`
+	DataContentType string    `json:"datacontenttype"`
+	Data            any       `json:"data"`
+}
+
+// CompanySnapshot is the full state of a company as carried in event payloads.
+type CompanySnapshot struct {
+	ID            uuid.UUID  `json:"id"`
+	Name          string     `json:"name"`
+	Description   *string    `json:"description"`
+	EmployeeCount int32      `json:"employee_count"`
+	Status        string     `json:"status"`
+	Registered    bool       `json:"registered"`
+	CompanyType   string     `json:"company_type"`
+	CreatedBy     *uuid.UUID `json:"created_by"`
+	UpdatedBy     *uuid.UUID `json:"updated_by"`
+}
+
+// CompanyEventData is the payload of company.created, company.deleted and company.restored events.
+// Deletions carry the last state of the company.
+type CompanyEventData struct {
+	SchemaVersion int             `json:"schema_version"`
+	Actor         uuid.UUID       `json:"actor"`
+	Company       CompanySnapshot `json:"company"`
+}
+
+// CompanyUpdatedEventData is the payload of company.updated events.
+type CompanyUpdatedEventData struct {
+	SchemaVersion int             `json:"schema_version"`
+	Actor         uuid.UUID       `json:"actor"`
+	Before        CompanySnapshot `json:"before"`
+	After         CompanySnapshot `json:"after"`
+	ChangedFields []string        `json:"changed_fields"`
+}
+
+// CompanyStatusChangedEventData is the payload of company.status_changed events, sent for every transition.
+type CompanyStatusChangedEventData struct {
+	SchemaVersion int             `json:"schema_version"`
+	Actor         uuid.UUID       `json:"actor"`
+	Action        string          `json:"action"`
+	From          string          `json:"from"`
+	To            string          `json:"to"`
+	Reason        string          `json:"reason"`
+	Company       CompanySnapshot `json:"company"`
+}
+
+// CompanyPurgedEventData is the payload of company.purged events, sent when a deleted company is removed for good.
+// Purges are done by the service itself rather than on behalf of a user.
+type CompanyPurgedEventData struct {
+	SchemaVersion int             `json:"schema_version"`
+	DeletedAt     time.Time       `json:"deleted_at"`
+	DeletedBy     *uuid.UUID      `json:"deleted_by"`
+	Company       CompanySnapshot `json:"company"`
+}
+
+// UserSnapshot is the public state of a user as carried in event payloads.
+type UserSnapshot struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+	Role     string    `json:"role"`
+}
+
+// UserEventData is the payload of user events.
+type UserEventData struct {
+	SchemaVersion int          `json:"schema_version"`
+	Actor         uuid.UUID    `json:"actor"`
+	User          UserSnapshot `json:"user"`
+}
+
+const (
+	cloudEventsSpecVersion = "1.0"
+	eventDataContentType   = "application/json"
+
+	// EventSchemaVersion is the version of the event data schemas.
+	// It is bumped on breaking changes, additive changes keep it as is.
+	EventSchemaVersion = 1
+
+	EventTypeCompanyCreated       = "company.created"
+	EventTypeCompanyUpdated       = "company.updated"
+	EventTypeCompanyDeleted       = "company.deleted"
+	EventTypeCompanyRestored      = "company.restored"
+	EventTypeCompanyStatusChanged = "company.status_changed"
+	EventTypeCompanyPurged        = "company.purged"
+	EventTypeUserRegistered       = "user.registered"
+	EventTypeUserRoleAssigned     = "user.role_assigned"
+)
+
+// newCompanySnapshot flattens a company read from the repository.
+func newCompanySnapshot(c *domain.Company) CompanySnapshot {
+	s := CompanySnapshot{
+		Description: c.Description,
+	}
+	if c.ID != nil {
+		s.ID = *c.ID
+	}
+	if c.Name != nil {
+		s.Name = *c.Name
+	}
+	if c.EmployeeCount != nil {
+		s.EmployeeCount = *c.EmployeeCount
+	}
+	if c.Status != nil {
+		s.Status = string(*c.Status)
+	}
+	if c.Registered != nil {
+		s.Registered = *c.Registered
+	}
+	if c.CompanyType != nil {
+		s.CompanyType = string(*c.CompanyType)
+	}
+	// The repository reports a missing author as uuid.Nil
+	if c.CreatedBy != nil && *c.CreatedBy != uuid.Nil {
+		s.CreatedBy = c.CreatedBy
+	}
+	if c.UpdatedBy != nil && *c.UpdatedBy != uuid.Nil {
+		s.UpdatedBy = c.UpdatedBy
+	}
+	return s
+}
+
+// newUserSnapshot flattens a user read from the repository, leaving out the credentials.
+func newUserSnapshot(u *domain.User) UserSnapshot {
+	s := UserSnapshot{
+		ID:       *u.ID,
+		Username: *u.Username,
+	}
+	if u.Role != nil {
+		s.Role = string(*u.Role)
+	}
+	return s
+}
+
+// changedCompanyFields lists the JSON names of the user editable fields that differ between two snapshots.
+func changedCompanyFields(before, after CompanySnapshot) []string {
+	changes := companyFieldChanges(before, after)
+	changed := make([]string, 0, len(changes))
+	for _, c := range changes {
+		changed = append(changed, c.Field)
+	}
+	return changed
+}
+
+// companyFieldChanges lists the user editable fields that differ between two snapshots, by their JSON names.
+func companyFieldChanges(before, after CompanySnapshot) []domain.CompanyFieldChange {
+	changes := []domain.CompanyFieldChange{}
+	if before.Name != after.Name {
+		changes = append(changes, domain.CompanyFieldChange{Field: "name", From: before.Name, To: after.Name})
+	}
+	if !equalStringPtrs(before.Description, after.Description) {
+		changes = append(changes, domain.CompanyFieldChange{
+			Field: "description",
+			From:  stringPtrValue(before.Description),
+			To:    stringPtrValue(after.Description),
+		})
+	}
+	if before.EmployeeCount != after.EmployeeCount {
+		changes = append(changes, domain.CompanyFieldChange{
+			Field: "employee_count",
+			From:  before.EmployeeCount,
+			To:    after.EmployeeCount,
+		})
+	}
+	if before.Status != after.Status {
+		changes = append(changes, domain.CompanyFieldChange{
+			Field: "status",
+			From:  before.Status,
+			To:    after.Status,
+		})
+	}
+	if before.CompanyType != after.CompanyType {
+		changes = append(changes, domain.CompanyFieldChange{
+			Field: "company_type",
+			From:  before.CompanyType,
+			To:    after.CompanyType,
+		})
+	}
+	return changes
+}
+
+// stringPtrValue unwraps s, keeping an unset value nil rather than a typed nil pointer.
+func stringPtrValue(s *string) any {
+	if s == nil {
+		return nil
+	}
+	return *s
+}
+
+func equalStringPtrs(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
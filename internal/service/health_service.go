@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+)
+
+// DatabaseHealth reports on the database the service depends on.
+type DatabaseHealth interface {
+	Ping(ctx context.Context) error
+	SchemaVersion(ctx context.Context) (int64, error)
+}
+
+// BrokerHealth reports on the message broker events are published to.
+// *kgo.Client satisfies it.
+type BrokerHealth interface {
+	Ping(ctx context.Context) error
+}
+
+// HealthService checks whether the service is ready to receive traffic.
+type HealthService struct {
+	db                    DatabaseHealth
+	broker                BrokerHealth
+	expectedSchemaVersion int64
+	checkTimeout          time.Duration
+	draining              atomic.Bool
+}
+
+const (
+	DependencyDatabase   = "database"
+	DependencyMigrations = "migrations"
+	DependencyKafka      = "kafka"
+)
+
+// NewHealthService creates a HealthService.
+// A nil broker means event publishing is not configured, expectedSchemaVersion is the latest migration
+// this build ships with, and checkTimeout bounds every dependency check.
+func NewHealthService(
+	db DatabaseHealth,
+	broker BrokerHealth,
+	expectedSchemaVersion int64,
+	checkTimeout time.Duration,
+) *HealthService {
+	return &HealthService{
+		db:                    db,
+		broker:                broker,
+		expectedSchemaVersion: expectedSchemaVersion,
+		checkTimeout:          checkTimeout,
+	}
+}
+
+// Drain makes every following readiness check fail, so load balancers stop routing traffic
+// to the instance before it shuts down.
+func (s *HealthService) Drain() {
+	s.draining.Store(true)
+}
+
+// Readiness checks the dependencies concurrently.
+// The database and its schema are critical. The broker is not, events wait in the outbox while it's away.
+func (s *HealthService) Readiness(ctx context.Context) *domain.HealthReport {
+	if s.draining.Load() {
+		return &domain.HealthReport{Status: domain.HealthStatusDraining}
+	}
+
+	checks := map[string]func(ctx context.Context) domain.DependencyHealth{
+		DependencyDatabase: func(ctx context.Context) domain.DependencyHealth {
+			return s.check(ctx, true, s.db.Ping)
+		},
+		DependencyMigrations: s.checkSchemaVersion,
+		DependencyKafka: func(ctx context.Context) domain.DependencyHealth {
+			if s.broker == nil {
+				return domain.DependencyHealth{Status: domain.HealthStatusDisabled}
+			}
+			return s.check(ctx, false, s.broker.Ping)
+		},
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	report := &domain.HealthReport{
+		Status:       domain.HealthStatusReady,
+		Dependencies: make(map[string]domain.DependencyHealth, len(checks)),
+	}
+	for name, check := range checks {
+		wg.Go(func() {
+			ctx, cancel := context.WithTimeout(ctx, s.checkTimeout)
+			defer cancel()
+			result := check(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Dependencies[name] = result
+		})
+	}
+	wg.Wait()
+
+	for _, dep := range report.Dependencies {
+		if dep.Status != domain.HealthStatusDown {
+			continue
+		}
+		if dep.Critical {
+			report.Status = domain.HealthStatusNotReady
+			break
+		}
+		report.Status = domain.HealthStatusDegraded
+	}
+	return report
+}
+
+// checkSchemaVersion fails while the schema lags behind the migrations of this build.
+// A newer schema is fine, migrations are kept backwards compatible for rolling deployments.
+func (s *HealthService) checkSchemaVersion(ctx context.Context) domain.DependencyHealth {
+	var version int64
+	result := s.check(ctx, true, func(ctx context.Context) error {
+		var err error
+		if version, err = s.db.SchemaVersion(ctx); err != nil {
+			return err
+		}
+		if version < s.expectedSchemaVersion {
+			return fmt.Errorf("schema version %d is behind the expected %d", version, s.expectedSchemaVersion)
+		}
+		return nil
+	})
+	result.Details = map[string]any{
+		"version":          version,
+		"expected_version": s.expectedSchemaVersion,
+	}
+	return result
+}
+
+func (s *HealthService) check(
+	ctx context.Context,
+	critical bool,
+	probe func(ctx context.Context) error,
+) domain.DependencyHealth {
+	start := time.Now()
+	err := probe(ctx)
+	result := domain.DependencyHealth{
+		Status:   domain.HealthStatusUp,
+		Critical: critical,
+		Latency:  time.Since(start),
+	}
+	if err != nil {
+		result.Status = domain.HealthStatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/logging"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// IdempotencyRepository persists the records of Idempotency-Keys.
+type IdempotencyRepository interface {
+	Claim(ctx context.Context, rec *domain.IdempotencyRecord, now time.Time) (bool, error)
+	Get(ctx context.Context, userID uuid.UUID, key string) (*domain.IdempotencyRecord, error)
+	Complete(
+		ctx context.Context,
+		userID uuid.UUID,
+		key string,
+		resp *domain.RecordedResponse,
+		expiresAt time.Time,
+	) error
+	Release(ctx context.Context, userID uuid.UUID, key string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+// IdempotencyService makes requests sent with an Idempotency-Key take effect once,
+// answering retries with the response to the first attempt.
+type IdempotencyService struct {
+	repo        IdempotencyRepository
+	logger      *logging.Logger
+	ttl         time.Duration
+	lockTimeout time.Duration
+}
+
+// NewIdempotencyService creates an IdempotencyService.
+// Responses are replayed for ttl, and a key whose request hasn't completed within lockTimeout
+// is considered abandoned and may be claimed again.
+func NewIdempotencyService(
+	repo IdempotencyRepository,
+	logger *logging.Logger,
+	ttl,
+	lockTimeout time.Duration,
+) *IdempotencyService {
+	return &IdempotencyService{
+		repo:        repo,
+		logger:      logger,
+		ttl:         ttl,
+		lockTimeout: lockTimeout,
+	}
+}
+
+// Begin claims a key of the user for the request identified by requestHash.
+// If the key has already been used for the same request, its recorded response is returned to be replayed.
+// Otherwise the caller holds the key and has to Complete or Release it.
+// It returns domain.ErrIdempotencyKeyReused if the key was used for a different request
+// and domain.ErrConflict while the first request with the key is still being handled.
+func (s *IdempotencyService) Begin(
+	ctx context.Context,
+	userID uuid.UUID,
+	key string,
+	requestHash []byte,
+) (*domain.RecordedResponse, error) {
+	now := time.Now().UTC()
+	claimed, err := s.repo.Claim(ctx, &domain.IdempotencyRecord{
+		UserID:      userID,
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   now.Add(s.lockTimeout),
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+	if claimed {
+		return nil, nil //nolint:nilnil // nothing to replay, the caller handles the request
+	}
+
+	rec, err := s.repo.Get(ctx, userID, key)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			// Released by the request holding it in the meantime
+			return nil, fmt.Errorf("idempotency key %q just released: %w", key, domain.ErrConflict)
+		}
+		return nil, err
+	}
+	if !bytes.Equal(rec.RequestHash, requestHash) {
+		return nil, fmt.Errorf("idempotency key %q used for a different request: %w", key, domain.ErrIdempotencyKeyReused)
+	}
+	if rec.Response == nil {
+		return nil, fmt.Errorf("idempotency key %q still in use: %w", key, domain.ErrConflict)
+	}
+	return rec.Response, nil
+}
+
+// Complete records the response to the request holding a key, to be replayed to its retries.
+func (s *IdempotencyService) Complete(
+	ctx context.Context,
+	userID uuid.UUID,
+	key string,
+	resp *domain.RecordedResponse,
+) error {
+	return s.repo.Complete(ctx, userID, key, resp, time.Now().UTC().Add(s.ttl))
+}
+
+// RunPruning removes expired records every interval until ctx is cancelled.
+// Claims take over expired records of their own key, pruning only keeps the table from growing.
+func (s *IdempotencyService) RunPruning(ctx context.Context, interval time.Duration) {
+	s.logger.Info("Idempotency key pruning started", zap.Duration(logging.FieldPruneInterval, interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pruned, err := s.Prune(ctx)
+			if err != nil {
+				s.logger.Error("Failed to prune expired idempotency keys", zap.Error(err))
+			}
+			if pruned > 0 {
+				s.logger.Info("Pruned expired idempotency keys", zap.Int(logging.FieldPrunedCount, pruned))
+			}
+		}
+	}
+}
+
+// Prune removes the records that have expired by now and returns how many it removed.
+func (s *IdempotencyService) Prune(ctx context.Context) (int, error) {
+	return s.repo.DeleteExpired(ctx, time.Now().UTC())
+}
+
+// Release frees a key without recording a response, e.g. because its request failed unexpectedly
+// and may be retried.
+func (s *IdempotencyService) Release(ctx context.Context, userID uuid.UUID, key string) error {
+	return s.repo.Release(ctx, userID, key)
+}
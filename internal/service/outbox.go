@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/util/ctxutils"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Transactor runs a unit of work atomically.
+// Repositories called with the context handed to fn must take part in the same transaction.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// EventOutbox is the write side of the transactional outbox used by the services.
+// Enqueued messages are published asynchronously by the OutboxRelay.
+type EventOutbox interface {
+	Enqueue(ctx context.Context, m *domain.OutboxMessage) error
+}
+
+// OutboxStore is the side of the transactional outbox the OutboxRelay works with.
+type OutboxStore interface {
+	Claim(ctx context.Context, limit int) ([]*domain.OutboxMessage, error)
+	MarkPublished(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, cause error, retryIn time.Duration) error
+	Park(ctx context.Context, id int64, cause error) error
+	Backlog(ctx context.Context) (*domain.OutboxBacklog, error)
+}
+
+// EventHeaderRequestID is the record header carrying the ID of the request that produced an event.
+const EventHeaderRequestID = "x-request-id"
+
+// enqueueEvent wraps data in a CloudEvents envelope and writes it to the outbox.
+// The subject doubles as the message key, keeping the events of an entity in order.
+// It should be called within the transaction of the mutation it describes,
+// so the event is stored if and only if the mutation is.
+func enqueueEvent(
+	ctx context.Context,
+	outbox EventOutbox,
+	topic,
+	source,
+	eventType string,
+	subject uuid.UUID,
+	data any,
+) error {
+	// pub/sub not configured
+	if outbox == nil {
+		return nil
+	}
+
+	event := CloudEvent{
+		SpecVersion:     cloudEventsSpecVersion,
+		ID:              uuid.NewString(),
+		Source:          source,
+		Type:            eventType,
+		Time:            time.Now().UTC(),
+		Subject:         subject.String(),
+		DataContentType: eventDataContentType,
+		Data:            data,
+	}
+
+	marshalledEvent, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	// The event is published later, carry the trace context and request ID over to the relay
+	headers := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, headers)
+	if requestID, ok := ctxutils.GetRequestIDFromContext(ctx); ok {
+		headers[EventHeaderRequestID] = requestID
+	}
+
+	return outbox.Enqueue(ctx, &domain.OutboxMessage{
+		Topic:   topic,
+		Key:     subject.String(),
+		Payload: marshalledEvent,
+		Headers: headers,
+	})
+}
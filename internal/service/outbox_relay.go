@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/config"
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/metrics"
+	"github.com/Laelapa/CompanyRegistry/logging"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/zap"
+)
+
+// Delay before the first retry of a failed event, doubled on every further failure
+const baseRetryBackoff = 1 * time.Second
+
+// OutboxRelay publishes the events stored in the transactional outbox.
+// Delivery is at-least-once: an event is only removed from the outbox after the producer
+// acknowledged it, so a crash in between leads to a duplicate rather than a lost event.
+// An event that fails the configured maximum of attempts is parked rather than retried forever,
+// which lets the later events of its key through, out of order with the parked one.
+type OutboxRelay struct {
+	store      OutboxStore
+	transactor Transactor
+	producer   EventProducer
+	logger     *logging.Logger
+	metrics    *metrics.Metrics
+	cfg        *config.OutboxConfig
+}
+
+// NewOutboxRelay creates an OutboxRelay, a nil metrics records nothing.
+func NewOutboxRelay(
+	store OutboxStore,
+	transactor Transactor,
+	producer EventProducer,
+	logger *logging.Logger,
+	metrics *metrics.Metrics,
+	cfg *config.OutboxConfig,
+) *OutboxRelay {
+	return &OutboxRelay{
+		store:      store,
+		transactor: transactor,
+		producer:   producer,
+		logger:     logger,
+		metrics:    metrics,
+		cfg:        cfg,
+	}
+}
+
+// Run polls the outbox until ctx is cancelled.
+// It then keeps draining the remaining events for up to the configured drain timeout before returning.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	r.logger.Info("Outbox relay started", zap.Duration(logging.FieldOutboxPollInterval, r.cfg.PollInterval))
+
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.drain()
+			return
+		case <-ticker.C:
+			r.relayPending(ctx)
+		}
+	}
+}
+
+func (r *OutboxRelay) drain() {
+	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.DrainTimeout)
+	defer cancel()
+
+	r.logger.Info("Draining event outbox...")
+	r.relayPending(ctx)
+
+	backlog, err := r.store.Backlog(ctx)
+	switch {
+	case err != nil:
+		r.logger.Error("Failed to read outbox backlog after draining", zap.Error(err))
+	case backlog.Pending > 0:
+		r.logger.Warn(
+			"Outbox relay stopped with events still pending, they will be published on next start",
+			zap.Int64(logging.FieldOutboxPending, backlog.Pending),
+			zap.Int64(logging.FieldOutboxParked, backlog.Parked),
+		)
+	default:
+		r.logger.Info("Event outbox drained")
+	}
+}
+
+// relayPending publishes batch after batch for as long as progress is being made,
+// then reports how far behind the outbox is.
+func (r *OutboxRelay) relayPending(ctx context.Context) {
+	for ctx.Err() == nil {
+		published, err := r.relayBatch(ctx)
+		if err != nil {
+			r.logger.Error("Failed to relay outbox batch", zap.Error(err))
+			return
+		}
+		if published == 0 {
+			break
+		}
+	}
+
+	r.reportBacklog(ctx)
+}
+
+// relayBatch claims a batch of due events and publishes them.
+// The claim holds row locks for the whole batch so concurrent relays never publish the same event.
+// Only the earliest event of a key is ever claimed, so the events of a batch are published concurrently
+// without breaking the per-key order, and the locks are held for about one publish timeout.
+func (r *OutboxRelay) relayBatch(ctx context.Context) (int, error) {
+	published := 0
+	err := r.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		messages, err := r.store.Claim(ctx, r.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		pErrs := make([]error, len(messages))
+		var wg sync.WaitGroup
+		for i, m := range messages {
+			wg.Go(func() { pErrs[i] = r.publish(ctx, m) })
+		}
+		wg.Wait()
+
+		for i, m := range messages {
+			if pErrs[i] != nil {
+				if err = r.handleFailure(ctx, m, pErrs[i]); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err = r.store.MarkPublished(ctx, m.ID); err != nil {
+				return err
+			}
+			published++
+		}
+		return nil
+	})
+	return published, err
+}
+
+// handleFailure schedules the retry of a message that failed to publish,
+// or parks it once it has failed the configured maximum of attempts.
+func (r *OutboxRelay) handleFailure(ctx context.Context, m *domain.OutboxMessage, pErr error) error {
+	attempts := m.Attempts + 1
+	fields := []zap.Field{
+		zap.Int64(logging.FieldOutboxMessageID, m.ID),
+		zap.String(logging.FieldKafkaTopic, m.Topic),
+		zap.Int32(logging.FieldOutboxAttempts, attempts),
+		zap.String(logging.FieldRequestID, m.Headers[EventHeaderRequestID]),
+		zap.Error(pErr),
+	}
+
+	if int(attempts) >= r.cfg.MaxAttempts {
+		r.logger.Error("Failed to publish outbox event too many times, parking it", fields...)
+		if err := r.store.Park(ctx, m.ID, pErr); err != nil {
+			return err
+		}
+		r.metrics.OutboxEventParked(m.Topic)
+		return nil
+	}
+
+	retryIn := r.retryBackoff(attempts)
+	r.logger.Warn(
+		"Failed to publish outbox event, will retry",
+		append(fields, zap.Duration(logging.FieldOutboxRetryIn, retryIn))...,
+	)
+	return r.store.MarkFailed(ctx, m.ID, pErr, retryIn)
+}
+
+func (r *OutboxRelay) publish(ctx context.Context, m *domain.OutboxMessage) error {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.PublishTimeout)
+	defer cancel()
+
+	// Continue the trace of the request that enqueued the message
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(m.Headers))
+	return r.producer.Produce(ctx, m.Topic, m.Key, m.Payload, m.Headers)
+}
+
+// retryBackoff doubles the delay for every failed attempt, capped at the configured maximum.
+func (r *OutboxRelay) retryBackoff(attempts int32) time.Duration {
+	backoff := baseRetryBackoff
+	for i := int32(1); i < attempts && backoff < r.cfg.MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, r.cfg.MaxRetryBackoff)
+}
+
+func (r *OutboxRelay) reportBacklog(ctx context.Context) {
+	backlog, err := r.store.Backlog(ctx)
+	if err != nil {
+		r.logger.Error("Failed to read outbox backlog", zap.Error(err))
+		return
+	}
+
+	fields := []zap.Field{
+		zap.Int64(logging.FieldOutboxPending, backlog.Pending),
+		zap.Duration(logging.FieldOutboxLag, backlog.Lag),
+		zap.Int64(logging.FieldOutboxParked, backlog.Parked),
+	}
+	if backlog.Pending > 0 && backlog.Lag > r.cfg.LagWarnThreshold {
+		r.logger.Warn("Event outbox is lagging behind", fields...)
+		return
+	}
+	r.logger.Debug("Event outbox backlog", fields...)
+}
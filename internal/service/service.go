@@ -3,4 +3,10 @@ package service
 type Service struct {
 	User    *UserService
 	Company *CompanyService
+	// CompanyType manages the catalog of company types
+	CompanyType *CompanyTypeService
+	Session     *SessionService
+	Health      *HealthService
+	// Idempotency replays the responses of requests sent with an Idempotency-Key
+	Idempotency *IdempotencyService
 }
@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/auth/tokenauthority"
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/logging"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// RefreshTokenRepository persists refresh tokens and their families.
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, t *domain.RefreshToken) error
+	GetRefreshTokenForUpdate(ctx context.Context, tokenHash []byte) (*domain.RefreshToken, error)
+	MarkRefreshTokenRotated(ctx context.Context, id uuid.UUID) error
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+}
+
+// AccessTokenRevocations tracks access tokens invalidated before their expiry.
+type AccessTokenRevocations interface {
+	RevokeAccessToken(ctx context.Context, jti uuid.UUID, expiresAt time.Time) error
+	IsAccessTokenRevoked(ctx context.Context, jti, familyID uuid.UUID) (bool, error)
+}
+
+type SessionRepository interface {
+	RefreshTokenRepository
+	AccessTokenRevocations
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+}
+
+// SessionService manages login sessions: a refresh token family together with the access tokens issued for it.
+type SessionService struct {
+	repo                 SessionRepository
+	users                UserRepository
+	transactor           Transactor
+	tokenAuthority       *tokenauthority.TokenAuthority
+	logger               *logging.Logger
+	refreshTokenLifetime time.Duration
+}
+
+const refreshTokenBytes = 32
+
+func NewSessionService(
+	repo SessionRepository,
+	users UserRepository,
+	transactor Transactor,
+	tokenAuthority *tokenauthority.TokenAuthority,
+	logger *logging.Logger,
+	refreshTokenLifetime time.Duration,
+) *SessionService {
+	return &SessionService{
+		repo:                 repo,
+		users:                users,
+		transactor:           transactor,
+		tokenAuthority:       tokenAuthority,
+		logger:               logger,
+		refreshTokenLifetime: refreshTokenLifetime,
+	}
+}
+
+// Start starts a new session for the user and returns its first token pair.
+func (s *SessionService) Start(ctx context.Context, user *domain.User) (*domain.TokenPair, error) {
+	return s.issueTokenPair(ctx, user, uuid.New())
+}
+
+// Refresh exchanges a refresh token for a new token pair of the same session.
+// Every refresh token can be exchanged once, presenting a rotated token again means
+// it has leaked, so the whole session is revoked.
+// The new access token carries the user's current role.
+// It returns domain.ErrBadCredentials if the refresh token is unknown, expired, revoked or reused.
+func (s *SessionService) Refresh(ctx context.Context, refreshToken string) (*domain.TokenPair, error) {
+	var pair *domain.TokenPair
+	var reused *domain.RefreshToken
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		stored, err := s.repo.GetRefreshTokenForUpdate(ctx, hashRefreshToken(refreshToken))
+		if err != nil {
+			if errors.Is(err, domain.ErrNotFound) {
+				return fmt.Errorf("unknown refresh token: %w", domain.ErrBadCredentials)
+			}
+			return err
+		}
+
+		switch {
+		case stored.RevokedAt != nil:
+			return fmt.Errorf("refresh token revoked: %w", domain.ErrBadCredentials)
+		case stored.RotatedAt != nil:
+			// The revocation has to be committed, so it's reported after the transaction
+			reused = stored
+			return s.repo.RevokeFamily(ctx, stored.FamilyID)
+		case !time.Now().Before(stored.ExpiresAt):
+			return fmt.Errorf("refresh token expired: %w", domain.ErrBadCredentials)
+		}
+
+		if err = s.repo.MarkRefreshTokenRotated(ctx, stored.ID); err != nil {
+			return err
+		}
+		user, err := s.users.GetByID(ctx, stored.UserID)
+		if err != nil {
+			return err
+		}
+		pair, err = s.issueTokenPair(ctx, user, stored.FamilyID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if reused != nil {
+		s.logger.Warn(
+			"Refresh token reuse detected, session revoked",
+			append(
+				s.logger.CtxFields(ctx),
+				zap.String(logging.FieldUserID, reused.UserID.String()),
+				zap.String(logging.FieldSessionID, reused.FamilyID.String()),
+			)...,
+		)
+		return nil, fmt.Errorf("refresh token reused: %w", domain.ErrBadCredentials)
+	}
+
+	return pair, nil
+}
+
+// Logout ends the session an access token belongs to.
+// The session's refresh tokens and every access token issued for it stop being accepted.
+func (s *SessionService) Logout(ctx context.Context, tokenID, sessionID uuid.UUID, expiresAt time.Time) error {
+	return s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.RevokeFamily(ctx, sessionID); err != nil {
+			return err
+		}
+		return s.repo.RevokeAccessToken(ctx, tokenID, expiresAt)
+	})
+}
+
+// IsRevoked reports whether an access token has been revoked before its expiry.
+func (s *SessionService) IsRevoked(ctx context.Context, tokenID, sessionID uuid.UUID) (bool, error) {
+	return s.repo.IsAccessTokenRevoked(ctx, tokenID, sessionID)
+}
+
+// RunPruning removes expired sessions every interval until ctx is cancelled.
+func (s *SessionService) RunPruning(ctx context.Context, interval time.Duration) {
+	s.logger.Info("Session pruning started", zap.Duration(logging.FieldPruneInterval, interval))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			pruned, err := s.Prune(ctx)
+			if err != nil {
+				s.logger.Error("Failed to prune expired sessions", zap.Error(err))
+			}
+			if pruned > 0 {
+				s.logger.Info("Pruned expired sessions", zap.Int(logging.FieldPrunedCount, pruned))
+			}
+		}
+	}
+}
+
+// Prune removes the revoked access tokens that have expired by now, along with the refresh token families
+// whose every token has, and returns how many rows it removed.
+func (s *SessionService) Prune(ctx context.Context) (int, error) {
+	return s.repo.DeleteExpired(ctx, time.Now().UTC())
+}
+
+// issueTokenPair stores a fresh refresh token of the family and issues an access token alongside it.
+func (s *SessionService) issueTokenPair(
+	ctx context.Context,
+	user *domain.User,
+	familyID uuid.UUID,
+) (*domain.TokenPair, error) {
+	raw := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(raw)
+
+	err := s.repo.CreateRefreshToken(ctx, &domain.RefreshToken{
+		UserID:    *user.ID,
+		FamilyID:  familyID,
+		TokenHash: hashRefreshToken(refreshToken),
+		ExpiresAt: time.Now().UTC().Add(s.refreshTokenLifetime),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	role := domain.RoleViewer
+	if user.Role != nil {
+		role = *user.Role
+	}
+	accessToken, expiresAt, err := s.tokenAuthority.IssueJWT(*user.ID, familyID, string(role))
+	if err != nil {
+		return nil, fmt.Errorf("failed to issue JWT: %w", err)
+	}
+
+	return &domain.TokenPair{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: expiresAt,
+		RefreshToken:         refreshToken,
+	}, nil
+}
+
+// hashRefreshToken hashes a refresh token for storage and lookup.
+// The tokens are random so a plain, unsalted hash is enough.
+func hashRefreshToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
@@ -2,69 +2,86 @@ package service
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"time"
 
-	"github.com/Laelapa/CompanyRegistry/auth/tokenauthority"
 	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/metrics"
+	"github.com/Laelapa/CompanyRegistry/internal/tracing"
 	"github.com/Laelapa/CompanyRegistry/logging"
 
 	"github.com/google/uuid"
-	"go.uber.org/zap"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserRepository interface {
 	Create(ctx context.Context, u *domain.User) (*domain.User, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error)
 	GetByUsername(ctx context.Context, username string) (*domain.User, error)
+	UpdateRole(ctx context.Context, id uuid.UUID, role domain.Role) (*domain.User, error)
 }
 
 type UserService struct {
-	repo           UserRepository
-	tokenAuthority *tokenauthority.TokenAuthority
-	logger         *logging.Logger
-	producer       EventProducer
-	topic          string
+	repo        UserRepository
+	transactor  Transactor
+	outbox      EventOutbox
+	sessions    *SessionService
+	logger      *logging.Logger
+	metrics     *metrics.Metrics
+	tracer      trace.Tracer
+	topic       string
+	eventSource string
 }
 
-const defaultEventPublishTimeout = 5 * time.Second
-
+// NewUserService creates a UserService.
+// A nil outbox disables event publishing, eventSource is the CloudEvents source of the published events.
+// A nil metrics records nothing.
 func NewUserService(
 	repo UserRepository,
-	tokenAuthority *tokenauthority.TokenAuthority,
+	transactor Transactor,
+	outbox EventOutbox,
+	sessions *SessionService,
 	logger *logging.Logger,
-	producer EventProducer,
-	topic string,
+	metrics *metrics.Metrics,
+	topic,
+	eventSource string,
 ) *UserService {
 	return &UserService{
-		repo:           repo,
-		tokenAuthority: tokenAuthority,
-		logger:         logger,
-		producer:       producer,
-		topic:          topic,
+		repo:        repo,
+		transactor:  transactor,
+		outbox:      outbox,
+		sessions:    sessions,
+		logger:      logger,
+		metrics:     metrics,
+		tracer:      tracing.Tracer(),
+		topic:       topic,
+		eventSource: eventSource,
 	}
 }
 
-// Register creates a new user and returns a signed JWT
+// Register creates a new user and starts a session for them,
 // effectively logging them in upon registration.
 // It returns domain.ErrConflict if the username is already taken.
 func (u *UserService) Register(
 	ctx context.Context,
 	username,
 	password string,
-) (signedJWT string, err error) {
+) (_ *domain.TokenPair, err error) {
+	ctx, span := u.tracer.Start(ctx, "UserService.Register")
+	defer func() { tracing.End(span, err) }()
+
 	if username == "" {
-		return "", fmt.Errorf("username is required: %w", domain.ErrBadCredentials)
+		return nil, fmt.Errorf("username is required: %w", domain.ErrBadCredentials)
 	}
 	if password == "" {
-		return "", fmt.Errorf("password is required: %w", domain.ErrBadCredentials)
+		return nil, fmt.Errorf("password is required: %w", domain.ErrBadCredentials)
 	}
 	// Hash the password
 	hashedPassword, pErr := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if pErr != nil {
-		return "", fmt.Errorf("failed to hash password: %w", pErr)
+		return nil, fmt.Errorf("failed to hash password: %w", pErr)
 	}
 
 	hashedPasswordStr := string(hashedPassword)
@@ -74,66 +91,158 @@ func (u *UserService) Register(
 		Username:     &username,
 		PasswordHash: &hashedPasswordStr,
 	}
-	dbUser, rErr := u.repo.Create(ctx, user)
+	var tokens *domain.TokenPair
+	rErr := u.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		dbUser, err := u.repo.Create(ctx, user)
+		if err != nil {
+			return err
+		}
+		if tokens, err = u.sessions.Start(ctx, dbUser); err != nil {
+			return err
+		}
+		return enqueueEvent(
+			ctx, u.outbox, u.topic, u.eventSource, EventTypeUserRegistered, *dbUser.ID,
+			UserEventData{
+				SchemaVersion: EventSchemaVersion,
+				Actor:         *dbUser.ID, // self-registration
+				User:          newUserSnapshot(dbUser),
+			},
+		)
+	})
 	if rErr != nil {
 		// Explicitly showing that it can return ErrConflict
 		if errors.Is(rErr, domain.ErrConflict) {
-			return "", domain.ErrConflict
+			return nil, domain.ErrConflict
 		}
-		return "", rErr
+		return nil, rErr
 	}
 
-	// Generate JWT
-	jwt, jErr := u.tokenAuthority.IssueJWT(*dbUser.ID)
-	if jErr != nil {
-		return "", fmt.Errorf("failed to issue JWT: %w", jErr)
-	}
-
-	go u.publishEvent(context.WithoutCancel(ctx), "SIGNUP", *dbUser.ID)
-	return jwt, nil
+	return tokens, nil
 }
 
-func (u *UserService) Login(ctx context.Context, username, password string) (signedJWT string, err error) {
-	// Retrieve user by username
-	user, uErr := u.repo.GetByUsername(ctx, username)
-	if uErr != nil {
-		return "", domain.ErrBadCredentials
+// BootstrapAdmin makes sure the configured bootstrap admin exists and is an admin,
+// so a fresh deployment has someone to assign roles.
+// The user is created if missing. An existing user is only promoted if the password matches,
+// so a username taken through signup can't be turned into an admin, and domain.ErrBadCredentials is returned otherwise.
+// Instances starting at the same time may race to create the user, the losers verify the winner's user instead.
+func (u *UserService) BootstrapAdmin(ctx context.Context, username, password string) (err error) {
+	ctx, span := u.tracer.Start(ctx, "UserService.BootstrapAdmin")
+	defer func() { tracing.End(span, err) }()
+
+	if username == "" || password == "" {
+		return fmt.Errorf("username and password are required: %w", domain.ErrBadCredentials)
 	}
 
-	// Compare provided password with stored hash
-	if pErr := bcrypt.CompareHashAndPassword([]byte(*user.PasswordHash), []byte(password)); pErr != nil {
-		return "", domain.ErrBadCredentials
+	existing, err := u.repo.GetByUsername(ctx, username)
+	if errors.Is(err, domain.ErrNotFound) {
+		err = u.createAdmin(ctx, username, password)
+		if !errors.Is(err, domain.ErrConflict) {
+			return err
+		}
+		// Created in the meantime, e.g. by another instance
+		existing, err = u.repo.GetByUsername(ctx, username)
 	}
-
-	jwt, jErr := u.tokenAuthority.IssueJWT(*user.ID)
-	if jErr != nil {
-		return "", fmt.Errorf("failed to issue JWT: %w", jErr)
+	if err != nil {
+		return err
 	}
 
-	return jwt, nil
+	if pErr := bcrypt.CompareHashAndPassword([]byte(*existing.PasswordHash), []byte(password)); pErr != nil {
+		return fmt.Errorf("user %q exists with another password: %w", username, domain.ErrBadCredentials)
+	}
+	if existing.Role != nil && *existing.Role == domain.RoleAdmin {
+		return nil
+	}
+	return u.AssignRole(ctx, *existing.ID, *existing.ID, domain.RoleAdmin)
 }
 
-func (u *UserService) publishEvent(ctx context.Context, eventType string, id uuid.UUID) {
-	// pub/sub not configured
-	if u.producer == nil {
-		return
+// createAdmin registers a user who is an admin from the start.
+func (u *UserService) createAdmin(ctx context.Context, username, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("failed to hash password: %w", err)
 	}
+	hashedPasswordStr := string(hashedPassword)
+
+	return u.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		dbUser, err := u.repo.Create(ctx, &domain.User{
+			Username:     &username,
+			PasswordHash: &hashedPasswordStr,
+		})
+		if err != nil {
+			return err
+		}
+		if dbUser, err = u.repo.UpdateRole(ctx, *dbUser.ID, domain.RoleAdmin); err != nil {
+			return err
+		}
+		return enqueueEvent(
+			ctx, u.outbox, u.topic, u.eventSource, EventTypeUserRegistered, *dbUser.ID,
+			UserEventData{
+				SchemaVersion: EventSchemaVersion,
+				Actor:         *dbUser.ID, // bootstrapped on its own behalf
+				User:          newUserSnapshot(dbUser),
+			},
+		)
+	})
+}
+
+// Login verifies the user's credentials and starts a new session for them.
+// It returns domain.ErrBadCredentials if the username or password is wrong.
+func (u *UserService) Login(ctx context.Context, username, password string) (_ *domain.TokenPair, err error) {
+	ctx, span := u.tracer.Start(ctx, "UserService.Login")
+	defer func() { tracing.End(span, err) }()
 
-	ctx, cancel := context.WithTimeout(ctx, defaultEventPublishTimeout) //TODO: Should export timeout
-	defer cancel()
+	// Retrieve user by username
+	user, uErr := u.repo.GetByUsername(ctx, username)
+	if uErr != nil {
+		u.metrics.LoginFailed()
+		return nil, domain.ErrBadCredentials
+	}
 
-	eventData := map[string]any{
-		"event": eventType,
+	// Compare provided password with stored hash
+	if pErr := bcrypt.CompareHashAndPassword([]byte(*user.PasswordHash), []byte(password)); pErr != nil {
+		u.metrics.LoginFailed()
+		return nil, domain.ErrBadCredentials
 	}
 
-	marshalledEvent, err := json.Marshal(eventData)
+	span.SetAttributes(attribute.String("user.id", user.ID.String()))
+	tokens, err := u.sessions.Start(ctx, user)
 	if err != nil {
-		u.logger.Error("Failed to marshal event", zap.Error(err))
-		return
+		return nil, err
 	}
+	u.metrics.LoginSucceeded()
+	return tokens, nil
+}
 
-	if pErr := u.producer.Produce(ctx, u.topic, id.String(), marshalledEvent); pErr != nil {
-		u.logger.Error("Failed to produce event", zap.Error(pErr))
-		return
+// AssignRole changes the role of a user, taking effect with the user's next issued access token.
+// It returns domain.ErrBadRequest if the role is unknown and domain.ErrNotFound if the user does not exist.
+func (u *UserService) AssignRole(
+	ctx context.Context,
+	actor uuid.UUID,
+	id uuid.UUID,
+	role domain.Role,
+) (err error) {
+	ctx, span := u.tracer.Start(ctx, "UserService.AssignRole", trace.WithAttributes(
+		attribute.String("user.id", id.String()),
+		attribute.String("user.role", string(role)),
+	))
+	defer func() { tracing.End(span, err) }()
+
+	if !role.Valid() {
+		return fmt.Errorf("unknown role %q: %w", role, domain.ErrBadRequest)
 	}
+
+	return u.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		updatedUser, err := u.repo.UpdateRole(ctx, id, role)
+		if err != nil {
+			return err
+		}
+		return enqueueEvent(
+			ctx, u.outbox, u.topic, u.eventSource, EventTypeUserRoleAssigned, id,
+			UserEventData{
+				SchemaVersion: EventSchemaVersion,
+				Actor:         actor,
+				User:          newUserSnapshot(updatedUser),
+			},
+		)
+	})
 }
@@ -0,0 +1,72 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// QueryTracer is a pgx.QueryTracer that wraps queries in client spans.
+// Queries only get a span when the context already carries one, so background work such as
+// the outbox relay polling does not produce a stream of single span traces.
+type QueryTracer struct {
+	tracer trace.Tracer
+}
+
+const sqlcNamePrefix = "-- name: "
+
+func NewQueryTracer() *QueryTracer {
+	return &QueryTracer{tracer: Tracer()}
+}
+
+// TraceQueryStart satisfies the pgx.QueryTracer interface.
+// The span is named after the sqlc query, which is also reported as the database operation.
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemNamePostgreSQL,
+		semconv.DBQueryText(data.SQL),
+	}
+	name := "db.query"
+	if queryName := sqlcQueryName(data.SQL); queryName != "" {
+		name = queryName
+		attrs = append(attrs, semconv.DBOperationName(queryName))
+	}
+
+	ctx, _ = t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	return ctx
+}
+
+// TraceQueryEnd satisfies the pgx.QueryTracer interface.
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+
+	span.SetAttributes(attribute.Int64("db.response.affected_rows", data.CommandTag.RowsAffected()))
+	// Lookups of missing rows are answered with a not found, not a failure
+	err := data.Err
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = nil
+	}
+	End(span, err)
+}
+
+// sqlcQueryName extracts the query name from the "-- name: GetCompanyByID :one" header sqlc prepends.
+func sqlcQueryName(sql string) string {
+	header, ok := strings.CutPrefix(sql, sqlcNamePrefix)
+	if !ok {
+		return ""
+	}
+	name, _, _ := strings.Cut(header, " ")
+	return name
+}
@@ -0,0 +1,90 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Laelapa/CompanyRegistry/internal/tracing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const getCompanySQL = "-- name: GetCompanyByID :one\nSELECT * FROM companies WHERE ID = $1"
+
+// recordSpans installs a tracer provider recording every span for the duration of the test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return recorder
+}
+
+// runQuery passes a query through the tracer the way pgx does, ending it with err.
+func runQuery(ctx context.Context, qt *tracing.QueryTracer, sql string, err error) {
+	ctx = qt.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: sql})
+	qt.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1"), Err: err})
+}
+
+func TestQueryTracerNeedsAParentSpan(t *testing.T) {
+	recorder := recordSpans(t)
+	qt := tracing.NewQueryTracer()
+
+	ctx := context.Background()
+	assert.Equal(t, ctx, qt.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: getCompanySQL}))
+	runQuery(ctx, qt, getCompanySQL, nil)
+
+	assert.Empty(t, recorder.Started(), "queries outside a trace must not start traces of their own")
+}
+
+func TestQueryTracerNamesSpansAfterTheSqlcQuery(t *testing.T) {
+	recorder := recordSpans(t)
+	qt := tracing.NewQueryTracer()
+
+	ctx, parent := tracing.Tracer().Start(context.Background(), "CompanyService.Get")
+	runQuery(ctx, qt, getCompanySQL, nil)
+	runQuery(ctx, qt, "SELECT 1", nil)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	named := spans[0]
+	assert.Equal(t, "GetCompanyByID", named.Name())
+	assert.Equal(t, trace.SpanKindClient, named.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), named.Parent().SpanID())
+	assert.Equal(t, parent.SpanContext().TraceID(), named.SpanContext().TraceID())
+	assert.Contains(t, named.Attributes(), attribute.String("db.operation.name", "GetCompanyByID"))
+	assert.Contains(t, named.Attributes(), attribute.Int64("db.response.affected_rows", 1))
+
+	assert.Equal(t, "db.query", spans[1].Name(), "queries without a sqlc header get a generic name")
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[1].Parent().SpanID())
+}
+
+func TestQueryTracerRecordsFailures(t *testing.T) {
+	recorder := recordSpans(t)
+	qt := tracing.NewQueryTracer()
+
+	ctx, parent := tracing.Tracer().Start(context.Background(), "CompanyService.Get")
+	runQuery(ctx, qt, getCompanySQL, pgx.ErrNoRows)
+	runQuery(ctx, qt, getCompanySQL, errors.New("connection reset"))
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code, "missing rows are not a failure")
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Equal(t, "connection reset", spans[1].Status().Description)
+}
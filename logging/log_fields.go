@@ -21,8 +21,26 @@ const (
 	FieldPath = "path"
 	// FieldReferer is the Referer header from the request
 	FieldReferer = "referer"
+	// FieldRequestID is the X-Request-ID the request was sent or assigned
+	FieldRequestID = "request_id"
+	// FieldTraceID and FieldSpanID tie a log line to the request's OpenTelemetry trace
+	FieldTraceID = "trace_id"
+	FieldSpanID  = "span_id"
+	// FieldRoute is the route pattern the request matched, without the method
+	FieldRoute = "route"
+	// FieldStatus is the HTTP status code of the response
+	FieldStatus = "status"
+	// FieldLatency is the time taken to handle the request
+	FieldLatency = "latency"
+	// FieldBytes is the size of the response body
+	FieldBytes = "bytes"
 
-	FieldUserID = "user_id"
+	FieldUserID   = "user_id"
+	FieldUsername = "username"
+	// FieldSessionID is the refresh token family an access token belongs to
+	FieldSessionID = "session_id"
+	// FieldIdempotencyKey is the Idempotency-Key header a mutating request was sent with
+	FieldIdempotencyKey = "idempotency_key"
 
 	// HTTP Server related fields ----------------------
 
@@ -30,12 +48,42 @@ const (
 
 	FieldServerPort = "server_port"
 
+	FieldDrainDelay = "drain_delay"
+
+	// Tracing related fields --------------------------
+
+	FieldTracingExporter = "tracing_exporter"
+
 	// Kafka related fields ----------------------------
 
 	FieldKafkaTopic   = "kafka_topic"
 	FieldKafkaBrokers = "kafka_brokers"
 
+	// Outbox related fields ---------------------------
+
+	FieldOutboxMessageID    = "outbox_message_id"
+	FieldOutboxAttempts     = "outbox_attempts"
+	FieldOutboxRetryIn      = "outbox_retry_in"
+	FieldOutboxPending      = "outbox_pending"
+	FieldOutboxParked       = "outbox_parked"
+	FieldOutboxLag          = "outbox_lag"
+	FieldOutboxPollInterval = "outbox_poll_interval"
+
+	// Company purge related fields --------------------
+
+	FieldPurgeRetention = "purge_retention"
+	FieldPurgeInterval  = "purge_interval"
+	FieldPurgedCount    = "purged_count"
+
+	// Pruning of expired records related fields -------
+
+	FieldPruneInterval = "prune_interval"
+	FieldPrunedCount   = "pruned_count"
+
 	// Other common fields -----------------------------
 
+	// FieldDependency is the dependency a health check concerns
+	FieldDependency = "dependency"
+
 	FieldError = "error"
 )
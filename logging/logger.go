@@ -42,6 +42,11 @@ func NewLogger(cfg config.LoggingConfig) (*Logger, error) {
 	return &Logger{logger, cfg.MaxHeaderLength}, nil
 }
 
+// Wrap turns an already built zap logger, such as one observing the logs in tests, into a Logger.
+func Wrap(logger *zap.Logger, maxHeaderLength int) *Logger {
+	return &Logger{logger, maxHeaderLength}
+}
+
 func setupProdConfig(cfg config.LoggingConfig) zap.Config {
 	config := zap.NewProductionConfig()
 	config.InitialFields = map[string]any{
@@ -1,10 +1,13 @@
 package logging
 
 import (
+	"context"
 	"net/http"
 
+	"github.com/Laelapa/CompanyRegistry/util/ctxutils"
 	"github.com/Laelapa/CompanyRegistry/util/netutils"
 
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -45,10 +48,27 @@ func (l *Logger) ReqFields(r *http.Request) []zap.Field {
 		}
 	}
 
-	return []zap.Field{
+	fields := []zap.Field{
 		zap.String(FieldRemoteAddr, l.FiletLogValue(netutils.GetClientIP(r))),
 		zap.String(FieldMethod, r.Method),
 		zap.String(FieldPath, l.FiletLogValue(r.URL.Path)),
 		zap.String(FieldReferer, l.FiletLogValue(r.Referer())),
 	}
+	return append(fields, l.CtxFields(r.Context())...)
+}
+
+// CtxFields returns the fields correlating a log line with the request being handled:
+// its request ID and its trace. Logs written outside the handlers should include them.
+func (l *Logger) CtxFields(ctx context.Context) []zap.Field {
+	var fields []zap.Field
+	if requestID, ok := ctxutils.GetRequestIDFromContext(ctx); ok {
+		fields = append(fields, zap.String(FieldRequestID, requestID))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		fields = append(fields,
+			zap.String(FieldTraceID, sc.TraceID().String()),
+			zap.String(FieldSpanID, sc.SpanID().String()),
+		)
+	}
+	return fields
 }
@@ -0,0 +1,110 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/routes/handlers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompanyAsOf(t *testing.T) {
+	app := setupApp(t)
+
+	w := sendPostRequest(app, "/api/v1/signup", handlers.UserSignupRequest{
+		Username: "user" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Password: "TestPassword123!",
+	}, "")
+	require.Equal(t, http.StatusCreated, w.Code)
+	var tokens handlers.AuthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+
+	employees := int32(5)
+	w = sendPostRequest(app, "/api/v1/company", handlers.CreateCompanyRequest{
+		Name:          "asof" + uuid.NewString()[:8],
+		EmployeeCount: &employees,
+		CompanyType:   "Corporation",
+	}, tokens.AccessToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var company handlers.CompanyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &company))
+	companyURL := "/api/v1/company/" + company.ID.String()
+
+	moreEmployees := int32(50)
+	w = sendRequest(
+		app, http.MethodPatch, companyURL, handlers.UpdateCompanyRequest{EmployeeCount: &moreEmployees},
+		tokens.AccessToken,
+	)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = sendRequest(app, http.MethodDelete, companyURL, nil, tokens.AccessToken)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	// The instants each revision became valid at, oldest first
+	w = sendRequest(app, http.MethodGet, companyURL+"/history", nil, tokens.AccessToken)
+	require.Equal(t, http.StatusOK, w.Code)
+	var history handlers.CompanyHistoryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	require.Len(t, history.Revisions, 3)
+	created := history.Revisions[2].ChangedAt
+	updated := history.Revisions[1].ChangedAt
+	deleted := history.Revisions[0].ChangedAt
+
+	asOf := func(t time.Time) string {
+		return url.QueryEscape(t.Format(time.RFC3339Nano))
+	}
+
+	t.Run("Company as of each revision", func(t *testing.T) {
+		for instant, expected := range map[time.Time]int32{created: employees, updated: moreEmployees} {
+			w := sendRequest(app, http.MethodGet, companyURL+"?as_of="+asOf(instant), nil, "")
+			require.Equal(t, http.StatusOK, w.Code)
+			var c handlers.CompanyResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &c))
+			assert.Equal(t, company.ID, c.ID)
+			assert.Equal(t, expected, c.EmployeeCount)
+		}
+	})
+
+	t.Run("Company before its creation and after its deletion", func(t *testing.T) {
+		for _, instant := range []time.Time{created.Add(-time.Microsecond), deleted} {
+			w := sendRequest(app, http.MethodGet, companyURL+"?as_of="+asOf(instant), nil, "")
+			assert.Equal(t, http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("Listing as of a past instant includes companies deleted since", func(t *testing.T) {
+		require.NotNil(t, history.Revisions[2].Actor)
+		query := "?created_by=" + history.Revisions[2].Actor.String() + "&as_of="
+		w := sendRequest(app, http.MethodGet, "/api/v1/companies"+query+asOf(updated), nil, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var page handlers.CompanyListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		require.Len(t, page.Companies, 1)
+		assert.Equal(t, company.ID, page.Companies[0].ID)
+		assert.Equal(t, moreEmployees, page.Companies[0].EmployeeCount)
+
+		w = sendRequest(app, http.MethodGet, "/api/v1/companies"+query+asOf(deleted), nil, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+		assert.Empty(t, page.Companies)
+	})
+
+	t.Run("Future instants are rejected", func(t *testing.T) {
+		future := asOf(time.Now().Add(time.Hour))
+		w := sendRequest(app, http.MethodGet, companyURL+"?as_of="+future, nil, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = sendRequest(app, http.MethodGet, "/api/v1/companies?as_of="+future, nil, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Malformed instants are rejected", func(t *testing.T) {
+		w := sendRequest(app, http.MethodGet, companyURL+"?as_of=yesterday", nil, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,104 @@
+package integration_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/auth/tokenauthority"
+	"github.com/Laelapa/CompanyRegistry/internal/authz"
+	"github.com/Laelapa/CompanyRegistry/internal/cache"
+	"github.com/Laelapa/CompanyRegistry/internal/config"
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/repository"
+	"github.com/Laelapa/CompanyRegistry/internal/repository/adapters"
+	"github.com/Laelapa/CompanyRegistry/internal/service"
+	"github.com/Laelapa/CompanyRegistry/logging"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompanyNameCache(t *testing.T) {
+	ctx := context.Background()
+	logger, _ := logging.NewLogger(config.LoggingConfig{LoggerSetup: "prod"})
+	queries := repository.New(testDBPool)
+	tokenAuth, err := tokenauthority.New(&config.AuthConfig{JwtSecret: "test-secret-key"})
+	require.NoError(t, err)
+
+	companySvc := service.NewCompanyService(
+		adapters.NewPGCompanyRepoAdapter(queries),
+		service.NewCompanyTypeService(adapters.NewPGCompanyTypeRepoAdapter(queries)),
+		cache.New[*domain.Company](10, time.Hour),
+		adapters.NewPGTransactor(testDBPool),
+		nil,
+		tokenAuth,
+		logger,
+		nil,
+		"doesn't-matter",
+		"doesn't-matter",
+	)
+
+	username := "cache" + uuid.NewString()[:8]
+	passwordHash := "not-a-real-hash"
+	user, err := adapters.NewPGUserRepoAdapter(queries).Create(ctx, &domain.User{
+		Username:     &username,
+		PasswordHash: &passwordHash,
+	})
+	require.NoError(t, err)
+	actor := authz.Principal{UserID: *user.ID, Role: domain.RoleViewer}
+
+	name := "cache" + uuid.NewString()[:8]
+	var ec int32 = 5
+	ct := domain.CompanyType("Corporation")
+	created, err := companySvc.Create(ctx, &domain.Company{
+		Name:          &name,
+		EmployeeCount: &ec,
+		CompanyType:   &ct,
+		CreatedBy:     user.ID,
+	})
+	require.NoError(t, err)
+
+	cached, err := companySvc.GetByName(ctx, name)
+	require.NoError(t, err)
+	assert.Equal(t, ec, *cached.EmployeeCount)
+
+	t.Run("Changes made behind the service's back are not seen", func(t *testing.T) {
+		_, err := testDBPool.Exec(ctx, "UPDATE companies SET employee_count = 6 WHERE ID = $1", *created.ID)
+		require.NoError(t, err)
+
+		c, err := companySvc.GetByName(ctx, name)
+		require.NoError(t, err)
+		assert.Equal(t, ec, *c.EmployeeCount, "the company should be served from the cache")
+	})
+
+	t.Run("Updates invalidate the cache", func(t *testing.T) {
+		newEC := int32(7)
+		_, err := companySvc.Update(ctx, actor, &domain.Company{ID: created.ID, EmployeeCount: &newEC})
+		require.NoError(t, err)
+
+		c, err := companySvc.GetByName(ctx, name)
+		require.NoError(t, err)
+		assert.Equal(t, newEC, *c.EmployeeCount)
+	})
+
+	t.Run("Renames invalidate the old name", func(t *testing.T) {
+		newName := "cache" + uuid.NewString()[:8]
+		_, err := companySvc.Update(ctx, actor, &domain.Company{ID: created.ID, Name: &newName})
+		require.NoError(t, err)
+
+		_, err = companySvc.GetByName(ctx, name)
+		require.ErrorIs(t, err, domain.ErrNotFound)
+		name = newName
+	})
+
+	t.Run("Deletes invalidate the cache", func(t *testing.T) {
+		_, err := companySvc.GetByName(ctx, name)
+		require.NoError(t, err)
+		require.NoError(t, companySvc.Delete(ctx, actor, *created.ID, nil))
+
+		_, err = companySvc.GetByName(ctx, name)
+		require.ErrorIs(t, err, domain.ErrNotFound)
+	})
+}
@@ -0,0 +1,154 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
+	"github.com/Laelapa/CompanyRegistry/internal/routes/handlers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompanyTypes(t *testing.T) {
+	app := setupApp(t)
+
+	signup := func(t *testing.T) (username string, tokens handlers.AuthResponse) {
+		t.Helper()
+		username = "user" + strings.ReplaceAll(uuid.NewString(), "-", "")
+		w := sendPostRequest(app, "/api/v1/signup", handlers.UserSignupRequest{
+			Username: username,
+			Password: "TestPassword123!",
+		}, "")
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+		return username, tokens
+	}
+
+	_, user := signup(t)
+	// Company types can only be managed by admins
+	admin := loginAdmin(t, app)
+
+	listTypes := func(t *testing.T, query string) []string {
+		t.Helper()
+		w := sendRequest(app, http.MethodGet, "/api/v1/company-types"+query, nil, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var list handlers.CompanyTypeListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		names := make([]string, 0, len(list.CompanyTypes))
+		for _, ct := range list.CompanyTypes {
+			names = append(names, ct.Name)
+		}
+		return names
+	}
+
+	createCompany := func(companyType string) *httptest.ResponseRecorder {
+		employees := int32(3)
+		return sendPostRequest(app, "/api/v1/company", handlers.CreateCompanyRequest{
+			Name:          "type" + uuid.NewString()[:8],
+			EmployeeCount: &employees,
+			CompanyType:   companyType,
+		}, user.AccessToken)
+	}
+
+	typeName := "LLC " + uuid.NewString()[:8]
+	typeURL := "/api/v1/company-types/" + url.PathEscape(typeName)
+
+	t.Run("The seeded types are listed without authentication", func(t *testing.T) {
+		assert.Subset(t, listTypes(t, ""), []string{"Corporation", "NonProfit", "Cooperative", "Sole Proprietorship"})
+	})
+
+	t.Run("Only admins can manage types", func(t *testing.T) {
+		w := sendPostRequest(app, "/api/v1/company-types", handlers.CreateCompanyTypeRequest{Name: typeName}, "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		w = sendPostRequest(app, "/api/v1/company-types", handlers.CreateCompanyTypeRequest{
+			Name: typeName,
+		}, user.AccessToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = sendRequest(app, http.MethodDelete, "/api/v1/company-types/Corporation", nil, user.AccessToken)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Unknown types are rejected by the service", func(t *testing.T) {
+		w := createCompany(typeName)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var p problem.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+		assert.Equal(t, problem.CodeUnknownCompanyType, p.Code)
+	})
+
+	var companyID uuid.UUID
+	t.Run("Added types can be given to companies right away", func(t *testing.T) {
+		description := "Limited liability company"
+		w := sendPostRequest(app, "/api/v1/company-types", handlers.CreateCompanyTypeRequest{
+			Name:        typeName,
+			Description: &description,
+		}, admin.AccessToken)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var created handlers.CompanyTypeResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		assert.Equal(t, typeName, created.Name)
+		assert.True(t, created.Active)
+		assert.Contains(t, listTypes(t, ""), typeName)
+
+		w = sendPostRequest(app, "/api/v1/company-types", handlers.CreateCompanyTypeRequest{
+			Name: typeName,
+		}, admin.AccessToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = createCompany(typeName)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var company handlers.CompanyResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &company))
+		assert.Equal(t, typeName, company.CompanyType)
+		companyID = company.ID
+	})
+
+	t.Run("Retired types stay with their companies only", func(t *testing.T) {
+		active := false
+		w := sendRequest(app, http.MethodPatch, typeURL, handlers.UpdateCompanyTypeRequest{
+			Active: &active,
+		}, admin.AccessToken)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		assert.NotContains(t, listTypes(t, ""), typeName)
+		assert.Contains(t, listTypes(t, "?include_inactive=true"), typeName)
+
+		w = createCompany(typeName)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		employees := int32(4)
+		w = sendRequest(app, http.MethodPatch, "/api/v1/company/"+companyID.String(), handlers.UpdateCompanyRequest{
+			EmployeeCount: &employees,
+			CompanyType:   &typeName,
+		}, user.AccessToken)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+
+	t.Run("Types in use cannot be deleted", func(t *testing.T) {
+		w := sendRequest(app, http.MethodDelete, typeURL, nil, admin.AccessToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Unused types can be deleted", func(t *testing.T) {
+		unused := "Unused " + uuid.NewString()[:8]
+		w := sendPostRequest(app, "/api/v1/company-types", handlers.CreateCompanyTypeRequest{
+			Name: unused,
+		}, admin.AccessToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		unusedURL := "/api/v1/company-types/" + url.PathEscape(unused)
+		w = sendRequest(app, http.MethodDelete, unusedURL, nil, admin.AccessToken)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		w = sendRequest(app, http.MethodDelete, unusedURL, nil, admin.AccessToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
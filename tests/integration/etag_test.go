@@ -0,0 +1,124 @@
+package integration_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Laelapa/CompanyRegistry/internal/app"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
+	"github.com/Laelapa/CompanyRegistry/internal/routes/handlers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptimisticConcurrency(t *testing.T) {
+	app := setupApp(t)
+
+	w := sendPostRequest(app, "/api/v1/signup", handlers.UserSignupRequest{
+		Username: "user" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Password: "TestPassword123!",
+	}, "")
+	require.Equal(t, http.StatusCreated, w.Code)
+	var tokens handlers.AuthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+
+	employees := int32(5)
+	w = sendPostRequest(app, "/api/v1/company", handlers.CreateCompanyRequest{
+		Name:          "etag" + uuid.NewString()[:8],
+		EmployeeCount: &employees,
+		CompanyType:   "Corporation",
+	}, tokens.AccessToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	created := w.Header().Get("ETag")
+	require.Equal(t, `"1"`, created)
+
+	var company handlers.CompanyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &company))
+	url := "/api/v1/company/" + company.ID.String()
+
+	w = sendRequest(app, http.MethodGet, url, nil, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, created, w.Header().Get("ETag"))
+	lastModified := w.Header().Get("Last-Modified")
+	require.NotEmpty(t, lastModified)
+
+	t.Run("Revalidating an unchanged company", func(t *testing.T) {
+		for header, value := range map[string]string{
+			"If-None-Match":     created,
+			"If-Modified-Since": lastModified,
+		} {
+			r := httptest.NewRequest(http.MethodGet, url, nil)
+			r.Header.Set(header, value)
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+			assert.Equal(t, http.StatusNotModified, w.Code, header)
+			assert.Empty(t, w.Body.Bytes(), header)
+			assert.Equal(t, created, w.Header().Get("ETag"), header)
+		}
+	})
+
+	ec := int32(10)
+	patch := handlers.UpdateCompanyRequest{EmployeeCount: &ec}
+	var updated string
+
+	t.Run("Update based on the current version applies", func(t *testing.T) {
+		w := sendConditionalRequest(app, http.MethodPatch, url, patch, tokens.AccessToken, created)
+		require.Equal(t, http.StatusOK, w.Code)
+		updated = w.Header().Get("ETag")
+		assert.Equal(t, `"2"`, updated)
+	})
+
+	t.Run("Revalidating a changed company", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, url, nil)
+		r.Header.Set("If-None-Match", created)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, updated, w.Header().Get("ETag"))
+	})
+
+	t.Run("Update based on a stale version is rejected", func(t *testing.T) {
+		w := sendConditionalRequest(app, http.MethodPatch, url, patch, tokens.AccessToken, created)
+		require.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+		var p problem.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+		assert.Equal(t, problem.CodePreconditionFailed, p.Code)
+	})
+
+	t.Run("Weak entity tags never match", func(t *testing.T) {
+		w := sendConditionalRequest(app, http.MethodPatch, url, patch, tokens.AccessToken, "W/"+updated)
+		require.Equal(t, http.StatusPreconditionFailed, w.Code)
+	})
+
+	t.Run("Delete honours If-Match", func(t *testing.T) {
+		w := sendConditionalRequest(app, http.MethodDelete, url, nil, tokens.AccessToken, created)
+		require.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+		w = sendConditionalRequest(app, http.MethodDelete, url, nil, tokens.AccessToken, updated)
+		require.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
+
+func sendConditionalRequest(
+	app *app.App,
+	method, url string,
+	body any,
+	accessToken string,
+	ifMatch string,
+) *httptest.ResponseRecorder {
+	reqBody, _ := json.Marshal(body)
+	r := httptest.NewRequest(method, url, bytes.NewReader(reqBody))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Authorization", "Bearer "+accessToken)
+	r.Header.Set("If-Match", ifMatch)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	return w
+}
@@ -0,0 +1,143 @@
+package integration_test
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Laelapa/CompanyRegistry/internal/app"
+	"github.com/Laelapa/CompanyRegistry/internal/routes/handlers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportCompanies(t *testing.T) {
+	app := setupApp(t)
+
+	w := sendPostRequest(app, "/api/v1/signup", handlers.UserSignupRequest{
+		Username: "user" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Password: "TestPassword123!",
+	}, "")
+	require.Equal(t, http.StatusCreated, w.Code)
+	var tokens handlers.AuthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+
+	names := make([]string, 0, 3)
+	var companyIDs []uuid.UUID
+	for i := range 3 {
+		employees := int32(10 * (i + 1))
+		description := "Exported, with a \"quote\""
+		newCompany := handlers.CreateCompanyRequest{
+			Name:          "exp" + uuid.NewString()[:8],
+			Description:   &description,
+			EmployeeCount: &employees,
+			CompanyType:   "Cooperative",
+		}
+		w = sendPostRequest(app, "/api/v1/company", newCompany, tokens.AccessToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var company handlers.CompanyResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &company))
+		names = append(names, company.Name)
+		companyIDs = append(companyIDs, company.ID)
+	}
+
+	w = sendRequest(app, http.MethodGet, "/api/v1/company/"+companyIDs[0].String()+"/history", nil, tokens.AccessToken)
+	require.Equal(t, http.StatusOK, w.Code)
+	var history handlers.CompanyHistoryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	require.NotEmpty(t, history.Revisions)
+	require.NotNil(t, history.Revisions[0].Actor)
+	userID := *history.Revisions[0].Actor
+	query := "?created_by=" + userID.String()
+
+	t.Run("Export requires authentication", func(t *testing.T) {
+		w := sendExportRequest(app, query, "application/json", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Unacceptable formats are rejected", func(t *testing.T) {
+		w := sendExportRequest(app, query, "application/xml", tokens.AccessToken)
+		assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	})
+
+	t.Run("JSON export includes audit fields", func(t *testing.T) {
+		w := sendExportRequest(app, query, "", tokens.AccessToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var records []handlers.CompanyExportRecord
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &records))
+		require.Len(t, records, 3)
+		for i, rec := range records {
+			assert.Equal(t, names[i], rec.Name, "companies are exported oldest first")
+			require.NotNil(t, rec.CreatedBy)
+			assert.Equal(t, userID, *rec.CreatedBy)
+			assert.NotNil(t, rec.CreatedAt)
+			assert.Nil(t, rec.UpdatedAt, "never updated")
+			assert.Equal(t, int64(1), rec.Version)
+		}
+	})
+
+	t.Run("NDJSON export applies filters", func(t *testing.T) {
+		w := sendExportRequest(app, query+"&registered=false&min_employees=20", "application/x-ndjson", tokens.AccessToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+		var records []handlers.CompanyExportRecord
+		sc := bufio.NewScanner(w.Body)
+		for sc.Scan() {
+			var rec handlers.CompanyExportRecord
+			require.NoError(t, json.Unmarshal(sc.Bytes(), &rec))
+			records = append(records, rec)
+		}
+		require.Len(t, records, 2)
+		assert.Equal(t, names[1], records[0].Name)
+		assert.Equal(t, names[2], records[1].Name)
+	})
+
+	t.Run("CSV export has a header row", func(t *testing.T) {
+		w := sendExportRequest(app, query, "text/csv", tokens.AccessToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "companies.csv")
+
+		rows, err := csv.NewReader(w.Body).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, rows, 4)
+		assert.Equal(t, []string{
+			"id", "name", "description", "employee_count", "status", "registered", "company_type",
+			"version", "created_at", "created_by", "updated_at", "updated_by",
+		}, rows[0])
+		assert.Equal(t, companyIDs[0].String(), rows[1][0])
+		assert.Equal(t, "Exported, with a \"quote\"", rows[1][2])
+		assert.Equal(t, "pending", rows[1][4])
+		assert.Equal(t, userID.String(), rows[1][9])
+	})
+
+	t.Run("Empty exports are well-formed", func(t *testing.T) {
+		w := sendExportRequest(app, query+"&company_type=NonProfit", "application/json", tokens.AccessToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var records []handlers.CompanyExportRecord
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &records))
+		assert.Empty(t, records)
+	})
+}
+
+func sendExportRequest(app *app.App, query, accept, accessToken string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/companies/export"+query, nil)
+	if accept != "" {
+		r.Header.Set("Accept", accept)
+	}
+	if accessToken != "" {
+		r.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	return w
+}
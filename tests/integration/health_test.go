@@ -0,0 +1,50 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Laelapa/CompanyRegistry/internal/routes/handlers"
+	"github.com/Laelapa/CompanyRegistry/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthEndpoints(t *testing.T) {
+	app := setupApp(t)
+
+	t.Run("Liveness", func(t *testing.T) {
+		w := sendRequest(app, http.MethodGet, "/healthz", nil, "")
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Readiness reports every dependency", func(t *testing.T) {
+		w := sendRequest(app, http.MethodGet, "/readyz", nil, "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp handlers.HealthResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "ready", resp.Status)
+		assert.Equal(t, "up", resp.Checks[service.DependencyDatabase].Status)
+		assert.Equal(t, "up", resp.Checks[service.DependencyMigrations].Status)
+		assert.Equal(t, "disabled", resp.Checks[service.DependencyKafka].Status)
+		assert.Equal(
+			t,
+			resp.Checks[service.DependencyMigrations].Details["expected_version"],
+			resp.Checks[service.DependencyMigrations].Details["version"],
+		)
+	})
+
+	t.Run("Readiness fails once shutdown starts", func(t *testing.T) {
+		app.ShutdownServer()
+
+		w := sendRequest(app, http.MethodGet, "/readyz", nil, "")
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), "draining")
+
+		w = sendRequest(app, http.MethodGet, "/healthz", nil, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,111 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Laelapa/CompanyRegistry/internal/routes/handlers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompanyHistory(t *testing.T) {
+	app := setupApp(t)
+
+	w := sendPostRequest(app, "/api/v1/signup", handlers.UserSignupRequest{
+		Username: "user" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Password: "TestPassword123!",
+	}, "")
+	require.Equal(t, http.StatusCreated, w.Code)
+	var tokens handlers.AuthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+
+	employees := int32(5)
+	w = sendPostRequest(app, "/api/v1/company", handlers.CreateCompanyRequest{
+		Name:          "hist" + uuid.NewString()[:8],
+		EmployeeCount: &employees,
+		CompanyType:   "Corporation",
+	}, tokens.AccessToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var company handlers.CompanyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &company))
+	url := "/api/v1/company/" + company.ID.String()
+
+	moreEmployees := int32(50)
+	w = sendRequest(
+		app, http.MethodPatch, url, handlers.UpdateCompanyRequest{EmployeeCount: &moreEmployees}, tokens.AccessToken,
+	)
+	require.Equal(t, http.StatusOK, w.Code)
+	description := "Now with a description"
+	w = sendRequest(
+		app, http.MethodPatch, url, handlers.UpdateCompanyRequest{Description: &description}, tokens.AccessToken,
+	)
+	require.Equal(t, http.StatusOK, w.Code)
+	w = sendRequest(app, http.MethodDelete, url, nil, tokens.AccessToken)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	t.Run("History requires authentication", func(t *testing.T) {
+		w := sendRequest(app, http.MethodGet, url+"/history", nil, "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("History of a deleted company is paginated newest first", func(t *testing.T) {
+		var revisions []handlers.CompanyRevisionResponse
+		next := url + "/history?limit=3"
+		pages := 0
+		for next != "" {
+			w := sendRequest(app, http.MethodGet, next, nil, tokens.AccessToken)
+			require.Equal(t, http.StatusOK, w.Code)
+			var page handlers.CompanyHistoryResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+			revisions = append(revisions, page.Revisions...)
+			pages++
+			next = ""
+			if page.NextCursor != nil {
+				next = url + "/history?limit=3&cursor=" + *page.NextCursor
+			}
+		}
+		assert.Equal(t, 2, pages)
+
+		require.Len(t, revisions, 4)
+		for i, operation := range []string{"delete", "update", "update", "create"} {
+			assert.Equal(t, int64(4-i), revisions[i].Revision)
+			assert.Equal(t, operation, revisions[i].Operation)
+			require.NotNil(t, revisions[i].Actor)
+			assert.NotEqual(t, uuid.Nil, *revisions[i].Actor)
+		}
+		assert.Equal(t, employees, revisions[3].Company.EmployeeCount)
+		assert.Equal(t, moreEmployees, revisions[2].Company.EmployeeCount)
+		assert.Equal(t, &description, revisions[0].Company.Description)
+	})
+
+	t.Run("Diff between revisions", func(t *testing.T) {
+		w := sendRequest(app, http.MethodGet, url+"/history/diff?from=1&to=3", nil, tokens.AccessToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var diff handlers.CompanyDiffResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &diff))
+		require.Len(t, diff.Changes, 2)
+		assert.Equal(t, "description", diff.Changes[0].Field)
+		assert.Nil(t, diff.Changes[0].From)
+		assert.Equal(t, description, diff.Changes[0].To)
+		assert.Equal(t, "employee_count", diff.Changes[1].Field)
+		assert.InDelta(t, employees, diff.Changes[1].From, 0)
+		assert.InDelta(t, moreEmployees, diff.Changes[1].To, 0)
+	})
+
+	t.Run("Unknown revisions and companies are not found", func(t *testing.T) {
+		w := sendRequest(app, http.MethodGet, url+"/history/diff?from=1&to=9", nil, tokens.AccessToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = sendRequest(app, http.MethodGet, "/api/v1/company/"+uuid.NewString()+"/history", nil, tokens.AccessToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Diff requires both revisions", func(t *testing.T) {
+		w := sendRequest(app, http.MethodGet, url+"/history/diff?from=1", nil, tokens.AccessToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,160 @@
+package integration_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Laelapa/CompanyRegistry/internal/app"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
+	"github.com/Laelapa/CompanyRegistry/internal/routes/handlers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	app := setupApp(t)
+
+	signup := handlers.UserSignupRequest{
+		Username: "user" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Password: "TestPassword123!",
+	}
+	signupKey := uuid.NewString()
+	w := sendIdempotentRequest(app, "/api/v1/signup", signup, "", signupKey)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var tokens handlers.AuthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+
+	t.Run("Retried signup after a refresh never replays the issued tokens", func(t *testing.T) {
+		w := sendPostRequest(
+			app, "/api/v1/token/refresh", handlers.RefreshTokenRequest{RefreshToken: tokens.RefreshToken}, "",
+		)
+		require.Equal(t, http.StatusOK, w.Code)
+		var rotated handlers.AuthResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotated))
+
+		// Only the outcome of the signup is recorded, the retry logs in with fresh tokens
+		w = sendIdempotentRequest(app, "/api/v1/signup", signup, "", signupKey)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+		var reissued handlers.AuthResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reissued))
+		assert.NotEmpty(t, reissued.AccessToken)
+		assert.NotEqual(t, tokens.RefreshToken, reissued.RefreshToken)
+		assert.NotEqual(t, rotated.RefreshToken, reissued.RefreshToken)
+
+		var status int
+		var body []byte
+		err := testDBPool.QueryRow(
+			context.Background(),
+			"SELECT response_status, response_body FROM idempotency_keys WHERE idempotency_key = $1",
+			signupKey,
+		).Scan(&status, &body)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, status)
+		assert.Nil(t, body, "tokens must not be recorded")
+
+		// A retry with a different password is a different request
+		retry := signup
+		retry.Password = "OtherPassword123!"
+		w = sendIdempotentRequest(app, "/api/v1/signup", retry, "", signupKey)
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		// The session wasn't revoked by a replayed refresh token being presented again
+		w = sendPostRequest(
+			app, "/api/v1/token/refresh", handlers.RefreshTokenRequest{RefreshToken: rotated.RefreshToken}, "",
+		)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+	})
+
+	employees := int32(5)
+	create := handlers.CreateCompanyRequest{
+		Name:          "idem" + uuid.NewString()[:8],
+		EmployeeCount: &employees,
+		CompanyType:   "Corporation",
+	}
+	createKey := uuid.NewString()
+	w = sendIdempotentRequest(app, "/api/v1/company", create, tokens.AccessToken, createKey)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+	created := w.Body.String()
+	etag := w.Header().Get("ETag")
+
+	t.Run("Retried create gets the original response instead of a conflict", func(t *testing.T) {
+		w := sendIdempotentRequest(app, "/api/v1/company", create, tokens.AccessToken, createKey)
+		require.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "true", w.Header().Get("Idempotent-Replayed"))
+		assert.JSONEq(t, created, w.Body.String())
+		assert.Equal(t, etag, w.Header().Get("ETag"))
+		assert.NotEmpty(t, w.Header().Get("X-Request-ID"))
+	})
+
+	t.Run("Reusing a key for a different request is rejected", func(t *testing.T) {
+		other := create
+		other.Name = "idem" + uuid.NewString()[:8]
+		w := sendIdempotentRequest(app, "/api/v1/company", other, tokens.AccessToken, createKey)
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		var p problem.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+		assert.Equal(t, problem.CodeIdempotencyKeyReused, p.Code)
+	})
+
+	t.Run("Keys are scoped to the user", func(t *testing.T) {
+		w := sendPostRequest(app, "/api/v1/signup", handlers.UserSignupRequest{
+			Username: "user" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+			Password: "TestPassword123!",
+		}, "")
+		require.Equal(t, http.StatusCreated, w.Code)
+		var otherTokens handlers.AuthResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &otherTokens))
+
+		other := create
+		other.Name = "idem" + uuid.NewString()[:8]
+		w = sendIdempotentRequest(app, "/api/v1/company", other, otherTokens.AccessToken, createKey)
+		require.Equal(t, http.StatusCreated, w.Code)
+		assert.Empty(t, w.Header().Get("Idempotent-Replayed"))
+	})
+
+	t.Run("Oversized bodies are rejected before the handler runs", func(t *testing.T) {
+		other := create
+		other.Name = "idem" + uuid.NewString()[:8]
+		description := strings.Repeat("a", 64<<10)
+		other.Description = &description
+		w := sendIdempotentRequest(app, "/api/v1/company", other, tokens.AccessToken, uuid.NewString())
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		var p problem.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+		assert.Equal(t, problem.CodePayloadTooLarge, p.Code)
+	})
+
+	t.Run("Requests without a key are not deduplicated", func(t *testing.T) {
+		w := sendPostRequest(app, "/api/v1/company", create, tokens.AccessToken)
+		require.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+func sendIdempotentRequest(
+	app *app.App,
+	url string,
+	body any,
+	accessToken string,
+	idempotencyKey string,
+) *httptest.ResponseRecorder {
+	reqBody, _ := json.Marshal(body)
+	r := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(reqBody))
+	r.Header.Set("Content-Type", "application/json")
+	if accessToken != "" {
+		r.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	r.Header.Set("Idempotency-Key", idempotencyKey)
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	return w
+}
@@ -0,0 +1,147 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Laelapa/CompanyRegistry/internal/app"
+	"github.com/Laelapa/CompanyRegistry/internal/routes/handlers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportCompanies(t *testing.T) {
+	app := setupApp(t)
+
+	w := sendPostRequest(app, "/api/v1/signup", handlers.UserSignupRequest{
+		Username: "user" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Password: "TestPassword123!",
+	}, "")
+	require.Equal(t, http.StatusCreated, w.Code)
+	var tokens handlers.AuthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+
+	t.Run("Import requires authentication", func(t *testing.T) {
+		w := sendImportRequest(app, "", "text/csv", "name\n", "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Unsupported media types are rejected", func(t *testing.T) {
+		w := sendImportRequest(app, "", "application/json", "[]", tokens.AccessToken)
+		assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	})
+
+	t.Run("Unknown CSV columns are rejected", func(t *testing.T) {
+		w := sendImportRequest(app, "", "text/csv", "name,founded\nacme,1990\n", tokens.AccessToken)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("Best effort CSV import creates the valid rows", func(t *testing.T) {
+		first, second := "imp"+uuid.NewString()[:8], "imp"+uuid.NewString()[:8]
+		csv := "name,description,employee_count,registered,company_type\n" +
+			first + ",First,10,false,Corporation\n" +
+			second + ",,3,,NonProfit\n" +
+			"bad" + uuid.NewString()[:8] + ",,many,false,Corporation\n" +
+			first + ",Again,1,false,Corporation\n" +
+			"reg" + uuid.NewString()[:8] + ",,2,true,Corporation\n"
+
+		w := sendImportRequest(app, "best_effort", "text/csv", csv, tokens.AccessToken)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var report handlers.CompanyImportResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+
+		assert.True(t, report.Committed)
+		assert.Equal(t, 2, report.Created)
+		assert.Equal(t, 2, report.Invalid)
+		assert.Equal(t, 1, report.Skipped)
+		require.Len(t, report.Rows, 5)
+		assert.Equal(t, "created", report.Rows[0].Status)
+		assert.NotNil(t, report.Rows[0].ID)
+		assert.Equal(t, "invalid", report.Rows[2].Status)
+		require.NotEmpty(t, report.Rows[2].Errors)
+		assert.Equal(t, "employee_count", report.Rows[2].Errors[0].Field)
+		assert.Equal(t, "skipped", report.Rows[3].Status)
+		assert.Equal(t, "invalid", report.Rows[4].Status, "companies are imported pending, not registered")
+		require.NotEmpty(t, report.Rows[4].Errors)
+		assert.Equal(t, "registered", report.Rows[4].Errors[0].Field)
+
+		w = sendRequest(app, http.MethodGet, "/api/v1/company/by-name/"+second, nil, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var company handlers.CompanyResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &company))
+		assert.Nil(t, company.Description)
+		assert.Equal(t, "NonProfit", company.CompanyType)
+
+		w = sendRequest(app, http.MethodGet, "/api/v1/company/by-name/"+first, nil, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &company))
+		assert.Equal(t, "pending", company.Status)
+	})
+
+	t.Run("Dry runs write nothing", func(t *testing.T) {
+		name := "dry" + uuid.NewString()[:8]
+		ndjson := `{"name":"` + name + `","employee_count":4,"registered":false,"company_type":"Cooperative"}` +
+			"\n\n" + `{"name":""}` + "\n"
+
+		w := sendImportRequest(app, "dry_run", "application/x-ndjson", ndjson, tokens.AccessToken)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var report handlers.CompanyImportResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+
+		assert.False(t, report.Committed)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Invalid)
+		assert.Nil(t, report.Rows[0].ID)
+
+		w = sendRequest(app, http.MethodGet, "/api/v1/company/by-name/"+name, nil, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("All or nothing imports roll back on a conflict", func(t *testing.T) {
+		employees := int32(1)
+		existing := handlers.CreateCompanyRequest{
+			Name:          "aon" + uuid.NewString()[:8],
+			EmployeeCount: &employees,
+			CompanyType:   "Corporation",
+		}
+		w := sendPostRequest(app, "/api/v1/company", existing, tokens.AccessToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		fresh := "aon" + uuid.NewString()[:8]
+		csv := "name,employee_count,registered,company_type\n" +
+			fresh + ",2,false,Corporation\n" +
+			existing.Name + ",2,false,Corporation\n"
+
+		w = sendImportRequest(app, "", "text/csv", csv, tokens.AccessToken)
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+		var report handlers.CompanyImportResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+
+		assert.Equal(t, "all_or_nothing", report.Mode)
+		assert.False(t, report.Committed)
+		assert.Equal(t, 1, report.Skipped)
+
+		w = sendRequest(app, http.MethodGet, "/api/v1/company/by-name/"+fresh, nil, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+func sendImportRequest(app *app.App, mode, contentType, body, accessToken string) *httptest.ResponseRecorder {
+	url := "/api/v1/companies/import"
+	if mode != "" {
+		url += "?mode=" + mode
+	}
+	r := httptest.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	r.Header.Set("Content-Type", contentType)
+	if accessToken != "" {
+		r.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, r)
+	return w
+}
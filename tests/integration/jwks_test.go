@@ -0,0 +1,64 @@
+package integration_test
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Laelapa/CompanyRegistry/auth/tokenauthority"
+	"github.com/Laelapa/CompanyRegistry/internal/routes/handlers"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWKS(t *testing.T) {
+	app := setupApp(t)
+
+	var jwks tokenauthority.JWKSet
+
+	t.Run("Get JWKS", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &jwks))
+		require.Len(t, jwks.Keys, 1)
+
+		key := jwks.Keys[0]
+		assert.Equal(t, testSigningKeyID, key.Kid)
+		assert.Equal(t, "OKP", key.Kty)
+		assert.Equal(t, "Ed25519", key.Crv)
+		assert.Equal(t, "EdDSA", key.Alg)
+	})
+
+	t.Run("Issued tokens verify against the JWKS", func(t *testing.T) {
+		require.Len(t, jwks.Keys, 1)
+
+		w := sendPostRequest(app, "/api/v1/signup", handlers.UserSignupRequest{
+			Username: "user" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+			Password: "TestPassword123!",
+		}, "")
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var authResp handlers.AuthResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &authResp))
+
+		x, err := base64.RawURLEncoding.DecodeString(jwks.Keys[0].X)
+		require.NoError(t, err)
+
+		token, err := jwt.Parse(authResp.AccessToken, func(token *jwt.Token) (any, error) {
+			assert.Equal(t, testSigningKeyID, token.Header["kid"])
+			return ed25519.PublicKey(x), nil
+		}, jwt.WithValidMethods([]string{"EdDSA"}))
+		require.NoError(t, err)
+		assert.True(t, token.Valid)
+	})
+}
@@ -2,26 +2,45 @@ package integration_test
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
 	"database/sql"
+	"encoding/json"
+	"encoding/pem"
 	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/Laelapa/CompanyRegistry/auth/tokenauthority"
 	"github.com/Laelapa/CompanyRegistry/internal/app"
 	"github.com/Laelapa/CompanyRegistry/internal/config"
+	"github.com/Laelapa/CompanyRegistry/internal/metrics"
+	"github.com/Laelapa/CompanyRegistry/internal/migrations"
 	"github.com/Laelapa/CompanyRegistry/internal/repository"
 	"github.com/Laelapa/CompanyRegistry/internal/repository/adapters"
+	"github.com/Laelapa/CompanyRegistry/internal/routes/handlers"
 	"github.com/Laelapa/CompanyRegistry/internal/service"
 	"github.com/Laelapa/CompanyRegistry/logging"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/require"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 )
 
+const (
+	// testSigningKeyID is the kid of the key setupApp signs tokens with.
+	testSigningKeyID = "test-key"
+	// The admin setupApp bootstraps, see loginAdmin
+	testAdminUsername = "bootstrapadmin"
+	testAdminPassword = "AdminPassword123!"
+)
+
 var testDBPool *pgxpool.Pool //nolint:gochecknoglobals // Used by the integration test suite
 
 func TestMain(m *testing.M) {
@@ -93,34 +112,75 @@ func setupApp(t *testing.T) *app.App {
 
 	// TODO: develop test logger
 	logger, _ := logging.NewLogger(config.LoggingConfig{LoggerSetup: "prod"})
-	tokenAuth := tokenauthority.New(
+	tokenAuth, err := tokenauthority.New(
 		&config.AuthConfig{
-			JwtSecret:   "test-secret-key",
 			JwtIssuer:   "test-issuer",
 			JwtLifetime: 1 * time.Hour,
+			SigningKeys: []config.SigningKeyConfig{
+				{ID: testSigningKeyID, PEMFile: writeEd25519Key(t)},
+			},
 		},
 	)
+	require.NoError(t, err)
 	queries := repository.New(testDBPool)
+	transactor := adapters.NewPGTransactor(testDBPool)
+	appMetrics := metrics.New()
+	require.NoError(t, appMetrics.RegisterPool(testDBPool))
+
+	schemaVersion, err := migrations.LatestVersion()
+	require.NoError(t, err)
+
+	userRepo := adapters.NewPGUserRepoAdapter(queries)
+	companyTypes := service.NewCompanyTypeService(adapters.NewPGCompanyTypeRepoAdapter(queries))
+	sessions := service.NewSessionService(
+		adapters.NewPGSessionRepoAdapter(queries),
+		userRepo,
+		transactor,
+		tokenAuth,
+		logger,
+		24*time.Hour,
+	)
 
 	svc := &service.Service{
 		User: service.NewUserService(
-			adapters.NewPGUserRepoAdapter(queries),
-			tokenAuth,
-			logger,
+			userRepo,
+			transactor,
 			nil,
+			sessions,
+			logger,
+			appMetrics,
+			"doesn't-matter",
 			"doesn't-matter",
 		),
 		Company: service.NewCompanyService(
 			adapters.NewPGCompanyRepoAdapter(queries),
+			companyTypes,
+			nil,
+			transactor,
+			nil,
 			tokenAuth,
 			logger,
-			nil,
+			appMetrics,
+			"doesn't-matter",
 			"doesn't-matter",
 		),
+		CompanyType: companyTypes,
+		Session:     sessions,
+		Health:      service.NewHealthService(adapters.NewPGHealthAdapter(testDBPool), nil, schemaVersion, time.Second),
+		Idempotency: service.NewIdempotencyService(
+			adapters.NewPGIdempotencyRepoAdapter(queries),
+			logger,
+			time.Hour,
+			time.Minute,
+		),
 	}
+	require.NoError(t, svc.User.BootstrapAdmin(context.Background(), testAdminUsername, testAdminPassword))
+
 	srvCfg := &config.ServerConfig{
 		Port:            "8080",
+		AdminPort:       "9090",
 		ShutdownTimeout: 5 * time.Second,
+		Timeouts:        config.ServerTimeoutsConfig{ExportTimeout: time.Minute},
 	}
 
 	return app.New(
@@ -129,5 +189,34 @@ func setupApp(t *testing.T) *app.App {
 		svc,
 		tokenAuth,
 		nil,
+		appMetrics,
 	)
 }
+
+// writeEd25519Key generates an Ed25519 private key and writes it PEM encoded to a temporary file.
+func writeEd25519Key(t *testing.T) string {
+	t.Helper()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "signing-key.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	return path
+}
+
+// loginAdmin starts a session for the admin bootstrapped by setupApp.
+func loginAdmin(t *testing.T, app *app.App) handlers.AuthResponse {
+	t.Helper()
+
+	w := sendPostRequest(app, "/api/v1/login", handlers.UserLoginRequest{
+		Username: testAdminUsername,
+		Password: testAdminPassword,
+	}, "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var tokens handlers.AuthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+	return tokens
+}
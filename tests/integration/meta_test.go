@@ -0,0 +1,105 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/routes/handlers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompanyMeta(t *testing.T) {
+	app := setupApp(t)
+
+	username := "user" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	w := sendPostRequest(app, "/api/v1/signup", handlers.UserSignupRequest{
+		Username: username,
+		Password: "TestPassword123!",
+	}, "")
+	require.Equal(t, http.StatusCreated, w.Code)
+	var tokens handlers.AuthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+
+	employees := int32(7)
+	newCompany := handlers.CreateCompanyRequest{
+		Name:          "meta" + uuid.NewString()[:8],
+		EmployeeCount: &employees,
+		CompanyType:   "Corporation",
+	}
+	w = sendPostRequest(app, "/api/v1/company", newCompany, tokens.AccessToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var company handlers.CompanyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &company))
+	assert.Nil(t, company.Meta, "meta is only included on request")
+	url := "/api/v1/company/" + company.ID.String()
+
+	t.Run("Meta is omitted unless expanded", func(t *testing.T) {
+		w := sendRequest(app, http.MethodGet, url, nil, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NotContains(t, w.Body.String(), `"meta"`)
+	})
+
+	t.Run("Meta names the creator", func(t *testing.T) {
+		w := sendRequest(app, http.MethodGet, url+"?expand=meta", nil, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var fetched handlers.CompanyResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &fetched))
+
+		require.NotNil(t, fetched.Meta)
+		assert.Equal(t, int64(1), fetched.Meta.Version)
+		assert.NotNil(t, fetched.Meta.CreatedAt)
+		require.NotNil(t, fetched.Meta.CreatedBy)
+		assert.Equal(t, username, fetched.Meta.CreatedBy.Username)
+		assert.Nil(t, fetched.Meta.UpdatedAt)
+		assert.Nil(t, fetched.Meta.UpdatedBy)
+	})
+
+	newName := "meta" + uuid.NewString()[:8]
+	w = sendRequest(app, http.MethodPatch, url, handlers.UpdateCompanyRequest{Name: &newName}, tokens.AccessToken)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	t.Run("Meta names the last updater", func(t *testing.T) {
+		w := sendRequest(app, http.MethodGet, "/api/v1/company/by-name/"+newName+"?expand=meta", nil, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var fetched handlers.CompanyResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &fetched))
+
+		require.NotNil(t, fetched.Meta)
+		assert.Equal(t, int64(2), fetched.Meta.Version)
+		assert.NotNil(t, fetched.Meta.UpdatedAt)
+		require.NotNil(t, fetched.Meta.UpdatedBy)
+		assert.Equal(t, username, fetched.Meta.UpdatedBy.Username)
+		assert.Equal(t, fetched.Meta.CreatedBy.ID, fetched.Meta.UpdatedBy.ID)
+	})
+
+	t.Run("Listings expand meta for every company", func(t *testing.T) {
+		w := sendRequest(app, http.MethodGet, url+"?expand=meta", nil, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var fetched handlers.CompanyResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &fetched))
+		userID := fetched.Meta.CreatedBy.ID
+
+		w = sendRequest(app, http.MethodGet, "/api/v1/companies?expand=meta&created_by="+userID.String(), nil, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var list handlers.CompanyListResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+		require.Len(t, list.Companies, 1)
+		require.NotNil(t, list.Companies[0].Meta)
+		assert.Equal(t, username, list.Companies[0].Meta.CreatedBy.Username)
+	})
+
+	t.Run("Invalid expansions are rejected", func(t *testing.T) {
+		w := sendRequest(app, http.MethodGet, url+"?expand=owner", nil, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		asOf := time.Now().UTC().Format(time.RFC3339)
+		w = sendRequest(app, http.MethodGet, url+"?expand=meta&as_of="+asOf, nil, "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
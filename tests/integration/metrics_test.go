@@ -0,0 +1,56 @@
+package integration_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Laelapa/CompanyRegistry/internal/routes/handlers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	app := setupApp(t)
+
+	username := "metrics" + uuid.NewString()[:8]
+	w := sendPostRequest(app, "/api/v1/signup", handlers.UserSignupRequest{
+		Username: username,
+		Password: "TestPassword123!",
+	}, "")
+	require.Equal(t, http.StatusCreated, w.Code)
+	w = sendPostRequest(app, "/api/v1/login", handlers.UserLoginRequest{
+		Username: username,
+		Password: "WrongPassword123!",
+	}, "")
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	w = sendRequest(app, http.MethodGet, "/api/v1/company/"+uuid.NewString(), nil, "")
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	t.Run("Metrics are not served by the public API", func(t *testing.T) {
+		w := sendRequest(app, http.MethodGet, "/metrics", nil, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Admin server exposes the recorded metrics", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		app.AdminHandler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		body := w.Body.String()
+		for _, want := range []string{
+			`company_registry_http_requests_total{method="POST",route="/api/v1/signup",status="201"} 1`,
+			`company_registry_http_requests_total{method="GET",route="/api/v1/company/{id}",status="404"} 1`,
+			`company_registry_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+			`company_registry_http_request_duration_seconds_count{method="POST",route="/api/v1/login",status="401"} 1`,
+			`company_registry_logins_total{result="failure"} 1`,
+			"company_registry_db_pool_acquired_connections",
+			"company_registry_db_pool_acquire_duration_seconds_total",
+		} {
+			assert.True(t, strings.Contains(body, want), "missing %s", want)
+		}
+	})
+}
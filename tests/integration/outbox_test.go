@@ -0,0 +1,253 @@
+package integration_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/auth/tokenauthority"
+	"github.com/Laelapa/CompanyRegistry/internal/config"
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/repository"
+	"github.com/Laelapa/CompanyRegistry/internal/repository/adapters"
+	"github.com/Laelapa/CompanyRegistry/internal/service"
+	"github.com/Laelapa/CompanyRegistry/internal/tracing"
+	"github.com/Laelapa/CompanyRegistry/logging"
+	"github.com/Laelapa/CompanyRegistry/util/ctxutils"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// flakyProducer records produced events and their headers by key and fails the first failFirst calls.
+// Values containing poison are always rejected. Every call starts a producer span, like events.Producer does.
+type flakyProducer struct {
+	mu        sync.Mutex
+	failFirst int
+	poison    []byte
+	calls     int
+	values    map[string][]byte
+	headers   map[string]map[string]string
+}
+
+func (p *flakyProducer) Produce(ctx context.Context, topic, key string, value []byte, headers map[string]string) error {
+	_, span := tracing.Tracer().Start(ctx, "send "+topic, trace.WithSpanKind(trace.SpanKindProducer))
+	defer span.End()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.calls++
+	if p.calls <= p.failFirst {
+		return errors.New("broker unavailable")
+	}
+	if p.poison != nil && bytes.Contains(value, p.poison) {
+		return errors.New("message too large")
+	}
+	if p.values == nil {
+		p.values = make(map[string][]byte)
+		p.headers = make(map[string]map[string]string)
+	}
+	p.values[key] = value
+	p.headers[key] = headers
+	return nil
+}
+
+func (p *flakyProducer) produced(key string) ([]byte, map[string]string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	value, ok := p.values[key]
+	return value, p.headers[key], ok
+}
+
+func TestOutboxRelay(t *testing.T) {
+	logger, _ := logging.NewLogger(config.LoggingConfig{LoggerSetup: "prod"})
+	queries := repository.New(testDBPool)
+	transactor := adapters.NewPGTransactor(testDBPool)
+	outbox := adapters.NewPGOutboxRepoAdapter(queries)
+
+	tokenAuth, err := tokenauthority.New(&config.AuthConfig{JwtSecret: "test-secret-key"})
+	require.NoError(t, err)
+
+	companySvc := service.NewCompanyService(
+		adapters.NewPGCompanyRepoAdapter(queries),
+		service.NewCompanyTypeService(adapters.NewPGCompanyTypeRepoAdapter(queries)),
+		nil,
+		transactor,
+		outbox,
+		tokenAuth,
+		logger,
+		nil,
+		"company.mutations",
+		"company-registry",
+	)
+
+	username := "outbox" + uuid.NewString()[:8]
+	passwordHash := "not-a-real-hash"
+	user, err := adapters.NewPGUserRepoAdapter(queries).Create(context.Background(), &domain.User{
+		Username:     &username,
+		PasswordHash: &passwordHash,
+	})
+	require.NoError(t, err)
+
+	name := "outbox" + uuid.NewString()[:8]
+	var ec int32 = 5
+	reg := false
+	ct := domain.CompanyType("Cooperative")
+
+	// Mutate on behalf of a traced request
+	_, err = tracing.Setup(context.Background(), &config.TracingConfig{Exporter: config.TracingExporterNone}, "", "")
+	require.NoError(t, err)
+	traceID := trace.TraceID(uuid.New())
+	reqCtx := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     trace.SpanID{1},
+		TraceFlags: trace.FlagsSampled,
+	}))
+	requestID := "outbox-" + uuid.NewString()
+	reqCtx = ctxutils.SetRequestIDInContext(reqCtx, requestID)
+
+	created, err := companySvc.Create(reqCtx, &domain.Company{
+		Name:          &name,
+		EmployeeCount: &ec,
+		Registered:    &reg,
+		CompanyType:   &ct,
+		CreatedBy:     user.ID,
+	})
+	require.NoError(t, err)
+
+	// The relay runs outside any request, it has to continue the trace the event carries
+	recorder := tracetest.NewSpanRecorder()
+	prevProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(prevProvider) })
+
+	// The first publish attempt fails, the relay has to retry it
+	producer := &flakyProducer{failFirst: 1}
+	relay := service.NewOutboxRelay(outbox, transactor, producer, logger, nil, &config.OutboxConfig{
+		PollInterval:     50 * time.Millisecond,
+		BatchSize:        10,
+		PublishTimeout:   time.Second,
+		MaxAttempts:      5,
+		MaxRetryBackoff:  time.Second,
+		LagWarnThreshold: time.Minute,
+		DrainTimeout:     time.Second,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relay.Run(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		_, _, ok := producer.produced(created.ID.String())
+		return ok
+	}, 10*time.Second, 50*time.Millisecond)
+
+	cancel()
+	<-done
+
+	value, headers, _ := producer.produced(created.ID.String())
+	var event struct {
+		service.CloudEvent
+		Data service.CompanyEventData `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(value, &event))
+	assert.Equal(t, "1.0", event.SpecVersion)
+	assert.Equal(t, service.EventTypeCompanyCreated, event.Type)
+	assert.Equal(t, "company-registry", event.Source)
+	assert.Equal(t, created.ID.String(), event.Subject)
+	assert.NotEmpty(t, event.ID)
+	assert.Equal(t, service.EventSchemaVersion, event.Data.SchemaVersion)
+	assert.Equal(t, *user.ID, event.Data.Actor)
+	assert.Equal(t, name, event.Data.Company.Name)
+	assert.Contains(t, headers["traceparent"], traceID.String(), "the trace of the request should carry over")
+	assert.Equal(t, requestID, headers[service.EventHeaderRequestID])
+
+	var sends []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "send company.mutations" && span.SpanContext().TraceID() == traceID {
+			sends = append(sends, span)
+		}
+	}
+	require.NotEmpty(t, sends)
+	for _, span := range sends {
+		assert.Equal(t, trace.SpanID{1}, span.Parent().SpanID(), "the publish should be a child of the request")
+	}
+
+	backlog, err := outbox.Backlog(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, backlog.Pending)
+}
+
+func TestOutboxRelayParksFailingEvents(t *testing.T) {
+	logger, _ := logging.NewLogger(config.LoggingConfig{LoggerSetup: "prod"})
+	outbox := adapters.NewPGOutboxRepoAdapter(repository.New(testDBPool))
+
+	topic := "outbox.parking." + uuid.NewString()[:8]
+	key := uuid.NewString()
+	for _, payload := range []string{`{"fate":"rejected"}`, `{"fate":"accepted"}`} {
+		require.NoError(t, outbox.Enqueue(context.Background(), &domain.OutboxMessage{
+			Topic:   topic,
+			Key:     key,
+			Payload: []byte(payload),
+			Headers: map[string]string{},
+		}))
+	}
+
+	// The first event is rejected for good, once parked it no longer holds back the second one
+	producer := &flakyProducer{poison: []byte("rejected")}
+	relay := service.NewOutboxRelay(outbox, adapters.NewPGTransactor(testDBPool), producer, logger, nil,
+		&config.OutboxConfig{
+			PollInterval:     50 * time.Millisecond,
+			BatchSize:        10,
+			PublishTimeout:   time.Second,
+			MaxAttempts:      2,
+			MaxRetryBackoff:  100 * time.Millisecond,
+			LagWarnThreshold: time.Minute,
+			DrainTimeout:     time.Second,
+		},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		relay.Run(ctx)
+	}()
+
+	assert.Eventually(t, func() bool {
+		_, _, ok := producer.produced(key)
+		return ok
+	}, 10*time.Second, 50*time.Millisecond)
+
+	cancel()
+	<-done
+
+	value, _, _ := producer.produced(key)
+	assert.JSONEq(t, `{"fate":"accepted"}`, string(value))
+
+	var attempts int32
+	var lastError string
+	err := testDBPool.QueryRow(context.Background(),
+		"SELECT attempts, last_error FROM outbox WHERE topic = $1 AND parked_at IS NOT NULL", topic,
+	).Scan(&attempts, &lastError)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), attempts)
+	assert.Equal(t, "message too large", lastError)
+
+	backlog, err := outbox.Backlog(context.Background())
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, backlog.Parked, int64(1))
+}
@@ -0,0 +1,92 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Laelapa/CompanyRegistry/internal/middleware"
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
+	"github.com/Laelapa/CompanyRegistry/internal/routes/handlers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProblemDetails(t *testing.T) {
+	app := setupApp(t)
+
+	decode := func(t *testing.T, w *httptest.ResponseRecorder) problem.Problem {
+		t.Helper()
+		assert.Equal(t, problem.ContentType, w.Header().Get("Content-Type"))
+		var p problem.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+		assert.Equal(t, w.Code, p.Status)
+		return p
+	}
+
+	w := sendPostRequest(app, "/api/v1/signup", handlers.UserSignupRequest{
+		Username: "user" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Password: "TestPassword123!",
+	}, "")
+	require.Equal(t, http.StatusCreated, w.Code)
+	var tokens handlers.AuthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+
+	t.Run("Validation failures list the offending fields", func(t *testing.T) {
+		employees := int32(-1)
+		w := sendPostRequest(app, "/api/v1/company", handlers.CreateCompanyRequest{
+			Name:          "a name way over fifteen characters",
+			EmployeeCount: &employees,
+			CompanyType:   "a type way over twenty characters",
+		}, tokens.AccessToken)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		p := decode(t, w)
+		assert.Equal(t, problem.CodeValidationFailed, p.Code)
+		assert.Equal(t, "/api/v1/company", p.Instance)
+		assert.Equal(t, w.Header().Get(middleware.HeaderRequestID), p.RequestID)
+
+		rules := make(map[string]string, len(p.Errors))
+		for _, fe := range p.Errors {
+			rules[fe.Field] = fe.Rule
+			assert.NotEmpty(t, fe.Message)
+		}
+		assert.Equal(t, map[string]string{
+			"name":           "max",
+			"employee_count": "gte",
+			"company_type":   "max",
+		}, rules)
+	})
+
+	t.Run("Query parameters are reported by name", func(t *testing.T) {
+		w := sendRequest(app, http.MethodGet, "/api/v1/companies?sort_by=founded", nil, "")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		p := decode(t, w)
+		require.Len(t, p.Errors, 1)
+		assert.Equal(t, "sort_by", p.Errors[0].Field)
+	})
+
+	t.Run("Domain errors map to stable codes", func(t *testing.T) {
+		w := sendRequest(app, http.MethodGet, "/api/v1/company/"+uuid.NewString(), nil, "")
+		require.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, problem.CodeNotFound, decode(t, w).Code)
+
+		w = sendPostRequest(app, "/api/v1/login", handlers.UserLoginRequest{
+			Username: "nobody" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+			Password: "wrong",
+		}, "")
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, problem.CodeInvalidCredentials, decode(t, w).Code)
+	})
+
+	t.Run("Authentication failures are problems too", func(t *testing.T) {
+		w := sendPostRequest(app, "/api/v1/company", handlers.CreateCompanyRequest{}, "")
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, problem.CodeUnauthorized, decode(t, w).Code)
+	})
+}
@@ -0,0 +1,171 @@
+package integration_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Laelapa/CompanyRegistry/internal/config"
+	"github.com/Laelapa/CompanyRegistry/internal/domain"
+	"github.com/Laelapa/CompanyRegistry/internal/repository"
+	"github.com/Laelapa/CompanyRegistry/internal/repository/adapters"
+	"github.com/Laelapa/CompanyRegistry/internal/routes/handlers"
+	"github.com/Laelapa/CompanyRegistry/internal/service"
+	"github.com/Laelapa/CompanyRegistry/logging"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleBasedAccess(t *testing.T) {
+	app := setupApp(t)
+
+	signup := func(t *testing.T) (username string, tokens handlers.AuthResponse) {
+		t.Helper()
+		username = "user" + strings.ReplaceAll(uuid.NewString(), "-", "")
+		w := sendPostRequest(app, "/api/v1/signup", handlers.UserSignupRequest{
+			Username: username,
+			Password: "TestPassword123!",
+		}, "")
+		require.Equal(t, http.StatusCreated, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+		return username, tokens
+	}
+
+	userID := func(t *testing.T, username string) uuid.UUID {
+		t.Helper()
+		var id uuid.UUID
+		err := testDBPool.QueryRow(context.Background(), "SELECT ID FROM users WHERE username = $1", username).Scan(&id)
+		require.NoError(t, err)
+		return id
+	}
+
+	_, owner := signup(t)
+	strangerName, stranger := signup(t)
+	// Roles can only be assigned by admins, the first one is bootstrapped
+	admin := loginAdmin(t, app)
+
+	var companyID uuid.UUID
+	ec := int32(10)
+	patch := handlers.UpdateCompanyRequest{EmployeeCount: &ec}
+
+	t.Run("Any user can create a company", func(t *testing.T) {
+		employees := int32(5)
+		w := sendPostRequest(app, "/api/v1/company", handlers.CreateCompanyRequest{
+			Name:          "rbac" + uuid.NewString()[:8],
+			EmployeeCount: &employees,
+			CompanyType:   "Corporation",
+		}, owner.AccessToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		var resp handlers.CompanyResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		companyID = resp.ID
+	})
+
+	t.Run("Viewer cannot modify someone else's company", func(t *testing.T) {
+		w := sendRequest(app, http.MethodPatch, "/api/v1/company/"+companyID.String(), patch, stranger.AccessToken)
+		require.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), "creator")
+
+		w = sendRequest(app, http.MethodDelete, "/api/v1/company/"+companyID.String(), nil, stranger.AccessToken)
+		require.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Creator can modify their company", func(t *testing.T) {
+		w := sendRequest(app, http.MethodPatch, "/api/v1/company/"+companyID.String(), patch, owner.AccessToken)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("Non-admin cannot assign roles", func(t *testing.T) {
+		w := sendRequest(
+			app, http.MethodPut, "/api/v1/user/"+userID(t, strangerName).String()+"/role",
+			handlers.AssignRoleRequest{Role: "admin"}, stranger.AccessToken,
+		)
+		require.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Admin can promote a user to editor", func(t *testing.T) {
+		w := sendRequest(
+			app, http.MethodPut, "/api/v1/user/"+userID(t, strangerName).String()+"/role",
+			handlers.AssignRoleRequest{Role: "editor"}, admin.AccessToken,
+		)
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		// The new role is carried by the next access token
+		w = sendPostRequest(
+			app, "/api/v1/token/refresh", handlers.RefreshTokenRequest{RefreshToken: stranger.RefreshToken}, "",
+		)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &stranger))
+	})
+
+	t.Run("Editor can modify any company", func(t *testing.T) {
+		w := sendRequest(app, http.MethodPatch, "/api/v1/company/"+companyID.String(), patch, stranger.AccessToken)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		w = sendRequest(app, http.MethodDelete, "/api/v1/company/"+companyID.String(), nil, stranger.AccessToken)
+		require.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("Bootstrap never promotes a user with another password", func(t *testing.T) {
+		logger, _ := logging.NewLogger(config.LoggingConfig{LoggerSetup: "prod"})
+		users := service.NewUserService(
+			adapters.NewPGUserRepoAdapter(repository.New(testDBPool)),
+			adapters.NewPGTransactor(testDBPool),
+			nil,
+			nil,
+			logger,
+			nil,
+			"doesn't-matter",
+			"doesn't-matter",
+		)
+		ownerName, _ := signup(t)
+		err := users.BootstrapAdmin(context.Background(), ownerName, "NotTheirPassword1!")
+		require.ErrorIs(t, err, domain.ErrBadCredentials)
+
+		var role string
+		err = testDBPool.QueryRow(context.Background(), "SELECT role FROM users WHERE username = $1", ownerName).Scan(&role)
+		require.NoError(t, err)
+		assert.Equal(t, "viewer", role)
+	})
+
+	t.Run("Instances bootstrapping the same admin concurrently all start", func(t *testing.T) {
+		logger, _ := logging.NewLogger(config.LoggingConfig{LoggerSetup: "prod"})
+		users := service.NewUserService(
+			adapters.NewPGUserRepoAdapter(repository.New(testDBPool)),
+			adapters.NewPGTransactor(testDBPool),
+			nil,
+			nil,
+			logger,
+			nil,
+			"doesn't-matter",
+			"doesn't-matter",
+		)
+		adminName := "admin" + strings.ReplaceAll(uuid.NewString(), "-", "")
+
+		errs := make(chan error, 4)
+		for range cap(errs) {
+			go func() { errs <- users.BootstrapAdmin(context.Background(), adminName, "AdminPassword123!") }()
+		}
+		for range cap(errs) {
+			require.NoError(t, <-errs)
+		}
+
+		var role string
+		err := testDBPool.QueryRow(context.Background(), "SELECT role FROM users WHERE username = $1", adminName).Scan(&role)
+		require.NoError(t, err)
+		assert.Equal(t, "admin", role)
+	})
+
+	t.Run("Assigning an unknown role fails", func(t *testing.T) {
+		w := sendRequest(
+			app, http.MethodPut, "/api/v1/user/"+userID(t, strangerName).String()+"/role",
+			handlers.AssignRoleRequest{Role: "superuser"}, admin.AccessToken,
+		)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,42 @@
+package integration_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Laelapa/CompanyRegistry/internal/middleware"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	app := setupApp(t)
+
+	send := func(requestID string) string {
+		r := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+		if requestID != "" {
+			r.Header.Set(middleware.HeaderRequestID, requestID)
+		}
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+		return w.Header().Get(middleware.HeaderRequestID)
+	}
+
+	t.Run("Echoes the ID sent by the client", func(t *testing.T) {
+		assert.Equal(t, "edge-proxy:42.a_b", send("edge-proxy:42.a_b"))
+	})
+
+	t.Run("Generates an ID when none is sent", func(t *testing.T) {
+		_, err := uuid.Parse(send(""))
+		assert.NoError(t, err)
+	})
+
+	t.Run("Replaces a malformed ID", func(t *testing.T) {
+		got := send("bad id\r\nwith spaces")
+		assert.NotEqual(t, "bad id\r\nwith spaces", got)
+		_, err := uuid.Parse(got)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,57 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/Laelapa/CompanyRegistry/internal/routes/handlers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchSnippets(t *testing.T) {
+	app := setupApp(t)
+
+	w := sendPostRequest(app, "/api/v1/signup", handlers.UserSignupRequest{
+		Username: "user" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Password: "TestPassword123!",
+	}, "")
+	require.Equal(t, http.StatusCreated, w.Code)
+	var tokens handlers.AuthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+
+	// A word unlikely to match other companies in the shared database
+	word := "zx" + strings.ReplaceAll(uuid.NewString(), "-", "")[:10]
+	description := "Makes " + word + ` <script>alert("xss")</script> and <img src=x onerror=alert(1)> gadgets`
+	employees := int32(3)
+	w = sendPostRequest(app, "/api/v1/company", handlers.CreateCompanyRequest{
+		Name:          "srch" + uuid.NewString()[:8],
+		Description:   &description,
+		EmployeeCount: &employees,
+		CompanyType:   "Corporation",
+	}, tokens.AccessToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var company handlers.CompanyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &company))
+
+	t.Run("Snippets escape the description and only mark matches", func(t *testing.T) {
+		w := sendRequest(app, http.MethodGet, "/api/v1/companies/search?q="+url.QueryEscape(word), nil, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp handlers.CompanySearchResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+		require.Len(t, resp.Results, 1)
+		assert.Equal(t, company.ID, resp.Results[0].Company.ID)
+		require.NotNil(t, resp.Results[0].Snippet)
+		snippet := *resp.Results[0].Snippet
+		assert.Contains(t, snippet, "<mark>"+word+"</mark>")
+		assert.NotContains(t, snippet, "<script")
+		assert.NotContains(t, snippet, "<img")
+		assert.Equal(t, 2, strings.Count(snippet, "<"), "only the <mark> tags are markup")
+	})
+}
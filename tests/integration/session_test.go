@@ -0,0 +1,147 @@
+package integration_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/config"
+	"github.com/Laelapa/CompanyRegistry/internal/repository"
+	"github.com/Laelapa/CompanyRegistry/internal/repository/adapters"
+	"github.com/Laelapa/CompanyRegistry/internal/routes/handlers"
+	"github.com/Laelapa/CompanyRegistry/internal/service"
+	"github.com/Laelapa/CompanyRegistry/logging"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionFlow(t *testing.T) {
+	app := setupApp(t)
+
+	username := "user" + strings.ReplaceAll(uuid.NewString(), "-", "")
+	password := "TestPassword123!"
+
+	var signup, refreshed, login handlers.AuthResponse
+
+	decodeAuth := func(t *testing.T, body []byte) handlers.AuthResponse {
+		t.Helper()
+		var resp handlers.AuthResponse
+		require.NoError(t, json.Unmarshal(body, &resp))
+		require.NotEmpty(t, resp.AccessToken)
+		require.NotEmpty(t, resp.RefreshToken)
+		return resp
+	}
+
+	refresh := func(refreshToken string) int {
+		return sendPostRequest(
+			app, "/api/v1/token/refresh", handlers.RefreshTokenRequest{RefreshToken: refreshToken}, "",
+		).Code
+	}
+
+	t.Run("Signup returns a token pair", func(t *testing.T) {
+		w := sendPostRequest(app, "/api/v1/signup", handlers.UserSignupRequest{
+			Username: username,
+			Password: password,
+		}, "")
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		signup = decodeAuth(t, w.Body.Bytes())
+		assert.Equal(t, "Bearer", signup.TokenType)
+		assert.Positive(t, signup.ExpiresIn)
+	})
+
+	t.Run("Refresh rotates the token pair", func(t *testing.T) {
+		w := sendPostRequest(
+			app, "/api/v1/token/refresh", handlers.RefreshTokenRequest{RefreshToken: signup.RefreshToken}, "",
+		)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		refreshed = decodeAuth(t, w.Body.Bytes())
+		assert.NotEqual(t, signup.RefreshToken, refreshed.RefreshToken)
+		assert.NotEqual(t, signup.AccessToken, refreshed.AccessToken)
+	})
+
+	t.Run("Refresh with unknown token fails", func(t *testing.T) {
+		require.Equal(t, http.StatusUnauthorized, refresh("not-a-refresh-token"))
+	})
+
+	t.Run("Reusing a rotated refresh token revokes the session", func(t *testing.T) {
+		require.Equal(t, http.StatusUnauthorized, refresh(signup.RefreshToken))
+
+		// The legitimate successor is revoked along with every access token of the session
+		require.Equal(t, http.StatusUnauthorized, refresh(refreshed.RefreshToken))
+		w := sendPostRequest(app, "/api/v1/logout", nil, refreshed.AccessToken)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Login starts a new session", func(t *testing.T) {
+		w := sendPostRequest(app, "/api/v1/login", handlers.UserLoginRequest{
+			Username: username,
+			Password: password,
+		}, "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		login = decodeAuth(t, w.Body.Bytes())
+	})
+
+	t.Run("Logout revokes the session", func(t *testing.T) {
+		w := sendPostRequest(app, "/api/v1/logout", nil, login.AccessToken)
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		w = sendPostRequest(app, "/api/v1/logout", nil, login.AccessToken)
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+		require.Equal(t, http.StatusUnauthorized, refresh(login.RefreshToken))
+	})
+
+	t.Run("Expired sessions are pruned", func(t *testing.T) {
+		signupHash := sha256.Sum256([]byte(signup.RefreshToken))
+		var familyID uuid.UUID
+		err := testDBPool.QueryRow(context.Background(),
+			"SELECT family_id FROM refresh_tokens WHERE token_hash = $1", signupHash[:],
+		).Scan(&familyID)
+		require.NoError(t, err)
+		_, err = testDBPool.Exec(context.Background(),
+			"UPDATE refresh_tokens SET expires_at = expires_at - interval '60 days' WHERE family_id = $1", familyID,
+		)
+		require.NoError(t, err)
+
+		w := sendPostRequest(app, "/api/v1/login", handlers.UserLoginRequest{
+			Username: username,
+			Password: password,
+		}, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		live := decodeAuth(t, w.Body.Bytes())
+
+		logger, _ := logging.NewLogger(config.LoggingConfig{LoggerSetup: "prod"})
+		sessions := service.NewSessionService(
+			adapters.NewPGSessionRepoAdapter(repository.New(testDBPool)),
+			nil,
+			nil,
+			nil,
+			logger,
+			time.Hour,
+		)
+		pruned, err := sessions.Prune(context.Background())
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, pruned, 1)
+
+		var remaining int
+		err = testDBPool.QueryRow(context.Background(),
+			"SELECT count(*) FROM refresh_tokens WHERE family_id = $1", familyID,
+		).Scan(&remaining)
+		require.NoError(t, err)
+		assert.Zero(t, remaining)
+		require.Equal(t, http.StatusOK, refresh(live.RefreshToken))
+	})
+
+	t.Run("Logout without token fails", func(t *testing.T) {
+		w := sendPostRequest(app, "/api/v1/logout", nil, "")
+		require.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -0,0 +1,147 @@
+package integration_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Laelapa/CompanyRegistry/internal/config"
+	"github.com/Laelapa/CompanyRegistry/internal/repository"
+	"github.com/Laelapa/CompanyRegistry/internal/repository/adapters"
+	"github.com/Laelapa/CompanyRegistry/internal/routes/handlers"
+	"github.com/Laelapa/CompanyRegistry/internal/service"
+	"github.com/Laelapa/CompanyRegistry/logging"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoftDelete(t *testing.T) {
+	app := setupApp(t)
+
+	w := sendPostRequest(app, "/api/v1/signup", handlers.UserSignupRequest{
+		Username: "user" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Password: "TestPassword123!",
+	}, "")
+	require.Equal(t, http.StatusCreated, w.Code)
+	var tokens handlers.AuthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+
+	employees := int32(5)
+	newCompany := handlers.CreateCompanyRequest{
+		Name:          "soft" + uuid.NewString()[:8],
+		EmployeeCount: &employees,
+		CompanyType:   "Corporation",
+	}
+	w = sendPostRequest(app, "/api/v1/company", newCompany, tokens.AccessToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var company handlers.CompanyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &company))
+	url := "/api/v1/company/" + company.ID.String()
+
+	w = sendRequest(app, http.MethodDelete, url, nil, tokens.AccessToken)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	t.Run("Deleted companies are hidden from reads", func(t *testing.T) {
+		w := sendRequest(app, http.MethodGet, url, nil, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = sendRequest(app, http.MethodGet, "/api/v1/company/by-name/"+newCompany.Name, nil, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = sendRequest(app, http.MethodDelete, url, nil, tokens.AccessToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Restore requires authentication", func(t *testing.T) {
+		w := sendRequest(app, http.MethodPost, url+"/restore", nil, "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Restore is refused while another company holds the name", func(t *testing.T) {
+		w := sendPostRequest(app, "/api/v1/company", newCompany, tokens.AccessToken)
+		require.Equal(t, http.StatusCreated, w.Code)
+		var namesake handlers.CompanyResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &namesake))
+
+		w = sendRequest(app, http.MethodPost, url+"/restore", nil, tokens.AccessToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+
+		w = sendRequest(app, http.MethodDelete, "/api/v1/company/"+namesake.ID.String(), nil, tokens.AccessToken)
+		require.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("Restored company is readable again", func(t *testing.T) {
+		w := sendRequest(app, http.MethodPost, url+"/restore", nil, tokens.AccessToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		// Created at 1, deleted at 2, restored at 3
+		assert.Equal(t, `"3"`, w.Header().Get("ETag"))
+
+		w = sendRequest(app, http.MethodGet, url, nil, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var restored handlers.CompanyResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &restored))
+		assert.Equal(t, company, restored)
+
+		w = sendRequest(app, http.MethodPost, url+"/restore", nil, tokens.AccessToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("Restore shows up in the history", func(t *testing.T) {
+		w := sendRequest(app, http.MethodGet, url+"/history", nil, tokens.AccessToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var history handlers.CompanyHistoryResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+		require.Len(t, history.Revisions, 3)
+		assert.Equal(t, "restore", history.Revisions[0].Operation)
+		assert.Equal(t, "delete", history.Revisions[1].Operation)
+	})
+
+	t.Run("Purge removes deleted companies past their retention", func(t *testing.T) {
+		w := sendRequest(app, http.MethodDelete, url, nil, tokens.AccessToken)
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		logger, _ := logging.NewLogger(config.LoggingConfig{LoggerSetup: "prod"})
+		queries := repository.New(testDBPool)
+		purgeCfg := &config.CompanyPurgeConfig{Retention: time.Hour, Interval: time.Hour, BatchSize: 2}
+		purger := service.NewCompanyPurger(
+			adapters.NewPGCompanyRepoAdapter(queries),
+			adapters.NewPGTransactor(testDBPool),
+			nil,
+			logger,
+			nil,
+			purgeCfg,
+			"doesn't-matter",
+			"doesn't-matter",
+		)
+
+		// Still within the retention period
+		_, err := purger.Purge(context.Background())
+		require.NoError(t, err)
+		w = sendRequest(app, http.MethodPost, url+"/restore", nil, tokens.AccessToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		w = sendRequest(app, http.MethodDelete, url, nil, tokens.AccessToken)
+		require.Equal(t, http.StatusNoContent, w.Code)
+
+		purgeCfg.Retention = time.Nanosecond
+		purged, err := purger.Purge(context.Background())
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, purged, 1)
+
+		var remaining int
+		err = testDBPool.QueryRow(
+			context.Background(), "SELECT count(*) FROM companies WHERE ID = $1", company.ID,
+		).Scan(&remaining)
+		require.NoError(t, err)
+		assert.Zero(t, remaining)
+
+		w = sendRequest(app, http.MethodPost, url+"/restore", nil, tokens.AccessToken)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		// The history outlives the company
+		w = sendRequest(app, http.MethodGet, url+"/history", nil, tokens.AccessToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,168 @@
+package integration_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/Laelapa/CompanyRegistry/internal/problem"
+	"github.com/Laelapa/CompanyRegistry/internal/routes/handlers"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompanyTransitions(t *testing.T) {
+	app := setupApp(t)
+
+	w := sendPostRequest(app, "/api/v1/signup", handlers.UserSignupRequest{
+		Username: "user" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Password: "TestPassword123!",
+	}, "")
+	require.Equal(t, http.StatusCreated, w.Code)
+	var tokens handlers.AuthResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &tokens))
+	admin := loginAdmin(t, app)
+
+	employees := int32(12)
+	unregistered := false
+	w = sendPostRequest(app, "/api/v1/company", handlers.CreateCompanyRequest{
+		Name:          "life" + uuid.NewString()[:8],
+		EmployeeCount: &employees,
+		Registered:    &unregistered,
+		CompanyType:   "Corporation",
+	}, tokens.AccessToken)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var company handlers.CompanyResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &company))
+	assert.Equal(t, "pending", company.Status)
+	assert.False(t, company.Registered)
+	url := "/api/v1/company/" + company.ID.String()
+
+	transition := func(action, reason string) *handlers.CompanyResponse {
+		t.Helper()
+		w := sendPostRequest(app, url+"/transitions", handlers.TransitionCompanyRequest{
+			Action: action,
+			Reason: reason,
+		}, admin.AccessToken)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var resp handlers.CompanyResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return &resp
+	}
+
+	t.Run("Transitions require authentication", func(t *testing.T) {
+		w := sendPostRequest(app, url+"/transitions", handlers.TransitionCompanyRequest{
+			Action: "approve",
+			Reason: "Paperwork complete",
+		}, "")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Creators cannot change the status of their own company", func(t *testing.T) {
+		w := sendPostRequest(app, url+"/transitions", handlers.TransitionCompanyRequest{
+			Action: "approve",
+			Reason: "Approving myself",
+		}, tokens.AccessToken)
+		require.Equal(t, http.StatusForbidden, w.Code)
+		var p problem.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+		assert.Equal(t, problem.CodeForbidden, p.Code)
+	})
+
+	t.Run("Transitions require a known action and a reason", func(t *testing.T) {
+		w := sendPostRequest(app, url+"/transitions", handlers.TransitionCompanyRequest{
+			Action: "merge",
+		}, admin.AccessToken)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var p problem.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+		assert.Len(t, p.Errors, 2)
+	})
+
+	t.Run("Illegal transitions are rejected", func(t *testing.T) {
+		w := sendPostRequest(app, url+"/transitions", handlers.TransitionCompanyRequest{
+			Action: "suspend",
+			Reason: "Late filings",
+		}, admin.AccessToken)
+		require.Equal(t, http.StatusConflict, w.Code)
+		var p problem.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+		assert.Equal(t, problem.CodeInvalidTransition, p.Code)
+	})
+
+	t.Run("The lifecycle derives registered from the status", func(t *testing.T) {
+		approved := transition("approve", "Paperwork complete")
+		assert.Equal(t, "active", approved.Status)
+		assert.True(t, approved.Registered)
+
+		suspended := transition("suspend", "Late filings")
+		assert.Equal(t, "suspended", suspended.Status)
+		assert.True(t, suspended.Registered)
+
+		liquidating := transition("liquidate", "Creditors' petition")
+		assert.Equal(t, "in_liquidation", liquidating.Status)
+
+		dissolved := transition("dissolve", "Liquidation finished")
+		assert.Equal(t, "dissolved", dissolved.Status)
+		assert.False(t, dissolved.Registered)
+	})
+
+	t.Run("Each transition is recorded with its reason", func(t *testing.T) {
+		w := sendRequest(app, http.MethodGet, url+"/history?limit=4", nil, tokens.AccessToken)
+		require.Equal(t, http.StatusOK, w.Code)
+		var history handlers.CompanyHistoryResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+
+		require.Len(t, history.Revisions, 4)
+		for i, reason := range []string{
+			"Liquidation finished", "Creditors' petition", "Late filings", "Paperwork complete",
+		} {
+			assert.Equal(t, "transition", history.Revisions[i].Operation)
+			require.NotNil(t, history.Revisions[i].Reason)
+			assert.Equal(t, reason, *history.Revisions[i].Reason)
+		}
+		assert.Equal(t, "dissolved", history.Revisions[0].Company.Status)
+	})
+
+	t.Run("Dissolved companies stay dissolved", func(t *testing.T) {
+		w := sendPostRequest(app, url+"/transitions", handlers.TransitionCompanyRequest{
+			Action: "reinstate",
+			Reason: "Changed our minds",
+		}, admin.AccessToken)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("Registered must agree with the status on update", func(t *testing.T) {
+		registered := true
+		w := sendRequest(app, http.MethodPatch, url, handlers.UpdateCompanyRequest{
+			Registered: &registered,
+		}, tokens.AccessToken)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = sendRequest(app, http.MethodPatch, url, handlers.UpdateCompanyRequest{
+			Registered: &unregistered,
+		}, tokens.AccessToken)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var updated handlers.CompanyResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+		assert.Equal(t, "dissolved", updated.Status)
+	})
+
+	t.Run("Companies cannot be created registered", func(t *testing.T) {
+		registered := true
+		w := sendPostRequest(app, "/api/v1/company", handlers.CreateCompanyRequest{
+			Name:          "life" + uuid.NewString()[:8],
+			EmployeeCount: &employees,
+			Registered:    &registered,
+			CompanyType:   "Corporation",
+		}, tokens.AccessToken)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		var p problem.Problem
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &p))
+		require.Len(t, p.Errors, 1)
+		assert.Equal(t, "registered", p.Errors[0].Field)
+	})
+}
@@ -24,6 +24,7 @@ func TestUserFlow(t *testing.T) {
 	companyName := "company" + uuid.NewString()[:8]
 
 	var accessToken string
+	var companyID uuid.UUID
 
 	t.Run("User Signup", func(t *testing.T) {
 		reqPayload := handlers.UserSignupRequest{
@@ -54,11 +55,9 @@ func TestUserFlow(t *testing.T) {
 
 	t.Run("Create Company", func(t *testing.T) {
 		var ec int32 = 50
-		reg := true
 		reqPayload := handlers.CreateCompanyRequest{
 			Name:          companyName,
 			EmployeeCount: &ec,
-			Registered:    &reg,
 			CompanyType:   "Corporation",
 		}
 		w := sendPostRequest(app, "/api/v1/company", reqPayload, accessToken)
@@ -70,36 +69,167 @@ func TestUserFlow(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, companyName, resp.Name)
 		assert.Equal(t, ec, resp.EmployeeCount)
-		assert.Equal(t, reg, resp.Registered)
+		assert.Equal(t, "pending", resp.Status)
+		assert.False(t, resp.Registered)
 		assert.Equal(t, "Corporation", resp.CompanyType)
+
+		companyID = resp.ID
+	})
+
+	t.Run("Get Company by name", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/company/by-name/"+companyName, nil)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp handlers.CompanyResponse
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		require.NoError(t, err)
+		assert.Equal(t, companyID, resp.ID)
+	})
+
+	t.Run("Get Company by ID", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/company/"+companyID.String(), nil)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp handlers.CompanyResponse
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		require.NoError(t, err)
+		assert.Equal(t, companyName, resp.Name)
+	})
+
+	t.Run("Get Company by invalid ID fails", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/company/not-a-uuid", nil)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("List Companies", func(t *testing.T) {
+		var names []string
+		cursor := ""
+		// Walk the listing one company at a time to exercise the cursor
+		for page := 0; page < 100; page++ {
+			url := "/api/v1/companies?company_type=Corporation&sort_by=name&limit=1"
+			if cursor != "" {
+				url += "&cursor=" + cursor
+			}
+			r := httptest.NewRequest(http.MethodGet, url, nil)
+			w := httptest.NewRecorder()
+			app.ServeHTTP(w, r)
+
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var resp handlers.CompanyListResponse
+			err := json.Unmarshal(w.Body.Bytes(), &resp)
+			require.NoError(t, err)
+			require.LessOrEqual(t, len(resp.Companies), 1)
+			for _, c := range resp.Companies {
+				names = append(names, c.Name)
+			}
+
+			if resp.NextCursor == nil {
+				break
+			}
+			cursor = *resp.NextCursor
+		}
+
+		assert.Contains(t, names, companyName)
+		assert.IsNonDecreasing(t, names)
+	})
+
+	t.Run("List Companies with invalid parameters fails", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/companies?min_employees=10&max_employees=5", nil)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
 	})
 
-	t.Run("Get Company", func(t *testing.T) {
-		r := httptest.NewRequest(http.MethodGet, "/api/v1/company/"+companyName, nil)
+	t.Run("Search Companies", func(t *testing.T) {
+		// A truncated name should still be found
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/companies/search?q="+companyName[:len(companyName)-2], nil)
 		w := httptest.NewRecorder()
 		app.ServeHTTP(w, r)
 
 		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp handlers.CompanySearchResponse
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		require.NoError(t, err)
+
+		var found bool
+		for _, result := range resp.Results {
+			if result.Company.ID == companyID {
+				found = true
+			}
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("Search Companies without query fails", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/companies/search", nil)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusBadRequest, w.Code)
 	})
 
 	t.Run("Create duplicate Company fails", func(t *testing.T) {
 		var ec int32 = 50
-		reg := true
 		reqPayload := handlers.CreateCompanyRequest{
 			Name:          companyName,
 			EmployeeCount: &ec,
-			Registered:    &reg,
 			CompanyType:   "Corporation",
 		}
 		w := sendPostRequest(app, "/api/v1/company", reqPayload, accessToken)
 
 		require.Equal(t, http.StatusConflict, w.Code)
 	})
+
+	t.Run("Update Company by ID", func(t *testing.T) {
+		var ec int32 = 75
+		reqPayload := handlers.UpdateCompanyRequest{
+			EmployeeCount: &ec,
+		}
+		w := sendRequest(app, http.MethodPatch, "/api/v1/company/"+companyID.String(), reqPayload, accessToken)
+
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp handlers.CompanyResponse
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		require.NoError(t, err)
+		assert.Equal(t, companyID, resp.ID)
+		assert.Equal(t, ec, resp.EmployeeCount)
+	})
+
+	t.Run("Delete Company by ID", func(t *testing.T) {
+		w := sendRequest(app, http.MethodDelete, "/api/v1/company/"+companyID.String(), nil, accessToken)
+
+		require.Equal(t, http.StatusNoContent, w.Code)
+	})
+
+	t.Run("Get deleted Company by ID fails", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/company/"+companyID.String(), nil)
+		w := httptest.NewRecorder()
+		app.ServeHTTP(w, r)
+
+		require.Equal(t, http.StatusNotFound, w.Code)
+	})
 }
 
 func sendPostRequest(app *app.App, url string, body any, accessToken string) *httptest.ResponseRecorder {
+	return sendRequest(app, http.MethodPost, url, body, accessToken)
+}
+
+func sendRequest(app *app.App, method, url string, body any, accessToken string) *httptest.ResponseRecorder {
 	reqBody, _ := json.Marshal(body)
-	r := httptest.NewRequest(http.MethodPost, url, bytes.NewReader(reqBody))
+	r := httptest.NewRequest(method, url, bytes.NewReader(reqBody))
 	r.Header.Set("Content-Type", "application/json")
 	if accessToken != "" {
 		r.Header.Set("Authorization", "Bearer "+accessToken)
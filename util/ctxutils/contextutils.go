@@ -2,6 +2,7 @@ package ctxutils
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -9,7 +10,27 @@ import (
 // Custom type for context keys to avoid collisions when using context.WithValue
 type ctxKey string
 
-const userIDKey ctxKey = "userID"
+// TokenInfo identifies the access token a request was authenticated with.
+type TokenInfo struct {
+	ID        uuid.UUID
+	SessionID uuid.UUID
+	ExpiresAt time.Time
+}
+
+// RequestSummary gathers facts established while a request is handled, such as the authenticated user.
+// Middleware wrapping the handler chain reads it once the request completes,
+// which a context value set further down the chain would not allow.
+type RequestSummary struct {
+	UserID uuid.UUID // uuid.Nil for unauthenticated requests
+}
+
+const (
+	userIDKey         ctxKey = "userID"
+	userRoleKey       ctxKey = "userRole"
+	tokenInfoKey      ctxKey = "tokenInfo"
+	requestSummaryKey ctxKey = "requestSummary"
+	requestIDKey      ctxKey = "requestID"
+)
 
 // GetUserIDFromContext retrieves the user ID from the context.
 // It returns the user ID and a boolean indicating whether it was found.
@@ -19,6 +40,59 @@ func GetUserIDFromContext(ctx context.Context) (uuid.UUID, bool) {
 }
 
 // SetUserIDInContext stores a UUID userID in the context.
+// It is also recorded in the request summary, if the context carries one.
 func SetUserIDInContext(ctx context.Context, userID uuid.UUID) context.Context {
+	if summary, ok := GetRequestSummaryFromContext(ctx); ok {
+		summary.UserID = userID
+	}
 	return context.WithValue(ctx, userIDKey, userID)
 }
+
+// GetRequestSummaryFromContext retrieves the request summary from the context.
+// It returns the summary and a boolean indicating whether it was found.
+func GetRequestSummaryFromContext(ctx context.Context) (*RequestSummary, bool) {
+	summary, ok := ctx.Value(requestSummaryKey).(*RequestSummary)
+	return summary, ok
+}
+
+// SetRequestSummaryInContext stores an empty request summary in the context and returns both.
+func SetRequestSummaryInContext(ctx context.Context) (context.Context, *RequestSummary) {
+	summary := &RequestSummary{}
+	return context.WithValue(ctx, requestSummaryKey, summary), summary
+}
+
+// GetRequestIDFromContext retrieves the request ID from the context.
+// It returns the request ID and a boolean indicating whether it was found.
+func GetRequestIDFromContext(ctx context.Context) (string, bool) {
+	requestID, ok := ctx.Value(requestIDKey).(string)
+	return requestID, ok
+}
+
+// SetRequestIDInContext stores the request ID in the context.
+func SetRequestIDInContext(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// GetTokenInfoFromContext retrieves the access token info from the context.
+// It returns the token info and a boolean indicating whether it was found.
+func GetTokenInfoFromContext(ctx context.Context) (TokenInfo, bool) {
+	info, ok := ctx.Value(tokenInfoKey).(TokenInfo)
+	return info, ok
+}
+
+// SetTokenInfoInContext stores the access token info in the context.
+func SetTokenInfoInContext(ctx context.Context, info TokenInfo) context.Context {
+	return context.WithValue(ctx, tokenInfoKey, info)
+}
+
+// GetUserRoleFromContext retrieves the user's role from the context.
+// It returns the role and a boolean indicating whether it was found.
+func GetUserRoleFromContext(ctx context.Context) (string, bool) {
+	role, ok := ctx.Value(userRoleKey).(string)
+	return role, ok
+}
+
+// SetUserRoleInContext stores the user's role in the context.
+func SetUserRoleInContext(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, userRoleKey, role)
+}
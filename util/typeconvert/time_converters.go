@@ -13,3 +13,17 @@ func TimeToPgtypeTimestamp(t time.Time) pgtype.Timestamp {
 		Valid:            true,
 	}
 }
+
+func PgtypeTimestampToPtrTime(t pgtype.Timestamp) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
+
+func PtrTimeToPgtypeTimestamp(t *time.Time) pgtype.Timestamp {
+	if t == nil {
+		return pgtype.Timestamp{Valid: false}
+	}
+	return TimeToPgtypeTimestamp(*t)
+}
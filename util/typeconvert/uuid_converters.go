@@ -27,3 +27,22 @@ func PgtypeUUIDToGoogleUUID(pgUUID pgtype.UUID) uuid.UUID {
 
 	return pgUUID.Bytes
 }
+
+// PtrGoogleUUIDToPgtypeUUID converts a Google UUID pointer to a pgtype.UUID.
+// A nil pointer results in a pgtype.UUID with the Valid field set to false.
+func PtrGoogleUUIDToPgtypeUUID(gUUID *uuid.UUID) pgtype.UUID {
+	if gUUID == nil {
+		return pgtype.UUID{Valid: false}
+	}
+	return GoogleUUIDToPgtypeUUID(*gUUID)
+}
+
+// PgtypeUUIDToPtrGoogleUUID converts a pgtype.UUID to a Google UUID pointer.
+// An invalid pgtype.UUID results in a nil pointer.
+func PgtypeUUIDToPtrGoogleUUID(pgUUID pgtype.UUID) *uuid.UUID {
+	if !pgUUID.Valid {
+		return nil
+	}
+	gUUID := uuid.UUID(pgUUID.Bytes)
+	return &gUUID
+}